@@ -0,0 +1,34 @@
+package pkcs7pad
+
+// The cipher helpers in this module (EncryptCBC, DecryptCBC, PaddedEncrypter,
+// PaddedDecrypter, and the cbchmac, tlscbc, and opensslenc packages) use only
+// the block ciphers, hashes, MACs, and random numbers from the standard
+// library's crypto packages, and never implementations of their own. They
+// therefore use the validated module automatically when the program is built
+// with GOEXPERIMENT=boringcrypto or run with GODEBUG=fips140=on. (The
+// EVP_BytesToKey derivation in opensslenc is built from a standard hash, but is
+// not itself an approved algorithm; use its PBKDF2 option in FIPS mode.)
+
+// Backends reported by CryptoBackend.
+const (
+	BackendGo           = "go"
+	BackendFIPS140      = "fips140"
+	BackendBoringCrypto = "boringcrypto"
+)
+
+// CryptoBackend reports which implementation of the standard library's
+// cryptographic primitives the cipher helpers are using: BackendBoringCrypto
+// if the program was built with the Go+BoringCrypto toolchain and BoringCrypto
+// is in use, BackendFIPS140 if the Go Cryptographic Module is running in FIPS
+// 140-3 mode, and BackendGo otherwise. FIPS 140-3 mode requires Go 1.24 or
+// later; the rest of the package does not. Programs that must only use
+// validated implementations can check it at startup.
+func CryptoBackend() string {
+	switch {
+	case boringEnabled():
+		return BackendBoringCrypto
+	case fips140Enabled():
+		return BackendFIPS140
+	}
+	return BackendGo
+}
@@ -0,0 +1,7 @@
+//go:build boringcrypto
+
+package pkcs7pad
+
+import "crypto/boring"
+
+func boringEnabled() bool { return boring.Enabled() }
@@ -0,0 +1,7 @@
+//go:build go1.24
+
+package pkcs7pad
+
+import "crypto/fips140"
+
+func fips140Enabled() bool { return fips140.Enabled() }
@@ -0,0 +1,5 @@
+//go:build !boringcrypto
+
+package pkcs7pad
+
+func boringEnabled() bool { return false }
@@ -0,0 +1,6 @@
+//go:build !go1.24
+
+package pkcs7pad
+
+// Before Go 1.24 there is no FIPS 140-3 mode to be in.
+func fips140Enabled() bool { return false }
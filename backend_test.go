@@ -0,0 +1,25 @@
+package pkcs7pad
+
+import "testing"
+
+func TestCryptoBackend(t *testing.T) {
+	t.Parallel()
+
+	got := CryptoBackend()
+	switch got {
+	case BackendBoringCrypto:
+		if !boringEnabled() {
+			t.Errorf("reported %q without BoringCrypto", got)
+		}
+	case BackendFIPS140:
+		if !fips140Enabled() {
+			t.Errorf("reported %q outside of FIPS 140-3 mode", got)
+		}
+	case BackendGo:
+		if boringEnabled() || fips140Enabled() {
+			t.Errorf("reported %q with a validated module enabled", got)
+		}
+	default:
+		t.Errorf("unknown backend %q", got)
+	}
+}
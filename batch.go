@@ -0,0 +1,28 @@
+package pkcs7pad
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UnpadAll removes the PKCS#7 padding from every buffer in bufs, as Unpad does.
+// The result for bufs[i] is stored at index i of the returned slice, and is
+// nil if its padding is malformed.
+//
+// If any of the buffers are malformed, UnpadAll returns an error joining one
+// error per bad buffer (see errors.Join), each of which identifies the buffer
+// by index and wraps ErrBadPadding. Since it names the bad buffers, the error
+// should not be shown to whoever supplied them.
+func UnpadAll(bufs [][]byte) ([][]byte, error) {
+	out := make([][]byte, len(bufs))
+	var errs []error
+	for i, buf := range bufs {
+		padLen, good := checkPadding(buf)
+		if good != 1 {
+			errs = append(errs, fmt.Errorf("buffer %d: %w", i, ErrBadPadding))
+			continue
+		}
+		out[i] = buf[:len(buf)-padLen]
+	}
+	return out, errors.Join(errs...)
+}
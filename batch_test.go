@@ -0,0 +1,42 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestUnpadAll(t *testing.T) {
+	t.Parallel()
+
+	var bufs [][]byte
+	for _, test := range PadTests {
+		bufs = append(bufs, test.out)
+	}
+	out, err := UnpadAll(bufs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, test := range PadTests {
+		if !bytes.Equal(out[i], test.in) {
+			t.Errorf("[%d] %x != %x", i, out[i], test.in)
+		}
+	}
+
+	bufs = append(bufs[:2:2], BadPadTests...)
+	out, err = UnpadAll(bufs)
+	if !errors.Is(err, ErrBadPadding) {
+		t.Fatalf("expected BadCiphertext, got %v", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != len(BadPadTests) {
+		t.Errorf("got %d errors, want %d", n, len(BadPadTests))
+	}
+	if !bytes.Equal(out[1], PadTests[1].in) {
+		t.Errorf("%x != %x", out[1], PadTests[1].in)
+	}
+	for i := 2; i < len(out); i++ {
+		if out[i] != nil {
+			t.Errorf("[%d] expected nil, got %x", i, out[i])
+		}
+	}
+}
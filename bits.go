@@ -0,0 +1,96 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// The functions in this file implement 10* padding on bit strings, as used by
+// protocols specified in terms of bits rather than bytes (ISO/IEC 9797-1 Method
+// 2, for example, is defined this way). A string of n bits is stored in
+// (n+7)/8 bytes, most significant bit first; any bits of the final byte past
+// the n'th are ignored.
+
+// PadBits appends a 1 bit followed by as few 0 bits as are needed to make the
+// length of a string of n bits a multiple of blockBits, and returns the padded
+// string and its length in bits. buf must hold at least (n+7)/8 bytes; bytes
+// past those are discarded. It panics if n is negative or blockBits is less
+// than 1.
+func PadBits(buf []byte, n, blockBits int) ([]byte, int) {
+	if blockBits < 1 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size of %d bits", blockBits))
+	}
+	if n < 0 || len(buf) < (n+7)/8 {
+		panic(fmt.Sprintf("pkcs7pad: %d bytes can't hold %d bits", len(buf), n))
+	}
+	padded := n + blockBits - n%blockBits
+	buf = buf[:(n+7)/8]
+	if r := n % 8; r != 0 {
+		// Clear the unused bits of the final byte.
+		buf[len(buf)-1] &= 0xff << (8 - r)
+	}
+	buf = append(buf, make([]byte, (padded+7)/8-len(buf))...)
+	buf[n/8] |= 0x80 >> (n % 8)
+	return buf, padded
+}
+
+// UnpadBits removes 10* padding from a string of n bits, and returns the length
+// in bits of the unpadded string, which is stored in the first bytes of buf. It
+// returns an error wrapping ErrBadPadding if n is not a non-zero multiple of
+// blockBits, or if the final block does not contain a 1 bit. Only the final
+// block is scanned, and it is scanned in constant time.
+func UnpadBits(buf []byte, n, blockBits int) (int, error) {
+	if blockBits < 1 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size of %d bits", blockBits))
+	}
+	if n < 0 || len(buf) < (n+7)/8 {
+		panic(fmt.Sprintf("pkcs7pad: %d bytes can't hold %d bits", len(buf), n))
+	}
+	if n%blockBits != 0 {
+		return 0, fmt.Errorf("%w: length of %d bits is not a multiple of block size %d", ErrBadPadding, n, blockBits)
+	}
+	if n == 0 {
+		return 0, ErrBadPadding
+	}
+
+	padLen, found := 0, 0
+	for i := 0; i < blockBits; i++ {
+		p := n - 1 - i
+		bit := int(buf[p/8]>>(7-p%8)) & 1
+		first := (found ^ 1) & bit
+		padLen = subtle.ConstantTimeSelect(first, i+1, padLen)
+		found |= bit
+	}
+	if found != 1 {
+		return 0, ErrBadPadding
+	}
+	return n - padLen, nil
+}
+
+// Pad10 is PadBits for byte strings: it appends 10* padding to buf such that
+// its length is a multiple of size bytes. On whole bytes, 10* padding is a
+// 0x80 byte followed by zeros, so Pad10 is the same as PadISO7816.
+func Pad10(buf []byte, size int) []byte {
+	return PadISO7816(buf, size)
+}
+
+// Unpad10 removes the padding added by Pad10. It is the same as
+// UnpadISO7816, but additionally requires the length of buf to be a non-zero
+// multiple of size and the padding to be no longer than a block.
+func Unpad10(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+	n, err := UnpadBits(buf, 8*len(buf), 8*size)
+	if err != nil {
+		return nil, err
+	}
+	if n%8 != 0 {
+		// The marker was not at the top of a byte.
+		return nil, ErrBadPadding
+	}
+	return buf[:n/8], nil
+}
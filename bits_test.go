@@ -0,0 +1,97 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPadBits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in       []byte
+		n, block int
+		out      []byte
+		padded   int
+	}{
+		{[]byte{}, 0, 8, []byte{0x80}, 8},
+		{[]byte{0xff}, 3, 8, []byte{0xf0}, 8},
+		{[]byte{0xff}, 7, 8, []byte{0xff}, 8},
+		{[]byte{0xff}, 8, 8, []byte{0xff, 0x80}, 16},
+		{[]byte{0xa0}, 3, 4, []byte{0xb0}, 4},
+		{[]byte{0xff, 0xff}, 9, 12, []byte{0xff, 0xc0}, 12},
+		{[]byte{0xff}, 5, 3, []byte{0xfc}, 6},
+	}
+	for i, test := range tests {
+		out, padded := PadBits(bytes.Clone(test.in), test.n, test.block)
+		if !bytes.Equal(out, test.out) || padded != test.padded {
+			t.Errorf("[%d] PadBits = %08b, %d; want %08b, %d", i, out, padded, test.out, test.padded)
+		}
+		n, err := UnpadBits(out, padded, test.block)
+		if err != nil || n != test.n {
+			t.Errorf("[%d] UnpadBits = %d, %v; want %d", i, n, err, test.n)
+		}
+	}
+
+	for block := 1; block <= 40; block++ {
+		for n := 0; n <= 3*block; n++ {
+			buf := bytes.Repeat([]byte{0xff}, (n+7)/8)
+			out, padded := PadBits(buf, n, block)
+			if padded%block != 0 || padded <= n || padded > n+block {
+				t.Fatalf("[%d/%d] padded to %d bits", block, n, padded)
+			}
+			if got, err := UnpadBits(out, padded, block); err != nil || got != n {
+				t.Fatalf("[%d/%d] UnpadBits = %d, %v", block, n, got, err)
+			}
+		}
+	}
+}
+
+func TestUnpadBitsErrors(t *testing.T) {
+	t.Parallel()
+
+	bad := []struct {
+		buf      []byte
+		n, block int
+	}{
+		{nil, 0, 8},
+		{[]byte{0x00}, 8, 8},
+		{[]byte{0x80, 0x00}, 16, 8},
+		{[]byte{0xf0}, 7, 4},
+	}
+	for i, test := range bad {
+		if _, err := UnpadBits(test.buf, test.n, test.block); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
+
+func TestPad10(t *testing.T) {
+	t.Parallel()
+
+	for n := 0; n < 40; n++ {
+		msg := bytes.Repeat([]byte{0x01}, n)
+		padded := Pad10(bytes.Clone(msg), 16)
+		bitPadded, _ := PadBits(bytes.Clone(msg), 8*n, 128)
+		if !bytes.Equal(padded, bitPadded) {
+			t.Fatalf("[%d] %x != %x", n, padded, bitPadded)
+		}
+		if out, err := Unpad10(padded, 16); err != nil || !bytes.Equal(out, msg) {
+			t.Fatalf("[%d] Unpad10 = %x, %v", n, out, err)
+		}
+	}
+
+	bad := [][]byte{
+		{},
+		make([]byte, 16),
+		append(make([]byte, 15), 0x40),
+		append([]byte{0x80}, make([]byte, 31)...),
+		make([]byte, 15),
+	}
+	for i, buf := range bad {
+		if _, err := Unpad10(buf, 16); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
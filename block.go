@@ -0,0 +1,15 @@
+package pkcs7pad
+
+import "crypto/cipher"
+
+// PadForBlock is like Pad, but it takes the block size from the cipher that
+// will encrypt buf, so that it can't be confused with the key size.
+func PadForBlock(buf []byte, b cipher.Block) []byte {
+	return Pad(buf, b.BlockSize())
+}
+
+// UnpadForBlock is like UnpadBlock, but it takes the block size from the
+// cipher that decrypted buf.
+func UnpadForBlock(buf []byte, b cipher.Block) ([]byte, error) {
+	return UnpadBlock(buf, b.BlockSize())
+}
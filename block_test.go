@@ -0,0 +1,38 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/des"
+	"errors"
+	"testing"
+)
+
+func TestPadForBlock(t *testing.T) {
+	t.Parallel()
+
+	b := testBlock(t)
+	for i, test := range PadTests {
+		pad := PadForBlock(bytes.Clone(test.in), b)
+		if !bytes.Equal(pad, test.out) {
+			t.Errorf("[%d] %x != %x", i, pad, test.out)
+		}
+		unpad, err := UnpadForBlock(pad, b)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+
+	d, err := des.NewCipher(testKey[:8])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pad := PadForBlock(testString[:3:3], d); len(pad) != 8 {
+		t.Errorf("padded to %d bytes for DES", len(pad))
+	}
+	if _, err := UnpadForBlock(PadTests[1].out[:12], b); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package pkcs7pad
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A BlockSize is a block size that is known to be between 1 and 255. Its
+// methods are equivalent to the corresponding functions in this package, but
+// since the size was validated when the BlockSize was created, they never
+// panic because of it. This lets configuration layers reject bad block sizes
+// up front instead of on the data path.
+//
+// The zero BlockSize is not valid; use NewBlockSize or UnmarshalText to create
+// one.
+type BlockSize struct {
+	size uint8
+}
+
+// NewBlockSize returns a BlockSize for the given size, or an error wrapping
+// ErrBlockSize if it is not between 1 and 255.
+func NewBlockSize(size int) (BlockSize, error) {
+	if size < 1 || size > 255 {
+		return BlockSize{}, errBlockSize(size)
+	}
+	return BlockSize{size: uint8(size)}, nil
+}
+
+// Int returns the block size as an int.
+func (s BlockSize) Int() int {
+	return int(s.size)
+}
+
+// String returns the block size in decimal.
+func (s BlockSize) String() string {
+	return strconv.Itoa(int(s.size))
+}
+
+// Pad is like the Pad function, using s as the block size.
+func (s BlockSize) Pad(buf []byte) []byte {
+	return pad(buf, s.valid())
+}
+
+// Unpad is like UnpadBlock, using s as the block size.
+func (s BlockSize) Unpad(buf []byte) ([]byte, error) {
+	return unpadBlock(buf, s.valid())
+}
+
+// PaddedLen is like the PaddedLen function, using s as the block size.
+func (s BlockSize) PaddedLen(n int) int {
+	size := s.valid()
+	return n + size - n%size
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s BlockSize) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(s.size), 10), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that block sizes read
+// from configuration files are validated as they are parsed.
+func (s *BlockSize) UnmarshalText(text []byte) error {
+	n, err := strconv.Atoi(string(text))
+	if err != nil {
+		return fmt.Errorf("%w %q", ErrBlockSize, text)
+	}
+	v, err := NewBlockSize(n)
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+func (s BlockSize) valid() int {
+	if s.size == 0 {
+		panic("pkcs7pad: use of zero BlockSize")
+	}
+	return int(s.size)
+}
@@ -0,0 +1,83 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestBlockSize(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewBlockSize(aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Int() != aes.BlockSize || s.String() != "16" {
+		t.Errorf("got %d (%s)", s.Int(), s)
+	}
+	for i, test := range PadTests {
+		pad := s.Pad(bytes.Clone(test.in))
+		if !bytes.Equal(pad, test.out) {
+			t.Errorf("[%d] %x != %x", i, pad, test.out)
+		}
+		if n := s.PaddedLen(len(test.in)); n != len(test.out) {
+			t.Errorf("[%d] PaddedLen %d != %d", i, n, len(test.out))
+		}
+		unpad, err := s.Unpad(pad)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+
+	for _, size := range []int{-1, 0, 256} {
+		if _, err := NewBlockSize(size); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[%d] expected ErrBlockSize, got %v", size, err)
+		}
+	}
+}
+
+func TestBlockSizeText(t *testing.T) {
+	t.Parallel()
+
+	var config struct {
+		BlockSize BlockSize `json:"block_size"`
+	}
+	if err := json.Unmarshal([]byte(`{"block_size": "8"}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.BlockSize.Int() != 8 {
+		t.Errorf("block size %d != 8", config.BlockSize.Int())
+	}
+	out, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"block_size":"8"}` {
+		t.Errorf("marshaled to %s", out)
+	}
+
+	for i, text := range []string{"", "0", "256", "sixteen"} {
+		var s BlockSize
+		if err := s.UnmarshalText([]byte(text)); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[%d] expected ErrBlockSize, got %v", i, err)
+		}
+	}
+}
+
+func TestBlockSizeZero(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r != "pkcs7pad: use of zero BlockSize" {
+			t.Errorf("unexpected panic %v", r)
+		}
+	}()
+	var s BlockSize
+	s.Pad(nil)
+}
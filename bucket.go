@@ -0,0 +1,115 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var errBucket = errors.New("pkcs7pad: no bucket is large enough")
+
+// PadToBucket pads buf up to the smallest of the given bucket sizes that is
+// longer than it, so that only the bucket, and not the exact length of the
+// message, is revealed. The buckets may be given in any order.
+//
+// The padding is removable by UnpadBucket. When 255 or fewer bytes are needed,
+// it is ordinary PKCS#7 padding: n bytes of the value n. Larger amounts of
+// padding, which PKCS#7 can't express, end in a zero byte (which is never valid
+// PKCS#7) preceded by the padding length as a 4-byte big-endian integer, with
+// every other padding byte zero.
+//
+// An error is returned if no bucket is longer than buf. The empty message
+// still needs a byte of padding, so every bucket must be at least 1.
+func PadToBucket(buf []byte, buckets []int) ([]byte, error) {
+	target := -1
+	for _, b := range buckets {
+		if b > len(buf) && (target < 0 || b < target) {
+			target = b
+		}
+	}
+	if target < 0 {
+		return nil, fmt.Errorf("%w for a message of %d bytes", errBucket, len(buf))
+	}
+
+	return padTo(buf, target), nil
+}
+
+// padTo pads buf to exactly target bytes, which must be more than len(buf), in
+// the format described by PadToBucket.
+func padTo(buf []byte, target int) []byte {
+	n := target - len(buf)
+	if cap(buf) < target {
+		grown := make([]byte, len(buf), target)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:target]
+	pad := buf[len(buf)-n:]
+	if n <= 255 {
+		for i := range pad {
+			pad[i] = byte(n)
+		}
+		return buf
+	}
+	clear(pad)
+	binary.BigEndian.PutUint32(pad[n-5:], uint32(n))
+	return buf
+}
+
+// UnpadBucket removes the padding added by PadToBucket. It returns an error
+// wrapping ErrBadPadding if the length of buf is not one of the buckets, or if
+// the padding is malformed. Which buckets are allowed is public, but the
+// padding is checked in constant time.
+func UnpadBucket(buf []byte, buckets []int) ([]byte, error) {
+	found := false
+	for _, b := range buckets {
+		found = found || b == len(buf)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: length %d is not one of the buckets", ErrBadPadding, len(buf))
+	}
+	padLen, good := checkPaddingTo(buf)
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+	return buf[:len(buf)-padLen], nil
+}
+
+// checkPaddingTo returns the length of the padding at the end of buf in the
+// format described by PadToBucket, along with 1 if it is well-formed and 0
+// otherwise.
+func checkPaddingTo(buf []byte) (int, int) {
+	if len(buf) == 0 {
+		return 0, 0
+	}
+
+	last := buf[len(buf)-1]
+	ext := subtle.ConstantTimeByteEq(last, 0)
+	var extLen uint32
+	if len(buf) >= 5 {
+		extLen = binary.BigEndian.Uint32(buf[len(buf)-5:])
+	}
+	// An extended length that doesn't fit in an int is also too long for
+	// buf, so clamping it larger than len(buf) preserves the verdict.
+	extLen = min(extLen, uint32(min(len(buf)+1, 1<<31-1)))
+	padLen := subtle.ConstantTimeSelect(ext, int(extLen), int(last))
+
+	var diff byte
+	for j := 0; j < len(buf); j++ {
+		b := buf[len(buf)-1-j]
+		inPad := byte(subtle.ConstantTimeLessOrEq(j+1, padLen))
+		// In the extended form, offsets 1 through 4 from the end hold the
+		// length itself, which was read above.
+		isLen := byte(ext & subtle.ConstantTimeLessOrEq(1, j) & subtle.ConstantTimeLessOrEq(j, 4))
+		want := last &^ byte(-ext) // last for PKCS#7, zero otherwise
+		diff |= (b ^ want) & -inPad & ^-isLen
+	}
+
+	good := subtle.ConstantTimeByteEq(diff, 0)
+	good &= subtle.ConstantTimeLessOrEq(1, padLen)
+	good &= subtle.ConstantTimeLessOrEq(padLen, len(buf))
+	// The extended form is only used for more than 255 bytes of padding.
+	good &= ext ^ 1 | subtle.ConstantTimeLessOrEq(256, padLen)
+	return padLen, good
+}
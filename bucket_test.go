@@ -0,0 +1,81 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var testBuckets = []int{4096, 256, 1024, 16}
+
+func TestPadToBucket(t *testing.T) {
+	t.Parallel()
+
+	for n := 0; n < 4096; n++ {
+		msg := bytes.Repeat([]byte{byte(n)}, n)
+		padded, err := PadToBucket(bytes.Clone(msg), testBuckets)
+		if err != nil {
+			t.Fatalf("[%d] error padding: %v", n, err)
+		}
+		want := 16
+		for _, b := range []int{16, 256, 1024, 4096} {
+			if n >= want {
+				want = b
+			}
+		}
+		if len(padded) != want {
+			t.Fatalf("[%d] padded to %d bytes, want %d", n, len(padded), want)
+		}
+		if !bytes.Equal(padded[:n], msg) {
+			t.Fatalf("[%d] padded buffer does not start with the message", n)
+		}
+		if want-n <= 255 {
+			if out, err := Unpad(padded); err != nil || !bytes.Equal(out, msg) {
+				t.Fatalf("[%d] short padding is not PKCS#7: %x", n, padded[n:])
+			}
+		}
+		out, err := UnpadBucket(padded, testBuckets)
+		if err != nil {
+			t.Fatalf("[%d] error unpadding: %v", n, err)
+		}
+		if !bytes.Equal(out, msg) {
+			t.Fatalf("[%d] %x != %x", n, out, msg)
+		}
+	}
+
+	if _, err := PadToBucket(make([]byte, 4096), testBuckets); err == nil {
+		t.Error("expected an error for a message as long as the largest bucket")
+	}
+	if _, err := PadToBucket(nil, nil); err == nil {
+		t.Error("expected an error with no buckets")
+	}
+}
+
+func TestUnpadBucketErrors(t *testing.T) {
+	t.Parallel()
+
+	ext, _ := PadToBucket(make([]byte, 100), testBuckets)
+	long := func(f func(b []byte)) []byte {
+		b := bytes.Clone(ext)
+		f(b)
+		return b
+	}
+	bad := [][]byte{
+		{},
+		make([]byte, 15),
+		make([]byte, 16),
+		bytes.Repeat([]byte{17}, 16),
+		append(bytes.Repeat([]byte{0}, 12), 3, 3, 2, 3),
+		long(func(b []byte) { b[200] = 1 }),
+		long(func(b []byte) { b[251] = 0xff }),
+		long(func(b []byte) { b[251] = 0; b[252] = 0; b[253] = 0; b[254] = 0x10 }),
+		long(func(b []byte) { b[254]++ }),
+		// The extended form for a length PKCS#7 can express.
+		long(func(b []byte) { clear(b); b[254] = 0xff }),
+	}
+	for i, buf := range bad {
+		if _, err := UnpadBucket(buf, testBuckets); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package pkcs7pad
+
+import (
+	"fmt"
+	"net"
+)
+
+// buffersLen returns the total length of bufs.
+func buffersLen(bufs net.Buffers) int64 {
+	var n int64
+	for _, b := range bufs {
+		n += int64(len(b))
+	}
+	return n
+}
+
+// PadBuffers returns bufs with its PKCS#7 padding appended as a separate final
+// slice, so that scatter-gather writers such as net.Buffers.WriteTo can send a
+// padded message without copying it into a single buffer. The padding is
+// computed from the total length of bufs. Like Pad, PadBuffers panics if size
+// is not between 1 and 255.
+func PadBuffers(bufs net.Buffers, size int) net.Buffers {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	i := PadLen(int(buffersLen(bufs)%int64(size)), size)
+	padding := make([]byte, i)
+	for j := range padding {
+		padding[j] = byte(i)
+	}
+	// Limit the capacity so the caller's backing array is never written to.
+	return append(bufs[:len(bufs):len(bufs)], padding)
+}
+
+// UnpadBuffers removes the PKCS#7 padding from the end of the data held in
+// bufs, which may span any number of its trailing slices. It returns a new
+// net.Buffers sharing the input slices, with the padding trimmed from the last
+// of them and any slices left empty at the end dropped. Like UnpadBlock, it
+// returns an error wrapping ErrBadPadding if the total length is not a
+// non-zero multiple of size, or if the padding is malformed, which is checked
+// in constant time.
+func UnpadBuffers(bufs net.Buffers, size int) (net.Buffers, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	total := buffersLen(bufs)
+	if total%int64(size) != 0 {
+		return nil, errMisaligned(total, size)
+	}
+	if total == 0 {
+		return nil, ErrBadPadding
+	}
+
+	// Gather the final block. Which bytes are copied depends only on the
+	// lengths of the slices, which are public.
+	var block [255]byte
+	tail := block[:size]
+	need := size
+	for i := len(bufs) - 1; need > 0; i-- {
+		b := bufs[i]
+		k := min(len(b), need)
+		copy(tail[need-k:need], b[len(b)-k:])
+		need -= k
+	}
+	padLen, good := checkPadding(tail)
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+
+	out := append(net.Buffers(nil), bufs...)
+	for len(out) > 0 {
+		last := out[len(out)-1]
+		k := min(len(last), padLen)
+		padLen -= k
+		if last = last[:len(last)-k]; len(last) > 0 {
+			out[len(out)-1] = last
+			break
+		}
+		out = out[:len(out)-1]
+	}
+	return out, nil
+}
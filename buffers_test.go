@@ -0,0 +1,104 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+)
+
+// splitAt splits buf into slices at the given offsets.
+func splitAt(buf []byte, offsets ...int) net.Buffers {
+	var bufs net.Buffers
+	prev := 0
+	for _, off := range offsets {
+		bufs = append(bufs, buf[prev:off])
+		prev = off
+	}
+	return append(bufs, buf[prev:])
+}
+
+func TestPadBuffers(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("hello, scatter-gather world")
+	for n := 0; n <= len(msg); n++ {
+		bufs := splitAt(msg[:n], n/3, n/2)
+		padded := PadBuffers(bufs, 8)
+		if len(padded) != len(bufs)+1 {
+			t.Errorf("[%d] padding was not added as a separate slice", n)
+		}
+		if got, want := bytes.Join(padded, nil), Pad(bytes.Clone(msg[:n]), 8); !bytes.Equal(got, want) {
+			t.Errorf("[%d] %x != %x", n, got, want)
+		}
+	}
+}
+
+func TestPadBuffersAliasing(t *testing.T) {
+	t.Parallel()
+
+	sentinel := []byte("sentinel")
+	backing := net.Buffers{[]byte("hello"), sentinel}
+	PadBuffers(backing[:1], 8)
+	if !bytes.Equal(backing[1], sentinel) {
+		t.Errorf("PadBuffers overwrote the caller's backing array")
+	}
+}
+
+func TestPadBuffersPanics(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r != "pkcs7pad: inappropriate block size 0" {
+			t.Errorf("unexpected panic %v", r)
+		}
+	}()
+	PadBuffers(net.Buffers{[]byte("hello")}, 0)
+}
+
+func TestUnpadBuffers(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("hello, scatter-gather world")
+	for n := 0; n <= len(msg); n++ {
+		padded := Pad(bytes.Clone(msg[:n]), 8)
+		// Split the padded buffer every possible way into three slices,
+		// some of which may be empty.
+		for i := 0; i <= len(padded); i++ {
+			for j := i; j <= len(padded); j++ {
+				bufs := splitAt(padded, i, j)
+				out, err := UnpadBuffers(bufs, 8)
+				if err != nil {
+					t.Fatalf("[%d, %d, %d] %v", n, i, j, err)
+				}
+				if got := bytes.Join(out, nil); !bytes.Equal(got, msg[:n]) {
+					t.Errorf("[%d, %d, %d] %x != %x", n, i, j, got, msg[:n])
+				}
+				if len(out) > 0 && len(out[len(out)-1]) == 0 {
+					t.Errorf("[%d, %d, %d] trailing empty slice", n, i, j)
+				}
+				if len(bufs[2]) != len(padded)-j {
+					t.Errorf("[%d, %d, %d] input was modified", n, i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestUnpadBuffersErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []net.Buffers{
+		nil,
+		{nil, {}},
+		{[]byte("1234567"), {}},
+		{[]byte("1234"), []byte("5678")},
+		{[]byte("12345\x03\x03"), []byte("\x02")},
+		{[]byte("\x09\x09\x09\x09"), []byte("\x09\x09\x09\x09")},
+	}
+	for i, bufs := range tests {
+		if _, err := UnpadBuffers(bufs, 8); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// PadBuffer appends PKCS#7 padding to the unread portion of b, so that b.Len()
+// becomes a multiple of size. The padding is written into b's own storage,
+// which grows if it has to. Like Pad, PadBuffer panics if size is not between 1
+// and 255.
+func PadBuffer(b *bytes.Buffer, size int) {
+	i := PadLen(b.Len(), size)
+	b.Grow(i)
+	for j := 0; j < i; j++ {
+		b.WriteByte(byte(i))
+	}
+}
+
+// UnpadBuffer removes PKCS#7 padding from the end of the unread portion of b
+// by truncating it in place, without copying. It performs the same
+// constant-time validation as Unpad, and leaves b unchanged if the padding is
+// malformed.
+func UnpadBuffer(b *bytes.Buffer) error {
+	padLen, good := checkPadding(b.Bytes())
+	if good != 1 {
+		return ErrBadPadding
+	}
+	b.Truncate(b.Len() - padLen)
+	return nil
+}
+
+// UnpadBufferBlock is like UnpadBuffer, but it applies the stricter checks of
+// UnpadBlock for the given block size.
+func UnpadBufferBlock(b *bytes.Buffer, size int) error {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	data, err := unpadBlock(b.Bytes(), size)
+	if err != nil {
+		return err
+	}
+	b.Truncate(len(data))
+	return nil
+}
@@ -0,0 +1,50 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPadUnpadBuffer(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 2)
+	for n := 0; n <= len(in); n++ {
+		var b bytes.Buffer
+		b.WriteString("skipped")
+		b.Next(7)
+		b.Write(in[:n])
+		PadBuffer(&b, 8)
+		if want := Pad(bytes.Clone(in[:n]), 8); !bytes.Equal(b.Bytes(), want) {
+			t.Errorf("[%d] padded %x, want %x", n, b.Bytes(), want)
+		}
+
+		c := bytes.NewBuffer(bytes.Clone(b.Bytes()))
+		if err := UnpadBuffer(&b); err != nil || !bytes.Equal(b.Bytes(), in[:n]) {
+			t.Errorf("[%d] UnpadBuffer = %x, %v", n, b.Bytes(), err)
+		}
+		if err := UnpadBufferBlock(c, 8); err != nil || !bytes.Equal(c.Bytes(), in[:n]) {
+			t.Errorf("[%d] UnpadBufferBlock = %x, %v", n, c.Bytes(), err)
+		}
+	}
+}
+
+func TestUnpadBufferErrors(t *testing.T) {
+	t.Parallel()
+
+	for i, in := range [][]byte{nil, []byte("hello\x03\x02\x03"), []byte("\x00")} {
+		b := bytes.NewBuffer(bytes.Clone(in))
+		if err := UnpadBuffer(b); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+		if !bytes.Equal(b.Bytes(), in) {
+			t.Errorf("[%d] buffer was modified: %x", i, b.Bytes())
+		}
+	}
+	// UnpadBuffer accepts this, but UnpadBufferBlock doesn't.
+	b := bytes.NewBuffer([]byte("hello\x01"))
+	if err := UnpadBufferBlock(b, 8); !errors.Is(err, ErrBadPadding) || b.Len() != 6 {
+		t.Errorf("expected ErrBadPadding, got %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package pkcs7pad
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+)
+
+// A PaddedEncrypter wraps a cipher.BlockMode, padding the plaintext it is given
+// so that callers don't have to deal with block boundaries. Plaintext is passed
+// to Update in chunks of any size, and Finish encrypts the final padded block.
+type PaddedEncrypter struct {
+	mode     cipher.BlockMode
+	buf      []byte
+	finished bool
+}
+
+// NewPaddedEncrypter returns a PaddedEncrypter that encrypts using the given
+// block mode, which must be an encrypter.
+func NewPaddedEncrypter(mode cipher.BlockMode) *PaddedEncrypter {
+	return &PaddedEncrypter{mode: mode, buf: make([]byte, 0, mode.BlockSize())}
+}
+
+// NewPaddedCBCEncrypter returns a PaddedEncrypter that encrypts in cipher block
+// chaining mode, using the given Block and initialization vector. The length of
+// iv must be the same as the Block's block size.
+func NewPaddedCBCEncrypter(b cipher.Block, iv []byte) *PaddedEncrypter {
+	return NewPaddedEncrypter(cipher.NewCBCEncrypter(b, iv))
+}
+
+// BlockSize returns the block size of the underlying block mode.
+func (e *PaddedEncrypter) BlockSize() int {
+	return e.mode.BlockSize()
+}
+
+// Update encrypts as much of src as possible, appends the resulting ciphertext
+// to dst, and returns the updated slice. Any trailing partial block is buffered
+// until the next call to Update or Finish. dst and src must not overlap.
+func (e *PaddedEncrypter) Update(dst, src []byte) []byte {
+	if e.finished {
+		panic("pkcs7pad: Update called after Finish")
+	}
+	bs := e.mode.BlockSize()
+	if len(e.buf) > 0 {
+		k := copy(e.buf[len(e.buf):bs], src)
+		e.buf = e.buf[:len(e.buf)+k]
+		src = src[k:]
+		if len(e.buf) < bs {
+			return dst
+		}
+		dst = cryptAppend(e.mode, dst, e.buf)
+		e.buf = e.buf[:0]
+	}
+	full := len(src) - len(src)%bs
+	dst = cryptAppend(e.mode, dst, src[:full])
+	e.buf = append(e.buf, src[full:]...)
+	return dst
+}
+
+// Finish pads and encrypts any buffered plaintext, appends the resulting final
+// block to dst, and returns the updated slice. The PaddedEncrypter must not be
+// used after calling Finish.
+func (e *PaddedEncrypter) Finish(dst []byte) []byte {
+	if e.finished {
+		panic("pkcs7pad: Finish called twice")
+	}
+	e.finished = true
+	return cryptAppend(e.mode, dst, Pad(e.buf, e.mode.BlockSize()))
+}
+
+// A PaddedDecrypter wraps a cipher.BlockMode, removing the padding from the
+// plaintext it produces. Ciphertext is passed to Update in chunks of any size,
+// and Finish decrypts and unpads the final block.
+//
+// The final block is always held back until Finish, which returns ErrBadPadding
+// if the padding is malformed. Reporting that error to whoever supplied the
+// ciphertext creates a padding oracle, so ciphertexts should be authenticated
+// before they are decrypted.
+type PaddedDecrypter struct {
+	mode     cipher.BlockMode
+	buf      []byte
+	total    int64
+	finished bool
+}
+
+// NewPaddedDecrypter returns a PaddedDecrypter that decrypts using the given
+// block mode, which must be a decrypter.
+func NewPaddedDecrypter(mode cipher.BlockMode) *PaddedDecrypter {
+	return &PaddedDecrypter{mode: mode, buf: make([]byte, 0, mode.BlockSize())}
+}
+
+// NewPaddedCBCDecrypter returns a PaddedDecrypter that decrypts in cipher block
+// chaining mode, using the given Block and initialization vector. The length of
+// iv must be the same as the Block's block size.
+func NewPaddedCBCDecrypter(b cipher.Block, iv []byte) *PaddedDecrypter {
+	return NewPaddedDecrypter(cipher.NewCBCDecrypter(b, iv))
+}
+
+// BlockSize returns the block size of the underlying block mode.
+func (d *PaddedDecrypter) BlockSize() int {
+	return d.mode.BlockSize()
+}
+
+// Update decrypts all but the last block of the ciphertext seen so far, appends
+// the resulting plaintext to dst, and returns the updated slice. dst and src
+// must not overlap.
+func (d *PaddedDecrypter) Update(dst, src []byte) []byte {
+	if d.finished {
+		panic("pkcs7pad: Update called after Finish")
+	}
+	d.total += int64(len(src))
+	bs := d.mode.BlockSize()
+	if len(d.buf)+len(src) <= bs {
+		d.buf = append(d.buf, src...)
+		return dst
+	}
+
+	// From here on we know that at least one block can be decrypted
+	// while still leaving something in the buffer.
+	if len(d.buf) > 0 {
+		k := copy(d.buf[len(d.buf):bs], src)
+		src = src[k:]
+		dst = cryptAppend(d.mode, dst, d.buf[:bs])
+		d.buf = d.buf[:0]
+	}
+	n := (len(src) - 1) / bs * bs
+	dst = cryptAppend(d.mode, dst, src[:n])
+	d.buf = append(d.buf, src[n:]...)
+	return dst
+}
+
+// Finish decrypts and unpads the final block, appends the resulting plaintext
+// to dst, and returns the updated slice. It returns an error if the ciphertext
+// was not a non-zero multiple of the block size or if the padding is
+// malformed. The PaddedDecrypter must not be used after calling Finish.
+func (d *PaddedDecrypter) Finish(dst []byte) ([]byte, error) {
+	if d.finished {
+		panic("pkcs7pad: Finish called twice")
+	}
+	d.finished = true
+	bs := d.mode.BlockSize()
+	if d.total%int64(bs) != 0 {
+		return dst, errMisaligned(d.total, bs)
+	}
+	if len(d.buf) != bs {
+		return dst, ErrBadPadding
+	}
+	d.mode.CryptBlocks(d.buf, d.buf)
+	tail, err := UnpadBlock(d.buf, bs)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, tail...), nil
+}
+
+// EncryptCBC pads plaintext and encrypts it in cipher block chaining mode under
+// a random initialization vector, which is prepended to the returned
+// ciphertext. An error is returned only if reading random bytes fails.
+//
+// The ciphertext is not authenticated. Unless it is protected by some other
+// means, prefer an AEAD such as the one in the cbchmac subpackage.
+func EncryptCBC(b cipher.Block, plaintext []byte) ([]byte, error) {
+	bs := b.BlockSize()
+	out := make([]byte, bs, bs+len(plaintext)+bs-len(plaintext)%bs)
+	if _, err := io.ReadFull(rand.Reader, out); err != nil {
+		return nil, err
+	}
+	e := NewPaddedCBCEncrypter(b, out)
+	return e.Finish(e.Update(out, plaintext)), nil
+}
+
+// DecryptCBC decrypts a ciphertext produced by EncryptCBC, and returns the
+// unpadded plaintext. It returns an error wrapping ErrBadPadding if the
+// ciphertext is too short, is not a multiple of the block size, or has
+// malformed padding.
+func DecryptCBC(b cipher.Block, ciphertext []byte) ([]byte, error) {
+	bs := b.BlockSize()
+	if len(ciphertext)%bs != 0 {
+		return nil, errMisaligned(int64(len(ciphertext)), bs)
+	}
+	if len(ciphertext) < 2*bs {
+		return nil, ErrBadPadding
+	}
+	iv, ciphertext := ciphertext[:bs], ciphertext[bs:]
+	out := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(b, iv).CryptBlocks(out, ciphertext)
+	return UnpadBlock(out, bs)
+}
+
+// cryptAppend runs mode over src, appending the output to dst.
+func cryptAppend(mode cipher.BlockMode, dst, src []byte) []byte {
+	if len(src) == 0 {
+		return dst
+	}
+	head, tail := sliceForAppend(dst, len(src))
+	mode.CryptBlocks(tail, src)
+	return head
+}
+
+// sliceForAppend extends in by n bytes, returning the extended slice along
+// with the n bytes that were added.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
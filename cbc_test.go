@@ -0,0 +1,125 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+)
+
+var (
+	testKey = []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	testIV  = []byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff}
+)
+
+func testBlock(t testing.TB) cipher.Block {
+	b, err := aes.NewCipher(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPaddedCBC(t *testing.T) {
+	t.Parallel()
+
+	b := testBlock(t)
+	in := bytes.Repeat(testString, 5)
+	for chunk := 1; chunk <= 2*aes.BlockSize+1; chunk++ {
+		for n := 0; n <= len(in); n += 5 {
+			want := make([]byte, n+aes.BlockSize-n%aes.BlockSize)
+			cipher.NewCBCEncrypter(b, testIV).CryptBlocks(want, Pad(append([]byte(nil), in[:n]...), aes.BlockSize))
+
+			e := NewPaddedCBCEncrypter(b, testIV)
+			var ct []byte
+			for p := in[:n]; len(p) > 0; {
+				k := min(chunk, len(p))
+				ct = e.Update(ct, p[:k])
+				p = p[k:]
+			}
+			ct = e.Finish(ct)
+			if !bytes.Equal(ct, want) {
+				t.Fatalf("[%d/%d] %x != %x", chunk, n, ct, want)
+			}
+
+			d := NewPaddedCBCDecrypter(b, testIV)
+			var pt []byte
+			for p := ct; len(p) > 0; {
+				k := min(chunk, len(p))
+				pt = d.Update(pt, p[:k])
+				p = p[k:]
+			}
+			pt, err := d.Finish(pt)
+			if err != nil {
+				t.Fatalf("[%d/%d] error finishing: %v", chunk, n, err)
+			}
+			if !bytes.Equal(pt, in[:n]) {
+				t.Fatalf("[%d/%d] %x != %x", chunk, n, pt, in[:n])
+			}
+		}
+	}
+}
+
+func TestEncryptCBC(t *testing.T) {
+	t.Parallel()
+
+	b := testBlock(t)
+	for i, test := range PadTests {
+		ct, err := EncryptCBC(b, test.in)
+		if err != nil {
+			t.Fatalf("[%d] error encrypting: %v", i, err)
+		}
+		if len(ct) != aes.BlockSize+len(test.out) {
+			t.Errorf("[%d] ciphertext has length %d", i, len(ct))
+		}
+		pt, err := DecryptCBC(b, ct)
+		if err != nil {
+			t.Errorf("[%d] error decrypting: %v", i, err)
+		}
+		if !bytes.Equal(pt, test.in) {
+			t.Errorf("[%d] %x != %x", i, pt, test.in)
+		}
+	}
+
+	ct1, _ := EncryptCBC(b, testString)
+	ct2, _ := EncryptCBC(b, testString)
+	if bytes.Equal(ct1, ct2) {
+		t.Error("two encryptions of the same plaintext are identical")
+	}
+}
+
+func TestDecryptCBCErrors(t *testing.T) {
+	t.Parallel()
+
+	b := testBlock(t)
+	bad := [][]byte{
+		{},
+		testString,
+		testString[:15],
+		append(append([]byte(nil), testIV...), testString...),
+	}
+	for i, test := range bad {
+		if _, err := DecryptCBC(b, test); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+}
+
+func TestPaddedCBCDecrypterErrors(t *testing.T) {
+	t.Parallel()
+
+	b := testBlock(t)
+	bad := [][]byte{
+		{},
+		testString[:15],
+		bytes.Repeat(testString, 2)[:17],
+		testString,
+	}
+	for i, test := range bad {
+		d := NewPaddedCBCDecrypter(b, testIV)
+		if _, err := d.Finish(d.Update(nil, test)); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+// Package cbcfile implements a chunked file format for encrypting large files
+// with AES in CBC mode and HMAC-SHA256, in bounded memory.
+//
+// A file starts with a header: the 4 bytes "CBCF", a version byte, the chunk
+// size as a 32-bit big-endian integer, and a 16-byte random file nonce. The
+// plaintext is split into chunks of exactly the chunk size, except for the
+// last, which holds between 1 and chunk size bytes (or none, if the file is
+// empty). Each chunk is written as a 16-byte random initialization vector, its
+// ciphertext, and a 32-byte tag. Only the last chunk is padded, with PKCS#7, so
+// every other chunk's ciphertext is exactly the chunk size.
+//
+// The tag of a chunk is the HMAC-SHA256 of the header, the index of the chunk
+// as a 64-bit big-endian integer, a byte that is 1 for the last chunk and 0
+// otherwise, the initialization vector, and the ciphertext. It is checked
+// before the chunk is decrypted, and it binds each chunk to its file and
+// position, so chunks can't be reordered, spliced between files, or dropped
+// from the end without detection.
+//
+// A Reader decrypts a file sequentially from an io.Reader. A File decrypts
+// ranges of a file stored in an io.ReaderAt, reading only the chunks it needs.
+package cbcfile
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+const (
+	magic      = "CBCF"
+	version    = 1
+	nonceSize  = 16
+	headerSize = len(magic) + 1 + 4 + nonceSize
+	tagSize    = sha256.Size
+
+	// DefaultChunkSize is the chunk size used by NewWriter when none is
+	// given.
+	DefaultChunkSize = 64 << 10
+
+	// MaxChunkSize is the largest chunk size allowed, which bounds the
+	// memory used by a Reader.
+	MaxChunkSize = 16 << 20
+)
+
+var (
+	// ErrFormat is returned when the input does not start with a header in
+	// a version of the format this package understands.
+	ErrFormat = errors.New("cbcfile: not a cbcfile")
+
+	// ErrAuth is returned when a chunk fails authentication, because the key
+	// is wrong or the file was modified or truncated.
+	ErrAuth = errors.New("cbcfile: message authentication failed")
+
+	errClosed = errors.New("cbcfile: write to closed writer")
+)
+
+// file holds the keys and header shared by all the chunks of a file.
+type file struct {
+	block     cipher.Block
+	macKey    []byte
+	header    []byte
+	chunkSize int
+}
+
+func newFile(encKey, macKey, header []byte) (*file, error) {
+	chunkSize := int(binary.BigEndian.Uint32(header[len(magic)+1:]))
+	if err := checkChunkSize(chunkSize); err != nil {
+		return nil, err
+	}
+	b, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return &file{
+		block:     b,
+		macKey:    append([]byte(nil), macKey...),
+		header:    header,
+		chunkSize: chunkSize,
+	}, nil
+}
+
+func checkChunkSize(n int) error {
+	if n < aes.BlockSize || n > MaxChunkSize || n%aes.BlockSize != 0 {
+		return fmt.Errorf("cbcfile: chunk size %d must be a multiple of %d between %d and %d", n, aes.BlockSize, aes.BlockSize, MaxChunkSize)
+	}
+	return nil
+}
+
+// segmentSize returns the encoded size of a chunk other than the last.
+func (f *file) segmentSize() int {
+	return aes.BlockSize + f.chunkSize + tagSize
+}
+
+func (f *file) tag(index uint64, last bool, iv, ct []byte) []byte {
+	var pos [9]byte
+	binary.BigEndian.PutUint64(pos[:], index)
+	if last {
+		pos[8] = 1
+	}
+	mac := hmac.New(sha256.New, f.macKey)
+	mac.Write(f.header)
+	mac.Write(pos[:])
+	mac.Write(iv)
+	mac.Write(ct)
+	return mac.Sum(nil)
+}
+
+// open authenticates and decrypts an encoded chunk, appending the plaintext to
+// dst.
+func (f *file) open(dst, seg []byte, index uint64, last bool) ([]byte, error) {
+	n := len(seg) - aes.BlockSize - tagSize
+	if last {
+		if n < aes.BlockSize || n > f.chunkSize+aes.BlockSize || n%aes.BlockSize != 0 {
+			return dst, ErrAuth
+		}
+	} else if n != f.chunkSize {
+		return dst, ErrAuth
+	}
+	iv, ct, tag := seg[:aes.BlockSize], seg[aes.BlockSize:aes.BlockSize+n], seg[aes.BlockSize+n:]
+	if !hmac.Equal(tag, f.tag(index, last, iv, ct)) {
+		return dst, ErrAuth
+	}
+
+	start := len(dst)
+	dst = append(dst, ct...)
+	cipher.NewCBCDecrypter(f.block, iv).CryptBlocks(dst[start:], dst[start:])
+	if !last {
+		return dst, nil
+	}
+	// The chunk is authentic, so bad padding means a broken writer rather
+	// than an attacker, but it is still reported as ErrAuth.
+	pt, err := pkcs7pad.UnpadBlock(dst[start:], aes.BlockSize)
+	if err != nil {
+		return dst[:start], ErrAuth
+	}
+	return dst[:start+len(pt)], nil
+}
+
+// A Writer encrypts the data written to it into the cbcfile format. At most one
+// chunk of plaintext is buffered at a time.
+type Writer struct {
+	w     io.Writer
+	f     *file
+	buf   []byte
+	out   []byte
+	index uint64
+	err   error
+}
+
+// NewWriter writes a header to w and returns a Writer that encrypts to it with
+// the given AES key and HMAC key, using chunks of chunkSize bytes of
+// plaintext. If chunkSize is zero, DefaultChunkSize is used; otherwise it must
+// be a multiple of the AES block size no larger than MaxChunkSize. The caller
+// must call Close to write the last chunk.
+func NewWriter(w io.Writer, encKey, macKey []byte, chunkSize int) (*Writer, error) {
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if err := checkChunkSize(chunkSize); err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[len(magic)] = version
+	binary.BigEndian.PutUint32(header[len(magic)+1:], uint32(chunkSize))
+	if _, err := io.ReadFull(rand.Reader, header[headerSize-nonceSize:]); err != nil {
+		return nil, err
+	}
+	f, err := newFile(encKey, macKey, header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		w:   w,
+		f:   f,
+		buf: make([]byte, 0, chunkSize),
+		out: make([]byte, 0, f.segmentSize()+aes.BlockSize),
+	}, nil
+}
+
+// Write encrypts and writes every complete chunk of data, buffering the rest.
+// A full chunk is held back until more data arrives, since it might be the
+// last.
+func (cw *Writer) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n := 0
+	for len(p) > 0 {
+		if len(cw.buf) == cw.f.chunkSize {
+			if err := cw.flush(false); err != nil {
+				return n, err
+			}
+		}
+		k := copy(cw.buf[len(cw.buf):cw.f.chunkSize], p)
+		cw.buf = cw.buf[:len(cw.buf)+k]
+		n += k
+		p = p[k:]
+	}
+	return n, nil
+}
+
+// Close writes the last chunk. It does not close the underlying writer.
+func (cw *Writer) Close() error {
+	if cw.err != nil {
+		if cw.err == errClosed {
+			return nil
+		}
+		return cw.err
+	}
+	if err := cw.flush(true); err != nil {
+		return err
+	}
+	cw.err = errClosed
+	return nil
+}
+
+func (cw *Writer) flush(last bool) error {
+	out := cw.out[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, out); err != nil {
+		cw.err = err
+		return err
+	}
+	if last {
+		out = pkcs7pad.AppendPad(out, cw.buf, aes.BlockSize)
+	} else {
+		out = append(out, cw.buf...)
+	}
+	iv, ct := out[:aes.BlockSize], out[aes.BlockSize:]
+	cipher.NewCBCEncrypter(cw.f.block, iv).CryptBlocks(ct, ct)
+	out = append(out, cw.f.tag(cw.index, last, iv, ct)...)
+	if _, err := cw.w.Write(out); err != nil {
+		cw.err = err
+		return err
+	}
+	cw.index++
+	cw.buf = cw.buf[:0]
+	return nil
+}
+
+// A Reader decrypts a file in the cbcfile format. Each chunk is authenticated
+// before any of its plaintext is returned, and the end of the file is only
+// reported once the last chunk has been authenticated.
+type Reader struct {
+	r     io.Reader
+	f     *file
+	seg   []byte
+	buf   []byte
+	plain []byte
+	index uint64
+	err   error
+}
+
+// NewReader reads a header from r and returns a Reader that decrypts the rest
+// of r with the given AES key and HMAC key. It returns ErrFormat if r does not
+// start with a valid header.
+func NewReader(r io.Reader, encKey, macKey []byte) (*Reader, error) {
+	header, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := newFile(encKey, macKey, header)
+	if err != nil {
+		return nil, err
+	}
+	// One byte more than the largest last chunk, so that a full buffer
+	// means the chunk at its start is not the last.
+	n := f.segmentSize() + aes.BlockSize + 1
+	return &Reader{r: r, f: f, seg: make([]byte, 0, n), buf: make([]byte, 0, f.chunkSize+aes.BlockSize)}, nil
+}
+
+func readHeader(r io.Reader) ([]byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrFormat
+		}
+		return nil, err
+	}
+	if string(header[:len(magic)]) != magic || header[len(magic)] != version {
+		return nil, ErrFormat
+	}
+	if err := checkChunkSize(int(binary.BigEndian.Uint32(header[len(magic)+1:]))); err != nil {
+		return nil, ErrFormat
+	}
+	return header, nil
+}
+
+// Read reads decrypted data into p. It returns io.EOF after the last chunk, and
+// ErrAuth if a chunk fails authentication or the file is truncated.
+func (cr *Reader) Read(p []byte) (int, error) {
+	for len(cr.plain) == 0 {
+		if cr.err != nil {
+			return 0, cr.err
+		}
+		cr.err = cr.next()
+	}
+	n := copy(p, cr.plain)
+	cr.plain = cr.plain[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next chunk into cr.plain. It returns io.EOF
+// after decrypting the last chunk.
+func (cr *Reader) next() error {
+	n, err := io.ReadFull(cr.r, cr.seg[len(cr.seg):cap(cr.seg)])
+	cr.seg = cr.seg[:len(cr.seg)+n]
+	if err == nil {
+		size := cr.f.segmentSize()
+		cr.plain, err = cr.f.open(cr.buf, cr.seg[:size], cr.index, false)
+		if err != nil {
+			return err
+		}
+		cr.index++
+		cr.seg = cr.seg[:copy(cr.seg, cr.seg[size:])]
+		return nil
+	}
+	if err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	cr.plain, err = cr.f.open(cr.buf, cr.seg, cr.index, true)
+	if err != nil {
+		return err
+	}
+	return io.EOF
+}
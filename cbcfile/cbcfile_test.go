@@ -0,0 +1,161 @@
+package cbcfile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+var (
+	testEncKey = bytes.Repeat([]byte{0x01}, 32)
+	testMACKey = bytes.Repeat([]byte{0x02}, 32)
+)
+
+const testChunkSize = 32
+
+func encrypt(t *testing.T, msg []byte, chunkSize int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testEncKey, testMACKey, chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Write a byte at a time to exercise the buffering.
+	for i := range msg {
+		if _, err := w.Write(msg[i : i+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(data []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(data), testEncKey, testMACKey)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	msg := make([]byte, 5*testChunkSize)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	for n := 0; n <= len(msg); n++ {
+		data := encrypt(t, msg[:n], testChunkSize)
+		chunks := max(1, (n+testChunkSize-1)/testChunkSize)
+		want := headerSize + chunks*(16+tagSize) + (chunks-1)*testChunkSize + (n-(chunks-1)*testChunkSize)/16*16 + 16
+		if len(data) != want {
+			t.Errorf("[%d] encoded length %d, want %d", n, len(data), want)
+		}
+		out, err := decrypt(data)
+		if err != nil || !bytes.Equal(out, msg[:n]) {
+			t.Errorf("[%d] decrypt = %x, %v", n, out, err)
+		}
+
+		r, err := NewReader(iotest.OneByteReader(bytes.NewReader(data)), testEncKey, testMACKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := iotest.TestReader(r, msg[:n]); err != nil {
+			t.Errorf("[%d] %v", n, err)
+		}
+	}
+}
+
+func TestDefaultChunkSize(t *testing.T) {
+	t.Parallel()
+
+	msg := bytes.Repeat([]byte("x"), DefaultChunkSize+1)
+	data := encrypt(t, msg, 0)
+	if out, err := decrypt(data); err != nil || !bytes.Equal(out, msg) {
+		t.Errorf("decrypt = %d bytes, %v", len(out), err)
+	}
+}
+
+func TestTampering(t *testing.T) {
+	t.Parallel()
+
+	msg := bytes.Repeat([]byte("0123456789abcdef"), 6)
+	data := encrypt(t, msg, testChunkSize)
+	seg := 16 + testChunkSize + tagSize
+
+	for i := headerSize; i < len(data); i += 7 {
+		bad := bytes.Clone(data)
+		bad[i] ^= 0x80
+		if _, err := decrypt(bad); !errors.Is(err, ErrAuth) {
+			t.Errorf("[%d] flipped byte: %v", i, err)
+		}
+	}
+
+	// Dropping the last chunk must be detected.
+	if _, err := decrypt(data[:headerSize+2*seg]); !errors.Is(err, ErrAuth) {
+		t.Errorf("truncated: %v", err)
+	}
+	// So must swapping the first two chunks.
+	swapped := bytes.Clone(data)
+	copy(swapped[headerSize:], data[headerSize+seg:headerSize+2*seg])
+	copy(swapped[headerSize+seg:], data[headerSize:headerSize+seg])
+	if _, err := decrypt(swapped); !errors.Is(err, ErrAuth) {
+		t.Errorf("swapped: %v", err)
+	}
+	// And splicing chunks from another file.
+	other := encrypt(t, msg, testChunkSize)
+	spliced := bytes.Clone(data)
+	copy(spliced[headerSize:], other[headerSize:headerSize+seg])
+	if _, err := decrypt(spliced); !errors.Is(err, ErrAuth) {
+		t.Errorf("spliced: %v", err)
+	}
+
+	r, _ := NewReader(bytes.NewReader(data), testEncKey, bytes.Repeat([]byte{0x03}, 32))
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrAuth) {
+		t.Errorf("wrong key: %v", err)
+	}
+}
+
+func TestHeaderErrors(t *testing.T) {
+	t.Parallel()
+
+	data := encrypt(t, []byte("hello"), testChunkSize)
+	if _, err := decrypt(data[:headerSize-1]); !errors.Is(err, ErrFormat) {
+		t.Errorf("short header: %v", err)
+	}
+	for _, i := range []int{0, len(magic), len(magic) + 1, len(magic) + 4} {
+		bad := bytes.Clone(data)
+		bad[i] ^= 0x41
+		if _, err := decrypt(bad); !errors.Is(err, ErrFormat) {
+			t.Errorf("[%d] bad header: %v", i, err)
+		}
+	}
+	for _, size := range []int{-16, 8, 33, MaxChunkSize + 16} {
+		if _, err := NewWriter(io.Discard, testEncKey, testMACKey, size); err == nil {
+			t.Errorf("[%d] expected error", size)
+		}
+	}
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewWriter(io.Discard, testEncKey, testMACKey, testChunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if _, err := w.Write([]byte("x")); err == nil {
+		t.Error("expected error writing after Close")
+	}
+}
@@ -0,0 +1,172 @@
+package cbcfile
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+var errOffset = errors.New("cbcfile: negative offset")
+
+// A File provides random access to a file in the cbcfile format stored in an
+// io.ReaderAt. Only the chunks covering the requested range are read and
+// decrypted, and each is authenticated before any of its plaintext is
+// returned. The most recently used chunk is cached, so small sequential reads
+// decrypt each chunk once.
+//
+// ReadAt may be called concurrently. Read and Seek share a file offset, and
+// must not be called concurrently with each other.
+type File struct {
+	r    io.ReaderAt
+	f    *file
+	size int64
+
+	// lastIndex is the index of the last chunk, and last is its plaintext,
+	// which Open decrypts to learn the size of the file.
+	lastIndex int64
+	last      []byte
+
+	mu         sync.Mutex
+	seg        []byte
+	cache      []byte
+	cacheIndex int64
+
+	off int64
+}
+
+// Open returns a File that reads the size bytes of r as a file in the cbcfile
+// format, decrypting with the given AES key and HMAC key. It reads the header
+// and authenticates the last chunk, so it returns ErrFormat if the header is
+// invalid and ErrAuth if the file has been truncated or its last chunk
+// modified. Damage to other chunks is reported when they are read.
+func Open(r io.ReaderAt, size int64, encKey, macKey []byte) (*File, error) {
+	header, err := readHeader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	f, err := newFile(encKey, macKey, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// The last chunk is between minLast and seg+16 bytes long, a range
+	// narrower than seg, so the number of chunks before it is determined by
+	// the size of the file.
+	seg := int64(f.segmentSize())
+	minLast := int64(16 + 16 + tagSize)
+	body := size - int64(headerSize)
+	if body < minLast {
+		return nil, ErrAuth
+	}
+	lastIndex := (body - minLast) / seg
+	buf := make([]byte, body-lastIndex*seg)
+	if err := readFullAt(r, buf, int64(headerSize)+lastIndex*seg); err != nil {
+		return nil, err
+	}
+	last, err := f.open(nil, buf, uint64(lastIndex), true)
+	if err != nil {
+		return nil, err
+	}
+	return &File{
+		r:          r,
+		f:          f,
+		size:       lastIndex*int64(f.chunkSize) + int64(len(last)),
+		lastIndex:  lastIndex,
+		last:       last,
+		seg:        make([]byte, seg),
+		cache:      make([]byte, 0, f.chunkSize),
+		cacheIndex: -1,
+	}, nil
+}
+
+// Size returns the length of the decrypted file.
+func (cf *File) Size() int64 {
+	return cf.size
+}
+
+// ReadAt reads len(p) bytes of decrypted data starting at offset off. It
+// returns ErrAuth if any chunk in the range fails authentication.
+func (cf *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errOffset
+	}
+	n := 0
+	for n < len(p) && off < cf.size {
+		k, err := cf.readChunk(p[n:], off/int64(cf.f.chunkSize), int(off%int64(cf.f.chunkSize)))
+		n += k
+		off += int64(k)
+		if err != nil {
+			return n, err
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readFullAt reads exactly len(buf) bytes from r at offset off. A ReaderAt may
+// return io.EOF along with a full read that ends at the end of its input, so
+// the error is only reported if the read was short.
+func readFullAt(r io.ReaderAt, buf []byte, off int64) error {
+	n, err := r.ReadAt(buf, off)
+	if n == len(buf) {
+		return nil
+	}
+	if err == io.EOF || err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readChunk copies the plaintext of chunk i, starting at offset within, into p.
+func (cf *File) readChunk(p []byte, i int64, within int) (int, error) {
+	if i == cf.lastIndex {
+		return copy(p, cf.last[within:]), nil
+	}
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	if cf.cacheIndex != i {
+		cf.cacheIndex = -1
+		seg := int64(len(cf.seg))
+		if err := readFullAt(cf.r, cf.seg, int64(headerSize)+i*seg); err != nil {
+			return 0, err
+		}
+		pt, err := cf.f.open(cf.cache[:0], cf.seg, uint64(i), false)
+		if err != nil {
+			return 0, err
+		}
+		cf.cache, cf.cacheIndex = pt, i
+	}
+	return copy(p, cf.cache[within:]), nil
+}
+
+// Read reads decrypted data from the current offset into p, and advances the
+// offset.
+func (cf *File) Read(p []byte) (int, error) {
+	n, err := cf.ReadAt(p, cf.off)
+	cf.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek sets the offset for the next Read, as described by io.Seeker. Offsets
+// are in the decrypted file.
+func (cf *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += cf.off
+	case io.SeekEnd:
+		offset += cf.size
+	default:
+		return 0, errors.New("cbcfile: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errOffset
+	}
+	cf.off = offset
+	return offset, nil
+}
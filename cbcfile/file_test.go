@@ -0,0 +1,110 @@
+package cbcfile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	msg := make([]byte, 4*testChunkSize)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	for _, n := range []int{0, 1, 15, 16, testChunkSize, testChunkSize + 1, 3*testChunkSize - 1, len(msg)} {
+		data := encrypt(t, msg[:n], testChunkSize)
+		f, err := Open(bytes.NewReader(data), int64(len(data)), testEncKey, testMACKey)
+		if err != nil {
+			t.Fatalf("[%d] Open: %v", n, err)
+		}
+		if f.Size() != int64(n) {
+			t.Errorf("[%d] Size = %d", n, f.Size())
+		}
+		if err := iotest.TestReader(f, msg[:n]); err != nil {
+			t.Errorf("[%d] %v", n, err)
+		}
+
+		for off := 0; off <= n; off++ {
+			for l := 0; off+l <= n+1; l += 7 {
+				p := make([]byte, l)
+				k, err := f.ReadAt(p, int64(off))
+				want := msg[off:min(off+l, n)]
+				if !bytes.Equal(p[:k], want) || (k < l) != (err == io.EOF) {
+					t.Errorf("[%d] ReadAt(%d, %d) = %x, %v, want %x", n, l, off, p[:k], err, want)
+				}
+			}
+		}
+	}
+}
+
+// eofReaderAt returns io.EOF along with any read that reaches the end of its
+// input, as io.ReaderAt allows.
+type eofReaderAt []byte
+
+func (r eofReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := bytes.NewReader(r).ReadAt(p, off)
+	if err == nil && off+int64(n) == int64(len(r)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func TestFileEOFReaderAt(t *testing.T) {
+	t.Parallel()
+
+	msg := bytes.Repeat([]byte("0123456789abcdef"), 6)
+	data := encrypt(t, msg, testChunkSize)
+	f, err := Open(eofReaderAt(data), int64(len(data)), testEncKey, testMACKey)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	p := make([]byte, len(msg))
+	if n, err := f.ReadAt(p, 0); n != len(msg) || err != nil || !bytes.Equal(p, msg) {
+		t.Errorf("ReadAt = %d, %v", n, err)
+	}
+}
+
+func TestFileErrors(t *testing.T) {
+	t.Parallel()
+
+	msg := bytes.Repeat([]byte("0123456789abcdef"), 6)
+	data := encrypt(t, msg, testChunkSize)
+	open := func(data []byte) (*File, error) {
+		return Open(bytes.NewReader(data), int64(len(data)), testEncKey, testMACKey)
+	}
+
+	seg := 16 + testChunkSize + tagSize
+	for _, n := range []int{headerSize, headerSize + 1, headerSize + seg, len(data) - 16, len(data) - 1} {
+		if _, err := open(data[:n]); !errors.Is(err, ErrAuth) {
+			t.Errorf("[%d] truncated: %v", n, err)
+		}
+	}
+	if _, err := open(data[:headerSize-1]); !errors.Is(err, ErrFormat) {
+		t.Errorf("short header: %v", err)
+	}
+
+	// Damage to a middle chunk is only found when it is read.
+	bad := bytes.Clone(data)
+	bad[headerSize+seg+20] ^= 1
+	f, err := open(bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 8)
+	if _, err := f.ReadAt(p, 0); err != nil {
+		t.Errorf("reading the first chunk: %v", err)
+	}
+	if _, err := f.ReadAt(p, testChunkSize); !errors.Is(err, ErrAuth) {
+		t.Errorf("reading the damaged chunk: %v", err)
+	}
+	if _, err := f.ReadAt(p, -1); err == nil {
+		t.Error("expected error for negative offset")
+	}
+	if _, err := f.Seek(-1, io.SeekStart); err == nil {
+		t.Error("expected error seeking to a negative offset")
+	}
+}
@@ -0,0 +1,131 @@
+// Package cbchmac implements an AEAD built from a block cipher in CBC mode with
+// PKCS#7 padding and HMAC, composed using encrypt-then-MAC.
+//
+// The construction follows the AEAD_AES_CBC_HMAC_SHA2 family from
+// draft-mcgrew-aead-aes-cbc-hmac-sha2 and RFC 7518: the tag is the truncated
+// HMAC of the additional data, the initialization vector, the ciphertext, and
+// the length of the additional data in bits as a 64-bit big-endian integer.
+//
+// https://tools.ietf.org/html/rfc7518#section-5.2
+//
+// The JOSE content encryption algorithms built on this construction are
+// available as NewA128CBCHS256, NewA192CBCHS384, and NewA256CBCHS512.
+//
+// It is intended for interoperating with existing CBC-based systems. New
+// designs should prefer an AEAD such as AES-GCM.
+package cbchmac
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// MinTagSize is the smallest tag size accepted by New.
+const MinTagSize = 12
+
+var errOpen = errors.New("cbchmac: message authentication failed")
+
+type aead struct {
+	block   cipher.Block
+	macKey  []byte
+	hash    func() hash.Hash
+	tagSize int
+}
+
+// New returns a cipher.AEAD that encrypts with the given block cipher in CBC
+// mode and authenticates with HMAC using the given hash function and key. Tags
+// are truncated to tagSize bytes, which must be between MinTagSize and the
+// size of the hash.
+//
+// The nonce is used as the CBC initialization vector, so it has the cipher's
+// block size and must be unpredictable as well as unique: callers should
+// generate every nonce with crypto/rand.
+func New(b cipher.Block, macKey []byte, h func() hash.Hash, tagSize int) (cipher.AEAD, error) {
+	if size := h().Size(); tagSize < MinTagSize || tagSize > size {
+		return nil, fmt.Errorf("cbchmac: tag size %d must be between %d and %d", tagSize, MinTagSize, size)
+	}
+	return &aead{
+		block:   b,
+		macKey:  append([]byte(nil), macKey...),
+		hash:    h,
+		tagSize: tagSize,
+	}, nil
+}
+
+func (a *aead) NonceSize() int { return a.block.BlockSize() }
+
+// Overhead returns the maximum difference between the lengths of a plaintext
+// and its ciphertext: a full block of padding plus the tag.
+func (a *aead) Overhead() int { return a.block.BlockSize() + a.tagSize }
+
+func (a *aead) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	bs := a.block.BlockSize()
+	if len(nonce) != bs {
+		panic("cbchmac: incorrect nonce length given to Seal")
+	}
+	n := len(plaintext) + bs - len(plaintext)%bs
+	ret, out := sliceForAppend(dst, n+a.tagSize)
+
+	ct := pkcs7pad.AppendPad(out[:0], plaintext, bs)
+	cipher.NewCBCEncrypter(a.block, nonce).CryptBlocks(ct, ct)
+	copy(out[n:], a.tag(nonce, ct, additionalData))
+	return ret
+}
+
+func (a *aead) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	bs := a.block.BlockSize()
+	if len(nonce) != bs {
+		panic("cbchmac: incorrect nonce length given to Open")
+	}
+	if len(ciphertext) < bs+a.tagSize || (len(ciphertext)-a.tagSize)%bs != 0 {
+		return nil, errOpen
+	}
+	ct, tag := ciphertext[:len(ciphertext)-a.tagSize], ciphertext[len(ciphertext)-a.tagSize:]
+	if subtle.ConstantTimeCompare(tag, a.tag(nonce, ct, additionalData)) != 1 {
+		return nil, errOpen
+	}
+
+	ret, out := sliceForAppend(dst, len(ct))
+	cipher.NewCBCDecrypter(a.block, nonce).CryptBlocks(out, ct)
+	// The ciphertext is authentic, so a padding error here means the
+	// sender is broken rather than that someone is probing us. We still
+	// don't distinguish it from a bad tag.
+	pt, err := pkcs7pad.UnpadBlock(out, bs)
+	if err != nil {
+		clear(out)
+		return nil, errOpen
+	}
+	return ret[:len(dst)+len(pt)], nil
+}
+
+func (a *aead) tag(nonce, ciphertext, additionalData []byte) []byte {
+	var al [8]byte
+	binary.BigEndian.PutUint64(al[:], uint64(len(additionalData))*8)
+
+	mac := hmac.New(a.hash, a.macKey)
+	mac.Write(additionalData)
+	mac.Write(nonce)
+	mac.Write(ciphertext)
+	mac.Write(al[:])
+	return mac.Sum(nil)[:a.tagSize]
+}
+
+// sliceForAppend extends in by n bytes, returning the extended slice along
+// with the n bytes that were added.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
@@ -0,0 +1,89 @@
+package cbchmac
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha256"
+	"testing"
+)
+
+var (
+	testEncKey = []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	testMACKey = []byte{0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f}
+	testNonce  = []byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff}
+	testAD     = []byte("additional data")
+)
+
+func TestSealOpen(t *testing.T) {
+	t.Parallel()
+
+	b, _ := aes.NewCipher(testEncKey)
+	a, err := New(b, testMACKey, sha256.New, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.NonceSize() != aes.BlockSize || a.Overhead() != 32 {
+		t.Errorf("NonceSize, Overhead = %d, %d", a.NonceSize(), a.Overhead())
+	}
+
+	pt := []byte("attack at dawn, or perhaps a little after")
+	for n := 0; n <= len(pt); n++ {
+		ct := a.Seal(nil, testNonce, pt[:n], testAD)
+		if len(ct) > n+a.Overhead() || (len(ct)-16)%aes.BlockSize != 0 {
+			t.Errorf("[%d] bad ciphertext length %d", n, len(ct))
+		}
+		out, err := a.Open(nil, testNonce, ct, testAD)
+		if err != nil {
+			t.Errorf("[%d] error opening: %v", n, err)
+		}
+		if !bytes.Equal(out, pt[:n]) {
+			t.Errorf("[%d] %x != %x", n, out, pt[:n])
+		}
+
+		// Sealing and opening in place must also work.
+		buf := append(make([]byte, 0, n+a.Overhead()), pt[:n]...)
+		ct2 := a.Seal(buf[:0], testNonce, buf, testAD)
+		if !bytes.Equal(ct2, ct) {
+			t.Errorf("[%d] in-place seal %x != %x", n, ct2, ct)
+		}
+		out, err = a.Open(ct2[:0], testNonce, ct2, testAD)
+		if err != nil || !bytes.Equal(out, pt[:n]) {
+			t.Errorf("[%d] in-place open = %x, %v", n, out, err)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	t.Parallel()
+
+	b, _ := aes.NewCipher(testEncKey)
+	a, _ := New(b, testMACKey, sha256.New, 16)
+	ct := a.Seal(nil, testNonce, []byte("hello world"), testAD)
+
+	if _, err := a.Open(nil, testNonce, ct, []byte("other data")); err != errOpen {
+		t.Errorf("wrong additional data: expected %v, got %v", errOpen, err)
+	}
+	for i := range ct {
+		bad := append([]byte(nil), ct...)
+		bad[i] ^= 0x01
+		if _, err := a.Open(nil, testNonce, bad, testAD); err != errOpen {
+			t.Errorf("[%d] expected %v, got %v", i, errOpen, err)
+		}
+	}
+	for _, n := range []int{0, 15, 16, 31} {
+		if _, err := a.Open(nil, testNonce, ct[:n], testAD); err != errOpen {
+			t.Errorf("[%d] expected %v, got %v", n, errOpen, err)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	t.Parallel()
+
+	b, _ := aes.NewCipher(testEncKey)
+	for _, size := range []int{0, MinTagSize - 1, sha256.Size + 1} {
+		if _, err := New(b, testMACKey, sha256.New, size); err == nil {
+			t.Errorf("[%d] expected error", size)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cbchmac
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+)
+
+// NewA128CBCHS256 returns the AES_128_CBC_HMAC_SHA_256 AEAD used by the JOSE
+// "A128CBC-HS256" content encryption algorithm. The key must be 32 bytes long:
+// the first half is the MAC key and the second half is the encryption key.
+//
+// https://tools.ietf.org/html/rfc7518#section-5.2.3
+func NewA128CBCHS256(key []byte) (cipher.AEAD, error) {
+	return newComposite(key, 16, sha256.New)
+}
+
+// NewA192CBCHS384 returns the AES_192_CBC_HMAC_SHA_384 AEAD used by the JOSE
+// "A192CBC-HS384" content encryption algorithm. The key must be 48 bytes long.
+//
+// https://tools.ietf.org/html/rfc7518#section-5.2.4
+func NewA192CBCHS384(key []byte) (cipher.AEAD, error) {
+	return newComposite(key, 24, sha512.New384)
+}
+
+// NewA256CBCHS512 returns the AES_256_CBC_HMAC_SHA_512 AEAD used by the JOSE
+// "A256CBC-HS512" content encryption algorithm. The key must be 64 bytes long.
+//
+// https://tools.ietf.org/html/rfc7518#section-5.2.5
+func NewA256CBCHS512(key []byte) (cipher.AEAD, error) {
+	return newComposite(key, 32, sha512.New)
+}
+
+// newComposite splits key into a MAC key and an encryption key of n bytes
+// each. The tag is truncated to n bytes as well.
+func newComposite(key []byte, n int, h func() hash.Hash) (cipher.AEAD, error) {
+	if len(key) != 2*n {
+		return nil, fmt.Errorf("cbchmac: invalid key length %d, want %d", len(key), 2*n)
+	}
+	b, err := aes.NewCipher(key[n:])
+	if err != nil {
+		return nil, err
+	}
+	return New(b, key[:n], h, n)
+}
@@ -0,0 +1,111 @@
+package cbchmac
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Test vectors from RFC 7518, Appendix B.
+var (
+	jweP  = []byte("A cipher system must not be required to be secret, and it must be able to fall into the hands of the enemy without inconvenience")
+	jweIV = unhex("1a f3 8c 2d c2 b9 6f fd d8 66 94 09 23 41 bc 04")
+	jweA  = []byte("The second principle of Auguste Kerckhoffs")
+)
+
+var JWETests = []struct {
+	name string
+	new  func([]byte) (cipher.AEAD, error)
+	key  int
+	e, t []byte
+}{
+	{
+		"A128CBC-HS256",
+		NewA128CBCHS256,
+		32,
+		unhex(`c8 0e df a3 2d df 39 d5 ef 00 c0 b4 68 83 42 79
+			a2 e4 6a 1b 80 49 f7 92 f7 6b fe 54 b9 03 a9 c9
+			a9 4a c9 b4 7a d2 65 5c 5f 10 f9 ae f7 14 27 e2
+			fc 6f 9b 3f 39 9a 22 14 89 f1 63 62 c7 03 23 36
+			09 d4 5a c6 98 64 e3 32 1c f8 29 35 ac 40 96 c8
+			6e 13 33 14 c5 40 19 e8 ca 79 80 df a4 b9 cf 1b
+			38 4c 48 6f 3a 54 c5 10 78 15 8e e5 d7 9d e5 9f
+			bd 34 d8 48 b3 d6 95 50 a6 76 46 34 44 27 ad e5
+			4b 88 51 ff b5 98 f7 f8 00 74 b9 47 3c 82 e2 db`),
+		unhex("65 2c 3f a3 6b 0a 7c 5b 32 19 fa b3 a3 0b c1 c4"),
+	},
+	{
+		"A256CBC-HS512",
+		NewA256CBCHS512,
+		64,
+		unhex(`4a ff aa ad b7 8c 31 c5 da 4b 1b 59 0d 10 ff bd
+			3d d8 d5 d3 02 42 35 26 91 2d a0 37 ec bc c7 bd
+			82 2c 30 1d d6 7c 37 3b cc b5 84 ad 3e 92 79 c2
+			e6 d1 2a 13 74 b7 7f 07 75 53 df 82 94 10 44 6b
+			36 eb d9 70 66 29 6a e6 42 7e a7 5c 2e 08 46 a1
+			1a 09 cc f5 37 0d c8 0b fe cb ad 28 c7 3f 09 b3
+			a3 b7 5e 66 2a 25 94 41 0a e4 96 b2 e2 e6 60 9e
+			31 e6 e0 2c c8 37 f0 53 d2 1f 37 ff 4f 51 95 0b
+			be 26 38 d0 9d d7 a4 93 09 30 80 6d 07 03 b1 f6`),
+		unhex(`4d d3 b4 c0 88 a7 f4 5c 21 68 39 64 5b 20 12 bf
+			2e 62 69 a8 c5 6a 81 6d bc 1b 26 77 61 95 5b c5`),
+	},
+}
+
+func TestJWE(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range JWETests {
+		key := make([]byte, test.key)
+		for i := range key {
+			key[i] = byte(i)
+		}
+		a, err := test.new(key)
+		if err != nil {
+			t.Fatalf("[%s] %v", test.name, err)
+		}
+
+		want := append(append([]byte(nil), test.e...), test.t...)
+		ct := a.Seal(nil, jweIV, jweP, jweA)
+		if !bytes.Equal(ct, want) {
+			t.Errorf("[%s] %x != %x", test.name, ct, want)
+		}
+		pt, err := a.Open(nil, jweIV, want, jweA)
+		if err != nil {
+			t.Errorf("[%s] error opening: %v", test.name, err)
+		}
+		if !bytes.Equal(pt, jweP) {
+			t.Errorf("[%s] %q != %q", test.name, pt, jweP)
+		}
+
+		if _, err := test.new(key[1:]); err == nil {
+			t.Errorf("[%s] expected error for short key", test.name)
+		}
+	}
+}
+
+func TestA192CBCHS384(t *testing.T) {
+	t.Parallel()
+
+	a, err := NewA192CBCHS384(make([]byte, 48))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Overhead() != 16+24 {
+		t.Errorf("Overhead = %d", a.Overhead())
+	}
+	pt, err := a.Open(nil, jweIV, a.Seal(nil, jweIV, jweP, jweA), jweA)
+	if err != nil || !bytes.Equal(pt, jweP) {
+		t.Errorf("round trip = %q, %v", pt, err)
+	}
+}
@@ -0,0 +1,82 @@
+// Command padcheck reports code that uses the pkcs7pad packages in ways that
+// can create a padding oracle. See package padcheck for the patterns it
+// detects.
+//
+// Usage:
+//
+//	padcheck [dir ...]
+//
+// Each argument is a directory holding a single package, which defaults to the
+// current directory. Test files are not checked. Diagnostics are printed one
+// per line as file:line:col: message, and the exit status is 1 if there were
+// any, or 2 if a package could not be loaded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/zenazn/pkcs7pad/padcheck"
+)
+
+func main() {
+	flag.Parse()
+	os.Exit(run(flag.Args(), os.Stdout, os.Stderr))
+}
+
+func run(dirs []string, stdout, stderr io.Writer) int {
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	status := 0
+	for _, dir := range dirs {
+		fset := token.NewFileSet()
+		files, info, err := load(fset, dir)
+		if err != nil {
+			fmt.Fprintf(stderr, "padcheck: %v\n", err)
+			status = 2
+			continue
+		}
+		for _, d := range padcheck.Check(files, info) {
+			fmt.Fprintf(stdout, "%s: %s\n", fset.Position(d.Pos), d.Message)
+			status = max(status, 1)
+		}
+	}
+	return status
+}
+
+// load parses and type-checks the package in dir.
+func load(fset *token.FileSet, dir string) ([]*ast.File, *types.Info, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, f)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check(pkg.ImportPath, fset, files, info); err != nil {
+		return nil, nil, err
+	}
+	return files, info, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"../../padcheck/testdata/src/a"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, stderr %q", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %q", lines)
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, "a.go:") {
+			t.Errorf("[%d] diagnostic %q has no position", i, line)
+		}
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"../../padcheck"}, &stdout, &stderr); code != 0 {
+		t.Errorf("exit status %d for a clean package, stdout %q, stderr %q", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"does-not-exist"}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d for a missing package", code)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// maxRecord is the longest line processBatch accepts.
+const maxRecord = 64 << 20
+
+// errRecords is returned by processBatch when at least one record failed. The
+// details are in the output.
+var errRecords = errors.New("pkcs7pad: some records could not be processed")
+
+type batchInput struct {
+	ID   json.RawMessage `json:"id,omitempty"`
+	Data string          `json:"data"`
+}
+
+type batchResult struct {
+	ID    json.RawMessage `json:"id,omitempty"`
+	Line  int             `json:"line"`
+	OK    bool            `json:"ok"`
+	Data  *string         `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// processBatch pads or unpads each line of in as a separate record, writing a
+// JSON result for each to out.
+func processBatch(cmd string, ndjson bool, size int, inFormat, outFormat format, in io.Reader, out io.Writer) error {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(nil, maxRecord)
+	bw := bufio.NewWriter(out)
+	enc := json.NewEncoder(bw)
+
+	failed := false
+	for line := 1; sc.Scan(); line++ {
+		res := batchResult{Line: line}
+		data, err := batchRecord(sc.Bytes(), ndjson, &res.ID, inFormat)
+		if err == nil {
+			if cmd == "pad" {
+				data = pkcs7pad.Pad(data, size)
+			} else {
+				data, err = pkcs7pad.UnpadBlock(data, size)
+			}
+		}
+		if err != nil {
+			failed = true
+			res.Error = err.Error()
+		} else {
+			s := outFormat.encode(data)
+			res.OK, res.Data = true, &s
+		}
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+	// Flush the results already produced even if the input was cut short,
+	// so that callers can tell how far processing got.
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if failed {
+		return errRecords
+	}
+	return nil
+}
+
+// batchRecord decodes a single line of batch input.
+func batchRecord(line []byte, ndjson bool, id *json.RawMessage, f format) ([]byte, error) {
+	if !ndjson {
+		return f.decode(line)
+	}
+	var rec batchInput
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	*id = rec.ID
+	return f.decode([]byte(rec.Data))
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func decodeResults(t *testing.T, out string) []batchResult {
+	t.Helper()
+	var results []batchResult
+	dec := json.NewDecoder(strings.NewReader(out))
+	for dec.More() {
+		var res batchResult
+		if err := dec.Decode(&res); err != nil {
+			t.Fatalf("bad output %q: %v", out, err)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+func TestBatchLines(t *testing.T) {
+	t.Parallel()
+
+	in := "aGVsbG8=\nd29ybGQ=\n\n"
+	out, stderr, code := runTest(t, []byte(in), "pad", "-batch", "lines", "-block-size", "8", "-in-format", "base64", "-out-format", "hex")
+	if code != 0 {
+		t.Fatalf("exited with %d: %q", code, stderr)
+	}
+	want := []string{"68656c6c6f030303", "776f726c64030303", "0808080808080808"}
+	results := decodeResults(t, out)
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, res := range results {
+		if res.Line != i+1 || !res.OK || res.Data == nil || *res.Data != want[i] {
+			t.Errorf("[%d] unexpected result %+v", i, res)
+		}
+	}
+}
+
+func TestBatchDefaultFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		// Records are base64 by default.
+		{nil, "68656c6c6f030303"},
+		{[]string{"-in-format", "raw"}, "614756736247383d0808080808080808"},
+	}
+	for i, test := range tests {
+		args := append([]string{"pad", "-batch", "lines", "-block-size", "8", "-out-format", "hex"}, test.args...)
+		out, stderr, code := runTest(t, []byte("aGVsbG8=\n"), args...)
+		if code != 0 {
+			t.Fatalf("[%d] exited with %d: %q", i, code, stderr)
+		}
+		results := decodeResults(t, out)
+		if len(results) != 1 || results[0].Data == nil || *results[0].Data != test.want {
+			t.Errorf("[%d] unexpected results %+v", i, results)
+		}
+	}
+}
+
+func TestBatchNDJSON(t *testing.T) {
+	t.Parallel()
+
+	in := `{"id": 7, "data": "68656c6c6f030303"}
+{"id": "x", "data": "68656c6c6f030304"}
+{"data": "zz"}
+not json
+{"data": "0808080808080808"}
+`
+	out, stderr, code := runTest(t, []byte(in), "unpad", "-batch", "ndjson", "-block-size", "8", "-in-format", "hex")
+	if code != 1 || !strings.Contains(stderr, "some records") {
+		t.Fatalf("exited with %d: %q", code, stderr)
+	}
+	results := decodeResults(t, out)
+	if len(results) != 5 {
+		t.Fatalf("got %d results, want 5", len(results))
+	}
+	if res := results[0]; !res.OK || string(res.ID) != "7" || *res.Data != "aGVsbG8=" {
+		t.Errorf("[0] unexpected result %+v", res)
+	}
+	for i, res := range results[1:4] {
+		if res.OK || res.Data != nil || res.Error == "" {
+			t.Errorf("[%d] unexpected result %+v", i+1, res)
+		}
+	}
+	if string(results[1].ID) != `"x"` {
+		t.Errorf("[1] id %s", results[1].ID)
+	}
+	if res := results[4]; !res.OK || res.Line != 5 || *res.Data != "" {
+		t.Errorf("[4] unexpected result %+v", res)
+	}
+}
+
+func TestBatchOversizedRecord(t *testing.T) {
+	t.Parallel()
+
+	in := "aGVsbG8=\nd29ybGQ=\n" + strings.Repeat("A", maxRecord+1) + "\n"
+	out, stderr, code := runTest(t, []byte(in), "pad", "-batch", "lines", "-block-size", "8", "-in-format", "base64")
+	if code == 0 || stderr == "" {
+		t.Fatalf("exited with %d: %q", code, stderr)
+	}
+	results := decodeResults(t, out)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for i, res := range results {
+		if res.Line != i+1 || !res.OK {
+			t.Errorf("[%d] unexpected result %+v", i, res)
+		}
+	}
+}
+
+func TestBatchUsage(t *testing.T) {
+	t.Parallel()
+
+	for i, args := range [][]string{
+		{"pad", "-batch", "csv"},
+		{"inspect", "-batch", "lines"},
+		{"unpad", "-batch", "lines", "-in-place", "f"},
+	} {
+		if _, _, code := runTest(t, nil, args...); code != 2 {
+			t.Errorf("[%d] exited with %d", i, code)
+		}
+	}
+}
+
+func TestBatchOutputFile(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "out")
+	if _, _, code := runTest(t, []byte("aGVsbG8=\n!\n"), "pad", "-batch", "lines", "-in-format", "base64", "-o", name); code != 1 {
+		t.Errorf("exited with %d", code)
+	}
+	out, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results := decodeResults(t, string(out)); len(results) != 2 || !results[0].OK || results[1].OK {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A format is an encoding for the command's input or output.
+type format string
+
+var formats = []format{"raw", "hex", "base64", "base64url"}
+
+func (f *format) String() string { return string(*f) }
+
+func (f *format) Set(s string) error {
+	for _, v := range formats {
+		if format(s) == v {
+			*f = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q", s)
+}
+
+// formatNames returns the supported formats, for flag usage messages.
+func formatNames() string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// decoder returns a reader that decodes r from format f. Whitespace in encoded
+// input is ignored, so that files with trailing newlines work.
+func (f format) decoder(r io.Reader) io.Reader {
+	switch f {
+	case "hex":
+		return hex.NewDecoder(skipReader{r, " \t\r\n"})
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, skipReader{r, " \t\r\n"})
+	case "base64url":
+		// Accept base64url both with and without padding.
+		return base64.NewDecoder(base64.RawURLEncoding, skipReader{r, " \t\r\n="})
+	}
+	return r
+}
+
+// encoder returns a writer that encodes to w in format f. Closing it flushes
+// any partial encoded output and, for text formats, adds a trailing newline.
+// It does not close w.
+func (f format) encoder(w io.Writer) io.WriteCloser {
+	switch f {
+	case "hex":
+		return lineCloser{hex.NewEncoder(w), w}
+	case "base64":
+		return lineCloser{base64.NewEncoder(base64.StdEncoding, w), w}
+	case "base64url":
+		return lineCloser{base64.NewEncoder(base64.RawURLEncoding, w), w}
+	}
+	return nopCloser{w}
+}
+
+// decode decodes a single encoded record in format f.
+func (f format) decode(s []byte) ([]byte, error) {
+	return io.ReadAll(f.decoder(bytes.NewReader(s)))
+}
+
+// encode encodes a single record in format f, without a trailing newline.
+func (f format) encode(b []byte) string {
+	switch f {
+	case "hex":
+		return hex.EncodeToString(b)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b)
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	return string(b)
+}
+
+// skipReader reads from r, dropping any of the bytes in skip.
+type skipReader struct {
+	r    io.Reader
+	skip string
+}
+
+func (s skipReader) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		j := 0
+		for _, b := range p[:n] {
+			if strings.IndexByte(s.skip, b) < 0 {
+				p[j] = b
+				j++
+			}
+		}
+		if j > 0 || err != nil || n == 0 {
+			return j, err
+		}
+	}
+}
+
+type lineCloser struct {
+	io.Writer
+	w io.Writer
+}
+
+func (l lineCloser) Close() error {
+	if c, ok := l.Writer.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(l.w, "\n")
+	return err
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format  string
+		encoded string
+	}{
+		{"raw", "hello\x03\x03\x03"},
+		{"hex", "68656c6c6f030303\n"},
+		{"base64", "aGVsbG8DAwM=\n"},
+		{"base64url", "aGVsbG8DAwM\n"},
+	}
+	for i, test := range tests {
+		out, stderr, code := runTest(t, []byte("hello"), "pad", "-block-size", "8", "-out-format", test.format)
+		if code != 0 || out != test.encoded {
+			t.Errorf("[%d] pad: %q, %q, %d", i, out, stderr, code)
+		}
+		out, stderr, code = runTest(t, []byte(test.encoded), "unpad", "-block-size", "8", "-in-format", test.format)
+		if code != 0 || out != "hello" {
+			t.Errorf("[%d] unpad: %q, %q, %d", i, out, stderr, code)
+		}
+	}
+}
+
+func TestFormatInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format, in string
+	}{
+		{"hex", "68 65 6c 6c\n6f 03 03 03\n"},
+		{"hex", "68656C6C6F030303"},
+		{"base64", "aGVsbG8D\r\nAwM=\r\n"},
+		{"base64url", "aGVsbG8DAwM=\n"},
+	}
+	for i, test := range tests {
+		out, stderr, code := runTest(t, []byte(test.in), "unpad", "-block-size", "8", "-in-format", test.format)
+		if code != 0 || out != "hello" {
+			t.Errorf("[%d] unpad: %q, %q, %d", i, out, stderr, code)
+		}
+	}
+
+	if _, stderr, code := runTest(t, []byte("zz"), "unpad", "-in-format", "hex"); code != 1 || !strings.Contains(stderr, "invalid byte") {
+		t.Errorf("bad hex: %q, %d", stderr, code)
+	}
+	if _, stderr, code := runTest(t, nil, "pad", "-out-format", "ascii85"); code != 2 || stderr == "" {
+		t.Errorf("unknown format: %q, %d", stderr, code)
+	}
+}
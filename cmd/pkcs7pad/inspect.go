@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// commonBlockSizes are the block sizes whose alignment inspect reports.
+var commonBlockSizes = []int{8, 16, 32}
+
+// tailLen is the number of trailing bytes inspect dumps.
+const tailLen = 32
+
+// A report is the JSON output of the inspect command.
+type report struct {
+	Warning string `json:"warning"`
+	Length  int    `json:"length"`
+
+	// Valid reports whether the input ends in well-formed PKCS#7 padding of
+	// any length, and PadLen is the padding length claimed by the final
+	// byte. BlockValid additionally requires that the input be aligned to
+	// BlockSize and that the padding fit in a single block.
+	Valid      bool `json:"valid"`
+	PadLen     int  `json:"pad_len"`
+	BlockSize  int  `json:"block_size"`
+	BlockValid bool `json:"block_valid"`
+
+	Aligned map[string]bool `json:"aligned"`
+	Tail    string          `json:"tail"`
+}
+
+// inspect reads all of r and writes a report on its padding to w. It is not
+// constant time.
+func inspect(size int, r io.Reader, w io.Writer) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	rep := report{
+		Warning:   "debug only: this report is not computed in constant time",
+		Length:    len(buf),
+		BlockSize: size,
+		Aligned:   make(map[string]bool),
+	}
+	if len(buf) > 0 {
+		rep.PadLen = int(buf[len(buf)-1])
+	}
+	_, err = pkcs7pad.UnpadFast(buf)
+	rep.Valid = err == nil
+	_, err = pkcs7pad.UnpadBlock(buf, size)
+	rep.BlockValid = err == nil
+	for _, s := range commonBlockSizes {
+		rep.Aligned[strconv.Itoa(s)] = len(buf)%s == 0
+	}
+	rep.Tail = hex.Dump(buf[max(0, len(buf)-tailLen):])
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
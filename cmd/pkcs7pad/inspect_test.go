@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in         string
+		valid      bool
+		padLen     int
+		blockValid bool
+		aligned16  bool
+	}{
+		{"", false, 0, false, true},
+		{"hello\x03\x03\x03", true, 3, false, false},
+		{"hello world\x05\x05\x05\x05\x05", true, 5, true, true},
+		{"hello world\x05\x05\x05\x04\x05", false, 5, false, true},
+		{strings.Repeat("\x14", 32), true, 20, false, true},
+	}
+	for i, test := range tests {
+		out, stderr, code := runTest(t, []byte(test.in), "inspect")
+		if code != 0 {
+			t.Errorf("[%d] exited with %d: %q", i, code, stderr)
+			continue
+		}
+		var rep report
+		if err := json.Unmarshal([]byte(out), &rep); err != nil {
+			t.Errorf("[%d] bad JSON %q: %v", i, out, err)
+			continue
+		}
+		if rep.Length != len(test.in) || rep.Valid != test.valid || rep.PadLen != test.padLen ||
+			rep.BlockValid != test.blockValid || rep.Aligned["16"] != test.aligned16 {
+			t.Errorf("[%d] unexpected report %+v", i, rep)
+		}
+		if rep.Warning == "" || rep.BlockSize != 16 {
+			t.Errorf("[%d] unexpected report %+v", i, rep)
+		}
+	}
+}
+
+func TestInspectTail(t *testing.T) {
+	t.Parallel()
+
+	out, _, _ := runTest(t, []byte(strings.Repeat("a", 40)+"\x08\x08\x08\x08\x08\x08\x08\x08"), "inspect", "-block-size", "8")
+	var rep report
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatal(err)
+	}
+	if !rep.BlockValid || !strings.Contains(rep.Tail, "08 08 08 08") || strings.Count(rep.Tail, "\n") != 2 {
+		t.Errorf("unexpected report %+v", rep)
+	}
+}
@@ -0,0 +1,215 @@
+// Command pkcs7pad adds and removes PKCS#7 padding.
+//
+// Usage:
+//
+//	pkcs7pad pad [flags] [file]
+//	pkcs7pad unpad [flags] [file]
+//	pkcs7pad inspect [flags] [file]
+//	pkcs7pad migrate [flags] file...
+//
+// With no file, or when file is "-", pkcs7pad reads standard input. It writes
+// to standard output unless -o is given. The flags are:
+//
+//	-block-size n     block size in bytes, between 1 and 255 (default 16)
+//	-in-format f      encoding of the input (default raw, or base64 with -batch)
+//	-out-format f     encoding of the output (default raw)
+//	-o file           write output to file
+//	-in-place         unpad only: remove the padding from file itself
+//	-batch mode       process one record per line; mode is lines or ndjson
+//
+// The supported encodings are raw, hex, base64, and base64url. Whitespace in
+// encoded input is ignored, and encoded output ends with a newline. base64url
+// output is unpadded, but padded input is accepted.
+//
+// The inspect command prints a JSON report describing the padding of its
+// input, for debugging. It ignores -out-format. Unlike unpad, inspect does not
+// run in constant time, so it must not be used where timing can be observed
+// by an attacker, such as on a server decrypting untrusted ciphertext.
+//
+// With -in-place, unpad reads only the final block of the file and truncates
+// the file to remove the padding, which is much faster than rewriting a large
+// file. The file is left unchanged if the padding is malformed. -in-place
+// can't be combined with -o or with encoded input or output.
+//
+// With -batch, pad and unpad process each line of the input as a separate
+// record and write one JSON object per record, so they can be used in data
+// migration pipelines. In lines mode, each line is a record in the input
+// format. In ndjson mode, each line is a JSON object whose "data" field holds
+// the record in the input format, and whose "id" field, if any, is copied to
+// the output. Each output object has the 1-based line number in "line", "ok"
+// reporting whether the record was processed, and either the result in "data"
+// (in the output format) or the reason for the failure in "error". Records
+// are base64 unless -in-format says otherwise; -in-format raw takes each line
+// as it is. Since records are embedded in JSON, base64 is used in place of raw
+// output. The exit status is 1 if any record failed.
+//
+// The migrate command helps retire CBC encryption. Each file must hold a
+// ciphertext produced by pkcs7pad.EncryptCBC: an initialization vector
+// followed by AES-CBC ciphertext with PKCS#7 padding. migrate decrypts it with
+// the key given by -cbc-key and writes the plaintext re-encrypted with AES-GCM
+// under the key given by -gcm-key, as a random 12-byte nonce followed by the
+// sealed plaintext, to a new file named by appending -suffix (default ".gcm"),
+// in -out-dir if given. Keys are hex-encoded and read from the environment
+// (env:NAME) or a file (file:PATH), so that they don't appear in the process
+// list. Existing files are never overwritten, and input files are left in
+// place. migrate writes a JSON report with one object per file to standard
+// output, and exits with status 1 if any file failed. It reveals padding
+// errors, so it should only be run over trusted, stored data.
+//
+// If unpad finds malformed padding it exits with status 1. Since the input is
+// processed as a stream, anything already written to the output should be
+// discarded; when -o is given, the output file is removed.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+const usage = `usage: pkcs7pad pad [flags] [file]
+       pkcs7pad unpad [flags] [file]
+       pkcs7pad inspect [flags] [file]
+       pkcs7pad migrate [flags] file...
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the given arguments, and returns its exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, args := args[0], args[1:]
+	if cmd == "migrate" {
+		return runMigrate(args, stdout, stderr)
+	}
+	if cmd != "pad" && cmd != "unpad" && cmd != "inspect" {
+		fmt.Fprintf(stderr, "pkcs7pad: unknown command %q\n%s", cmd, usage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	size, _ := pkcs7pad.NewBlockSize(16)
+	fs.TextVar(&size, "block-size", size, "block size in `bytes`, between 1 and 255")
+	inFormat, outFormat := format("raw"), format("raw")
+	fs.Var(&inFormat, "in-format", "encoding of the input: "+formatNames()+"; base64 with -batch")
+	fs.Var(&outFormat, "out-format", "encoding of the output: "+formatNames())
+	outName := fs.String("o", "", "write output to `file` instead of standard output")
+	batch := fs.String("batch", "", "process one record per line; `mode` is lines or ndjson")
+	inPlace := fs.Bool("in-place", false, "unpad only: remove the padding from the file by truncating it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintf(stderr, "pkcs7pad: %s takes at most one file\n", cmd)
+		return 2
+	}
+	if *inPlace {
+		if cmd != "unpad" || fs.NArg() != 1 || fs.Arg(0) == "-" || *outName != "" || *batch != "" || inFormat != "raw" || outFormat != "raw" {
+			fmt.Fprintln(stderr, "pkcs7pad: -in-place requires unpad with a single file, and no -o, -batch, or formats")
+			return 2
+		}
+		if err := unpadFile(fs.Arg(0), size.Int()); err != nil {
+			printError(stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	if *batch != "" && (cmd == "inspect" || (*batch != "lines" && *batch != "ndjson")) {
+		fmt.Fprintln(stderr, "pkcs7pad: -batch must be lines or ndjson, and is only supported by pad and unpad")
+		return 2
+	}
+
+	in := stdin
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			printError(stderr, err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+
+	out := stdout
+	var outFile *os.File
+	if *outName != "" {
+		f, err := os.Create(*outName)
+		if err != nil {
+			printError(stderr, err)
+			return 1
+		}
+		out, outFile = f, f
+	}
+
+	var err error
+	if cmd == "inspect" {
+		err = inspect(size.Int(), inFormat.decoder(in), out)
+	} else if *batch != "" {
+		inSet := false
+		fs.Visit(func(f *flag.Flag) { inSet = inSet || f.Name == "in-format" })
+		if !inSet {
+			inFormat = "base64"
+		}
+		if outFormat == "raw" {
+			outFormat = "base64"
+		}
+		err = processBatch(cmd, *batch == "ndjson", size.Int(), inFormat, outFormat, in, out)
+	} else {
+		enc := outFormat.encoder(out)
+		err = process(cmd, size.Int(), inFormat.decoder(in), enc)
+		if err == nil {
+			err = enc.Close()
+		}
+	}
+	if outFile != nil {
+		closeErr := outFile.Close()
+		// Batch results for records that failed are still useful.
+		if closeErr != nil || (err != nil && err != errRecords) {
+			os.Remove(*outName)
+		}
+		err = errors.Join(err, closeErr)
+	}
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// printError prints err to w, adding the command name unless the error came
+// from the pkcs7pad package and already has it.
+func printError(w io.Writer, err error) {
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "pkcs7pad: ") {
+		msg = "pkcs7pad: " + msg
+	}
+	fmt.Fprintln(w, msg)
+}
+
+// process copies in to out, padding or unpadding it according to cmd.
+func process(cmd string, size int, in io.Reader, out io.Writer) error {
+	var r io.Reader
+	if cmd == "pad" {
+		r = pkcs7pad.NewPadReader(in, size)
+	} else {
+		r = pkcs7pad.NewUnpadReader(in, size)
+	}
+	_, err := io.Copy(out, r)
+	return err
+}
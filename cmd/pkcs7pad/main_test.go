@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runTest(t *testing.T, stdin []byte, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, bytes.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestPadUnpad(t *testing.T) {
+	t.Parallel()
+
+	out, stderr, code := runTest(t, []byte("hello"), "pad", "-block-size", "8")
+	if code != 0 || out != "hello\x03\x03\x03" {
+		t.Fatalf("pad: %q, %q, %d", out, stderr, code)
+	}
+	out, stderr, code = runTest(t, []byte(out), "unpad", "--block-size=8")
+	if code != 0 || out != "hello" {
+		t.Fatalf("unpad: %q, %q, %d", out, stderr, code)
+	}
+
+	out, _, code = runTest(t, nil, "pad")
+	if code != 0 || out != strings.Repeat("\x10", 16) {
+		t.Errorf("default block size: %q, %d", out, code)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in")
+	padded := filepath.Join(dir, "padded")
+	if err := os.WriteFile(in, []byte("test engineers"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, code := runTest(t, nil, "pad", "-o", padded, in); code != 0 {
+		t.Fatalf("pad: %q, %d", stderr, code)
+	}
+	out, stderr, code := runTest(t, nil, "unpad", padded)
+	if code != 0 || out != "test engineers" {
+		t.Errorf("unpad: %q, %q, %d", out, stderr, code)
+	}
+
+	bad := filepath.Join(dir, "bad")
+	if _, _, code := runTest(t, []byte("not padded"), "unpad", "-o", bad); code != 1 {
+		t.Errorf("bad padding exited with %d", code)
+	}
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Errorf("output file was not removed: %v", err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{nil, 2},
+		{[]string{"frobnicate"}, 2},
+		{[]string{"pad", "-block-size", "0"}, 2},
+		{[]string{"pad", "-block-size", "256"}, 2},
+		{[]string{"pad", "a", "b"}, 2},
+		{[]string{"pad", "/nonexistent/file"}, 1},
+	}
+	for i, test := range tests {
+		if _, stderr, code := runTest(t, nil, test.args...); code != test.code || stderr == "" {
+			t.Errorf("[%d] exited with %d (%q), want %d", i, code, stderr, test.code)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+type migrateResult struct {
+	File   string `json:"file"`
+	Output string `json:"output,omitempty"`
+	OK     bool   `json:"ok"`
+	Bytes  int    `json:"bytes,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// runMigrate runs the migrate command, and returns its exit status.
+func runMigrate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	cbcKeySpec := fs.String("cbc-key", "", "`source` of the hex-encoded AES key of the input: env:NAME or file:PATH")
+	gcmKeySpec := fs.String("gcm-key", "", "`source` of the hex-encoded AES key of the output: env:NAME or file:PATH")
+	inFormat, outFormat := format("raw"), format("raw")
+	fs.Var(&inFormat, "in-format", "encoding of the input files: "+formatNames())
+	fs.Var(&outFormat, "out-format", "encoding of the output files: "+formatNames())
+	suffix := fs.String("suffix", ".gcm", "`suffix` added to the name of each output file")
+	outDir := fs.String("out-dir", "", "write output files to `dir` instead of next to the input")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 || *cbcKeySpec == "" || *gcmKeySpec == "" || (*suffix == "" && *outDir == "") {
+		fmt.Fprintln(stderr, "pkcs7pad: migrate requires -cbc-key, -gcm-key, an output location, and at least one file")
+		return 2
+	}
+
+	cbcKey, err := loadKey(*cbcKeySpec)
+	if err != nil {
+		printError(stderr, fmt.Errorf("-cbc-key: %w", err))
+		return 2
+	}
+	gcmKey, err := loadKey(*gcmKeySpec)
+	if err != nil {
+		printError(stderr, fmt.Errorf("-gcm-key: %w", err))
+		return 2
+	}
+	b, _ := aes.NewCipher(cbcKey)
+	gb, _ := aes.NewCipher(gcmKey)
+	aead, err := cipher.NewGCM(gb)
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	bw := bufio.NewWriter(stdout)
+	enc := json.NewEncoder(bw)
+	failed := false
+	for _, name := range fs.Args() {
+		res := migrateResult{File: name, Output: name + *suffix}
+		if *outDir != "" {
+			res.Output = filepath.Join(*outDir, filepath.Base(name)+*suffix)
+		}
+		res.Bytes, err = migrateFile(name, res.Output, b, aead, inFormat, outFormat)
+		if err != nil {
+			failed = true
+			res.Output, res.Error = "", err.Error()
+		} else {
+			res.OK = true
+		}
+		if err := enc.Encode(res); err != nil {
+			printError(stderr, err)
+			return 1
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		printError(stderr, err)
+		return 1
+	}
+	if failed {
+		printError(stderr, errRecords)
+		return 1
+	}
+	return 0
+}
+
+// loadKey reads a hex-encoded AES key from an environment variable or a file.
+func loadKey(spec string) ([]byte, error) {
+	var s string
+	switch kind, arg, _ := strings.Cut(spec, ":"); kind {
+	case "env":
+		v, ok := os.LookupEnv(arg)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", arg)
+		}
+		s = v
+	case "file":
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, err
+		}
+		s = string(b)
+	default:
+		return nil, fmt.Errorf("key source %q must be env:NAME or file:PATH", spec)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("key is not hex-encoded: %w", err)
+	}
+	if n := len(key); n != 16 && n != 24 && n != 32 {
+		return nil, fmt.Errorf("key is %d bytes, want 16, 24, or 32", n)
+	}
+	return key, nil
+}
+
+// migrateFile decrypts the named file, which holds a ciphertext produced by
+// pkcs7pad.EncryptCBC, and writes it re-encrypted with aead to a new file
+// named out, as a random nonce followed by the sealed plaintext. It returns
+// the length of the plaintext.
+func migrateFile(name, out string, b cipher.Block, aead cipher.AEAD, inFormat, outFormat format) (int, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return 0, err
+	}
+	data, err = inFormat.decode(data)
+	if err != nil {
+		return 0, err
+	}
+	pt, err := pkcs7pad.DecryptCBC(b, data)
+	if err != nil {
+		return 0, err
+	}
+	defer clear(pt)
+
+	sealed := make([]byte, aead.NonceSize(), aead.NonceSize()+len(pt)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, sealed); err != nil {
+		return 0, err
+	}
+	sealed = aead.Seal(sealed, sealed, pt, nil)
+
+	// Never overwrite an existing file, which might be an earlier
+	// migration's only copy of the data.
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	w := outFormat.encoder(f)
+	_, err = w.Write(sealed)
+	err = errors.Join(err, w.Close(), f.Close())
+	if err != nil {
+		os.Remove(out)
+		return 0, err
+	}
+	return len(pt), nil
+}
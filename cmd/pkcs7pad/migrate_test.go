@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func decodeMigrateResults(t *testing.T, out string) []migrateResult {
+	t.Helper()
+	var results []migrateResult
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var res migrateResult
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatalf("bad output %q: %v", out, err)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cbcKey, gcmKey := bytes.Repeat([]byte{1}, 16), bytes.Repeat([]byte{2}, 32)
+	cbcKeyFile, gcmKeyFile := filepath.Join(dir, "cbc.key"), filepath.Join(dir, "gcm.key")
+	os.WriteFile(cbcKeyFile, []byte(hex.EncodeToString(cbcKey)+"\n"), 0o600)
+	os.WriteFile(gcmKeyFile, []byte(hex.EncodeToString(gcmKey)), 0o600)
+
+	b, _ := aes.NewCipher(cbcKey)
+	good, bad := filepath.Join(dir, "good"), filepath.Join(dir, "bad")
+	ct, err := pkcs7pad.EncryptCBC(b, []byte("hello, gcm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(good, ct, 0o600)
+	os.WriteFile(bad, make([]byte, 15), 0o600)
+
+	out, stderr, code := runTest(t, nil, "migrate", "-cbc-key", "file:"+cbcKeyFile, "-gcm-key", "file:"+gcmKeyFile, good, bad)
+	if code != 1 {
+		t.Fatalf("exited with %d: %q", code, stderr)
+	}
+	results := decodeMigrateResults(t, out)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if res := results[0]; !res.OK || res.File != good || res.Output != good+".gcm" || res.Bytes != 10 {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if res := results[1]; res.OK || res.Output != "" || !strings.Contains(res.Error, "bad padding") {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if _, err := os.Stat(bad + ".gcm"); !os.IsNotExist(err) {
+		t.Errorf("output written for a failed file: %v", err)
+	}
+
+	sealed, err := os.ReadFile(good + ".gcm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gb, _ := aes.NewCipher(gcmKey)
+	aead, _ := cipher.NewGCM(gb)
+	pt, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil || string(pt) != "hello, gcm" {
+		t.Errorf("Open = %q, %v", pt, err)
+	}
+
+	// Running again must not overwrite the output.
+	out, _, code = runTest(t, nil, "migrate", "-cbc-key", "file:"+cbcKeyFile, "-gcm-key", "file:"+gcmKeyFile, good)
+	if res := decodeMigrateResults(t, out); code != 1 || res[0].OK {
+		t.Errorf("second run: %q, %d", out, code)
+	}
+	if again, _ := os.ReadFile(good + ".gcm"); !bytes.Equal(again, sealed) {
+		t.Error("output was overwritten")
+	}
+}
+
+func TestMigrateFormats(t *testing.T) {
+	t.Parallel()
+
+	dir, outDir := t.TempDir(), t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	key := bytes.Repeat([]byte{3}, 24)
+	os.WriteFile(keyFile, []byte(hex.EncodeToString(key)), 0o600)
+
+	b, _ := aes.NewCipher(key)
+	ct, _ := pkcs7pad.EncryptCBC(b, []byte("encoded"))
+	name := filepath.Join(dir, "blob.hex")
+	os.WriteFile(name, []byte(hex.EncodeToString(ct)+"\n"), 0o600)
+
+	_, stderr, code := runTest(t, nil, "migrate", "-cbc-key", "file:"+keyFile, "-gcm-key", "file:"+keyFile,
+		"-in-format", "hex", "-out-format", "base64", "-out-dir", outDir, "-suffix", "", name)
+	if code != 0 {
+		t.Fatalf("exited with %d: %q", code, stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "blob.hex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := format("base64").decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, _ := cipher.NewGCM(b)
+	if pt, err := aead.Open(nil, sealed[:12], sealed[12:], nil); err != nil || string(pt) != "encoded" {
+		t.Errorf("Open = %q, %v", pt, err)
+	}
+}
+
+func TestMigrateEnvKey(t *testing.T) {
+	t.Setenv("PKCS7PAD_TEST_KEY", hex.EncodeToString(make([]byte, 16)))
+	b, _ := aes.NewCipher(make([]byte, 16))
+	ct, _ := pkcs7pad.EncryptCBC(b, nil)
+	name := filepath.Join(t.TempDir(), "empty")
+	os.WriteFile(name, ct, 0o600)
+
+	out, stderr, code := runTest(t, nil, "migrate", "-cbc-key", "env:PKCS7PAD_TEST_KEY", "-gcm-key", "env:PKCS7PAD_TEST_KEY", name)
+	if code != 0 || !strings.Contains(out, `"ok":true`) {
+		t.Errorf("exited with %d: %q, %q", code, out, stderr)
+	}
+}
+
+func TestMigrateUsage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	shortKey := filepath.Join(dir, "short")
+	os.WriteFile(shortKey, []byte("0011"), 0o600)
+	for i, args := range [][]string{
+		{"migrate", "-cbc-key", "file:x", "-gcm-key", "file:x"},
+		{"migrate", "-cbc-key", "file:x", "f"},
+		{"migrate", "-cbc-key", "plain", "-gcm-key", "plain", "f"},
+		{"migrate", "-cbc-key", "env:PKCS7PAD_UNSET_KEY", "-gcm-key", "file:" + shortKey, "f"},
+		{"migrate", "-cbc-key", "file:" + shortKey, "-gcm-key", "file:" + shortKey, "f"},
+	} {
+		if _, _, code := runTest(t, nil, args...); code != 2 {
+			t.Errorf("[%d] exited with %d, want 2", i, code)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// unpadFile removes the PKCS#7 padding from the named file by truncating it.
+// Only the final block is read.
+func unpadFile(name string, size int) error {
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	_, err = pkcs7pad.UnpadFile(f, size)
+	return errors.Join(err, f.Close())
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnpadInPlace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, out string
+		code    int
+	}{
+		{"hello\x03\x03\x03", "hello", 0},
+		{"\x08\x08\x08\x08\x08\x08\x08\x08", "", 0},
+		{"hello world!\x04\x04\x04\x04", "hello world!", 0},
+		{"hello\x03\x02\x03", "hello\x03\x02\x03", 1},
+		{"hello", "hello", 1},
+		{"", "", 1},
+	}
+	dir := t.TempDir()
+	for i, test := range tests {
+		name := filepath.Join(dir, "f")
+		if err := os.WriteFile(name, []byte(test.in), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, stderr, code := runTest(t, nil, "unpad", "-block-size", "8", "-in-place", name); code != test.code {
+			t.Errorf("[%d] exited with %d (%q), want %d", i, code, stderr, test.code)
+		}
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.out {
+			t.Errorf("[%d] %q != %q", i, got, test.out)
+		}
+	}
+}
+
+func TestUnpadInPlaceUsage(t *testing.T) {
+	t.Parallel()
+
+	for i, args := range [][]string{
+		{"pad", "-in-place", "f"},
+		{"unpad", "-in-place"},
+		{"unpad", "-in-place", "-"},
+		{"unpad", "-in-place", "-o", "g", "f"},
+		{"unpad", "-in-place", "-in-format", "hex", "f"},
+	} {
+		if _, _, code := runTest(t, nil, args...); code != 2 {
+			t.Errorf("[%d] exited with %d", i, code)
+		}
+	}
+}
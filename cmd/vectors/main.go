@@ -0,0 +1,189 @@
+// Command vectors writes a JSON corpus of PKCS#7 padding test vectors, so that
+// implementations in other languages can be checked for byte-identical
+// behavior.
+//
+// Usage:
+//
+//	vectors [-sizes 1,8,16,32,255] [-seed n] [-o file]
+//
+// The output is a JSON object whose "vectors" field is a list of test cases.
+// Each has a block size, a hex-encoded padded buffer, and whether unpadding it
+// with that block size must succeed. Valid cases also have the hex-encoded
+// input that pads to the padded buffer; invalid cases have a comment
+// describing how the padding is malformed. Unpadding is expected to be strict:
+// the buffer must be a non-zero multiple of the block size, and the padding
+// must be between 1 and the block size bytes long.
+//
+// The output depends only on the flags, so a corpus can be regenerated and
+// compared.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// A corpus is the JSON document written by the command.
+type corpus struct {
+	Description string   `json:"description"`
+	Vectors     []vector `json:"vectors"`
+}
+
+// A vector is a single test case.
+type vector struct {
+	Comment   string  `json:"comment,omitempty"`
+	BlockSize int     `json:"block_size"`
+	Input     *string `json:"input,omitempty"`
+	Padded    string  `json:"padded"`
+	Valid     bool    `json:"valid"`
+}
+
+func main() {
+	sizes := flag.String("sizes", "1,8,16,32,255", "comma-separated block `sizes` to generate vectors for")
+	seed := flag.Int64("seed", 1, "seed for the random message contents")
+	out := flag.String("o", "", "write the corpus to `file` instead of standard output")
+	flag.Parse()
+
+	var bs []int
+	for _, s := range strings.Split(*sizes, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 1 || n > 255 {
+			fmt.Fprintf(os.Stderr, "vectors: invalid block size %q\n", s)
+			os.Exit(2)
+		}
+		bs = append(bs, n)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "vectors:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(w, generate(bs, rand.New(rand.NewSource(*seed)))); err != nil {
+		fmt.Fprintln(os.Stderr, "vectors:", err)
+		os.Exit(1)
+	}
+}
+
+func write(w io.Writer, c corpus) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// generate returns vectors for each of the given block sizes.
+func generate(sizes []int, rng *rand.Rand) corpus {
+	c := corpus{
+		Description: "PKCS#7 padding test vectors (RFC 5652, section 6.3) generated by github.com/zenazn/pkcs7pad/cmd/vectors",
+	}
+	for _, size := range sizes {
+		c.Vectors = append(c.Vectors, validVectors(size, rng)...)
+		c.Vectors = append(c.Vectors, invalidVectors(size, rng)...)
+	}
+	return c
+}
+
+// messageLens returns the message lengths to generate valid vectors for: every
+// length up to two blocks for small block sizes, and the lengths around block
+// boundaries for large ones.
+func messageLens(size int) []int {
+	if size <= 32 {
+		lens := make([]int, 2*size+1)
+		for i := range lens {
+			lens[i] = i
+		}
+		return lens
+	}
+	return []int{0, 1, size - 1, size, size + 1, 2*size - 1, 2 * size}
+}
+
+func validVectors(size int, rng *rand.Rand) []vector {
+	var vs []vector
+	for _, n := range messageLens(size) {
+		msg := make([]byte, n)
+		rng.Read(msg)
+		padded := pkcs7pad.Pad(append([]byte(nil), msg...), size)
+		input := hex.EncodeToString(msg)
+		vs = append(vs, vector{
+			BlockSize: size,
+			Input:     &input,
+			Padded:    hex.EncodeToString(padded),
+			Valid:     true,
+		})
+	}
+	return vs
+}
+
+func invalidVectors(size int, rng *rand.Rand) []vector {
+	var vs []vector
+	add := func(comment string, buf []byte) {
+		if _, err := pkcs7pad.UnpadBlock(buf, size); err == nil {
+			panic(fmt.Sprintf("vectors: %q case for block size %d is valid: %x", comment, size, buf))
+		}
+		vs = append(vs, vector{
+			Comment:   comment,
+			BlockSize: size,
+			Padded:    hex.EncodeToString(buf),
+		})
+	}
+	// block returns n random blocks whose final byte is b.
+	block := func(n int, b byte) []byte {
+		buf := make([]byte, n*size)
+		rng.Read(buf)
+		buf[len(buf)-1] = b
+		return buf
+	}
+
+	add("empty buffer", nil)
+	add("final byte is zero", block(1, 0))
+	if size < 255 {
+		add("padding length greater than the block size", block(2, byte(size+1)))
+	}
+	if size > 1 {
+		add("length not a multiple of the block size", pkcs7pad.Pad(block(1, 0), size)[1:])
+
+		full := repeat(size, size)
+		for i := 0; i < size-1; i++ {
+			buf := append([]byte(nil), full...)
+			buf[i] ^= 0x01
+			add(fmt.Sprintf("full block of padding with byte %d corrupted", i), buf)
+		}
+		for _, p := range []int{2, size / 2, size - 1} {
+			if p < 2 {
+				continue
+			}
+			for _, i := range []int{size - p, size - 2} {
+				buf := block(1, byte(p))
+				for j := size - p; j < size-1; j++ {
+					buf[j] = byte(p)
+				}
+				buf[i] = byte(p - 1)
+				add(fmt.Sprintf("padding of length %d with byte %d wrong", p, i), buf)
+			}
+		}
+	}
+	return vs
+}
+
+// repeat returns n copies of the byte b.
+func repeat(n, b int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(b)
+	}
+	return buf
+}
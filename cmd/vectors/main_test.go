@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	c := generate([]int{1, 8, 16, 255}, rand.New(rand.NewSource(1)))
+	var valid, invalid int
+	for i, v := range c.Vectors {
+		padded, err := hex.DecodeString(v.Padded)
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+		unpad, err := pkcs7pad.UnpadBlock(padded, v.BlockSize)
+		if (err == nil) != v.Valid {
+			t.Errorf("[%d] %+v: unpad error %v", i, v, err)
+		}
+		if !v.Valid {
+			invalid++
+			if v.Input != nil || v.Comment == "" {
+				t.Errorf("[%d] unexpected invalid vector %+v", i, v)
+			}
+			continue
+		}
+		valid++
+		if v.Input == nil {
+			t.Fatalf("[%d] valid vector without input", i)
+		}
+		input, err := hex.DecodeString(*v.Input)
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+		if !bytes.Equal(unpad, input) {
+			t.Errorf("[%d] unpadded to %x, want %x", i, unpad, input)
+		}
+	}
+	if valid == 0 || invalid == 0 {
+		t.Errorf("%d valid and %d invalid vectors", valid, invalid)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	t.Parallel()
+
+	var a, b bytes.Buffer
+	if err := write(&a, generate([]int{16}, rand.New(rand.NewSource(7)))); err != nil {
+		t.Fatal(err)
+	}
+	if err := write(&b, generate([]int{16}, rand.New(rand.NewSource(7)))); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("output is not deterministic")
+	}
+	var c corpus
+	if err := json.Unmarshal(a.Bytes(), &c); err != nil || len(c.Vectors) == 0 {
+		t.Errorf("bad output: %v", err)
+	}
+}
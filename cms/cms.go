@@ -0,0 +1,171 @@
+// Package cms implements the EncryptedContentInfo type of the Cryptographic
+// Message Syntax, and the content-encryption process for block ciphers in CBC
+// mode, as defined in RFC 5652.
+//
+// https://tools.ietf.org/html/rfc5652#section-6.1
+// https://tools.ietf.org/html/rfc5652#section-6.3
+//
+// The supported content-encryption algorithms are AES in CBC mode (RFC 3565)
+// and Triple-DES in CBC mode (RFC 3370), each of which takes its
+// initialization vector as an OCTET STRING parameter. Key management, the
+// RecipientInfo structures of EnvelopedData, is left to the caller.
+//
+// Only DER is supported: encoders that use constructed, indefinite-length
+// OCTET STRINGs for the encrypted content produce BER that Parse rejects.
+package cms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// Object identifiers for content types and content-encryption algorithms.
+var (
+	OIDData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+
+	OIDAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	OIDAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	OIDAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	OIDDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+// ErrUnsupportedAlgorithm is returned when a content-encryption algorithm is
+// not one this package implements.
+var ErrUnsupportedAlgorithm = errors.New("cms: unsupported content-encryption algorithm")
+
+// An EncryptedContentInfo is the encrypted content of an EnvelopedData,
+// EncryptedData, or AuthenticatedData structure.
+type EncryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	// EncryptedContent is nil if the content is detached.
+	EncryptedContent []byte `asn1:"optional,tag:0"`
+}
+
+type algorithm struct {
+	oid       asn1.ObjectIdentifier
+	keySize   int
+	newCipher func(key []byte) (cipher.Block, error)
+}
+
+var algorithms = []algorithm{
+	{OIDAES128CBC, 16, aes.NewCipher},
+	{OIDAES192CBC, 24, aes.NewCipher},
+	{OIDAES256CBC, 32, aes.NewCipher},
+	{OIDDESEDE3CBC, 24, des.NewTripleDESCipher},
+}
+
+func lookup(oid asn1.ObjectIdentifier) (algorithm, error) {
+	for _, a := range algorithms {
+		if a.oid.Equal(oid) {
+			return a, nil
+		}
+	}
+	return algorithm{}, fmt.Errorf("%w %v", ErrUnsupportedAlgorithm, oid)
+}
+
+// block returns the cipher for the given key, checking that the key has the
+// algorithm's size.
+func (a algorithm) block(key []byte) (cipher.Block, error) {
+	if len(key) != a.keySize {
+		return nil, fmt.Errorf("cms: key for %v is %d bytes, want %d", a.oid, len(key), a.keySize)
+	}
+	return a.newCipher(key)
+}
+
+// Encrypt pads content with PKCS#7 and encrypts it with the content-encryption
+// algorithm alg and the given key under a random initialization vector. The
+// result records contentType as the type of the content.
+func Encrypt(contentType, alg asn1.ObjectIdentifier, key, content []byte) (*EncryptedContentInfo, error) {
+	a, err := lookup(alg)
+	if err != nil {
+		return nil, err
+	}
+	b, err := a.block(key)
+	if err != nil {
+		return nil, err
+	}
+	bs := b.BlockSize()
+	iv := make([]byte, bs)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	ct := pkcs7pad.AppendPad(nil, content, bs)
+	cipher.NewCBCEncrypter(b, iv).CryptBlocks(ct, ct)
+	return &EncryptedContentInfo{
+		ContentType: contentType,
+		ContentEncryptionAlgorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  alg,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedContent: ct,
+	}, nil
+}
+
+// Decrypt decrypts the encrypted content with the given key and removes its
+// PKCS#7 padding. It returns ErrUnsupportedAlgorithm if the content-encryption
+// algorithm is not supported, and an error wrapping pkcs7pad.ErrBadPadding if
+// the ciphertext or padding is malformed, which is also how a wrong key
+// usually shows up.
+//
+// CMS content encryption is not authenticated, so reporting padding errors to
+// whoever supplied the message creates a padding oracle.
+func (e *EncryptedContentInfo) Decrypt(key []byte) ([]byte, error) {
+	if e.EncryptedContent == nil {
+		return nil, errors.New("cms: encrypted content is detached")
+	}
+	a, err := lookup(e.ContentEncryptionAlgorithm.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	b, err := a.block(key)
+	if err != nil {
+		return nil, err
+	}
+	bs := b.BlockSize()
+	var iv []byte
+	rest, err := asn1.Unmarshal(e.ContentEncryptionAlgorithm.Parameters.FullBytes, &iv)
+	if err != nil || len(rest) != 0 || len(iv) != bs {
+		return nil, fmt.Errorf("cms: invalid initialization vector parameter for %v", a.oid)
+	}
+
+	ct := e.EncryptedContent
+	if len(ct) == 0 || len(ct)%bs != 0 {
+		return nil, fmt.Errorf("%w: encrypted content length %d is not a non-zero multiple of the block size", pkcs7pad.ErrBadPadding, len(ct))
+	}
+	pt := make([]byte, len(ct))
+	cipher.NewCBCDecrypter(b, iv).CryptBlocks(pt, ct)
+	return pkcs7pad.UnpadBlock(pt, bs)
+}
+
+// Marshal returns the DER encoding of e.
+func (e *EncryptedContentInfo) Marshal() ([]byte, error) {
+	return asn1.Marshal(*e)
+}
+
+// Parse parses a DER-encoded EncryptedContentInfo. Trailing data is an error.
+func Parse(der []byte) (*EncryptedContentInfo, error) {
+	var e EncryptedContentInfo
+	rest, err := asn1.Unmarshal(der, &e)
+	if err != nil {
+		return nil, fmt.Errorf("cms: %w", err)
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("cms: trailing data after EncryptedContentInfo")
+	}
+	return &e, nil
+}
@@ -0,0 +1,120 @@
+package cms
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	algs := []struct {
+		oid     asn1.ObjectIdentifier
+		keySize int
+	}{
+		{OIDAES128CBC, 16},
+		{OIDAES192CBC, 24},
+		{OIDAES256CBC, 32},
+		{OIDDESEDE3CBC, 24},
+	}
+	content := []byte("Content-Type: text/plain\r\n\r\nhello")
+	for i, a := range algs {
+		key := bytes.Repeat([]byte{byte(i + 1)}, a.keySize)
+		e, err := Encrypt(OIDData, a.oid, key, content)
+		if err != nil {
+			t.Fatalf("[%d] Encrypt: %v", i, err)
+		}
+		der, err := e.Marshal()
+		if err != nil {
+			t.Fatalf("[%d] Marshal: %v", i, err)
+		}
+		parsed, err := Parse(der)
+		if err != nil {
+			t.Fatalf("[%d] Parse: %v", i, err)
+		}
+		if !parsed.ContentType.Equal(OIDData) || !parsed.ContentEncryptionAlgorithm.Algorithm.Equal(a.oid) {
+			t.Errorf("[%d] parsed %v, %v", i, parsed.ContentType, parsed.ContentEncryptionAlgorithm.Algorithm)
+		}
+		out, err := parsed.Decrypt(key)
+		if err != nil || !bytes.Equal(out, content) {
+			t.Errorf("[%d] Decrypt = %q, %v", i, out, err)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	t.Parallel()
+
+	e := &EncryptedContentInfo{
+		ContentType:      OIDData,
+		EncryptedContent: []byte{0xaa, 0xbb},
+	}
+	e.ContentEncryptionAlgorithm.Algorithm = OIDAES128CBC
+	e.ContentEncryptionAlgorithm.Parameters.FullBytes = []byte{0x04, 0x01, 0x00}
+	der, err := e.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SEQUENCE { OID id-data, SEQUENCE { OID aes128-CBC, OCTET STRING 00 },
+	// [0] IMPLICIT aabb }
+	want := "301f" +
+		"06092a864886f70d010701" +
+		"300e" + "0609608648016503040102" + "040100" +
+		"8002aabb"
+	if got := hex.EncodeToString(der); got != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+
+	if _, err := Parse(append(der, 0)); err == nil {
+		t.Error("expected error parsing trailing data")
+	}
+	if _, err := Parse(der[:len(der)-1]); err == nil {
+		t.Error("expected error parsing truncated data")
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	t.Parallel()
+
+	key := make([]byte, 16)
+	e, err := Encrypt(OIDData, OIDAES128CBC, key, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Decrypt(make([]byte, 32)); err == nil {
+		t.Error("expected error for wrong key size")
+	}
+
+	tampered := *e
+	tampered.EncryptedContent = bytes.Clone(e.EncryptedContent)
+	tampered.EncryptedContent = tampered.EncryptedContent[:15]
+	if _, err := tampered.Decrypt(key); !errors.Is(err, pkcs7pad.ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding for misaligned content, got %v", err)
+	}
+
+	tampered = *e
+	tampered.ContentEncryptionAlgorithm.Parameters.FullBytes = []byte{0x04, 0x01, 0x00}
+	if _, err := tampered.Decrypt(key); err == nil {
+		t.Error("expected error for short IV")
+	}
+
+	tampered = *e
+	tampered.ContentEncryptionAlgorithm.Algorithm = asn1.ObjectIdentifier{1, 2, 3}
+	if _, err := tampered.Decrypt(key); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+	if _, err := Encrypt(OIDData, asn1.ObjectIdentifier{1, 2, 3}, key, nil); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("expected ErrUnsupportedAlgorithm from Encrypt, got %v", err)
+	}
+
+	tampered = *e
+	tampered.EncryptedContent = nil
+	if _, err := tampered.Decrypt(key); err == nil {
+		t.Error("expected error for detached content")
+	}
+}
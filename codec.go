@@ -0,0 +1,47 @@
+package pkcs7pad
+
+import "errors"
+
+// A Codec pads and unpads buffers using a fixed Scheme and block size. Codecs
+// are immutable and safe for concurrent use. The zero Codec is not usable; use
+// NewCodec to create one.
+type Codec struct {
+	scheme Scheme
+	size   int
+}
+
+// NewCodec returns a Codec for the given padding scheme and block size. It
+// returns an error if the scheme is nil, or an error wrapping ErrBlockSize if
+// the block size is not between 1 and 255.
+func NewCodec(s Scheme, size int) (Codec, error) {
+	if s == nil {
+		return Codec{}, errors.New("pkcs7pad: nil padding scheme")
+	}
+	if size < 1 || size > 255 {
+		return Codec{}, errBlockSize(size)
+	}
+	return Codec{scheme: s, size: size}, nil
+}
+
+// Scheme returns the codec's padding scheme.
+func (c Codec) Scheme() Scheme {
+	return c.scheme
+}
+
+// BlockSize returns the codec's block size.
+func (c Codec) BlockSize() int {
+	return c.size
+}
+
+// Pad appends padding to the given buffer such that the resulting slice of
+// bytes has a length divisible by the codec's block size.
+func (c Codec) Pad(buf []byte) []byte {
+	return c.scheme.Pad(buf, c.size)
+}
+
+// Unpad returns a subslice of the input buffer with trailing padding removed,
+// or an error if the padding is malformed or the buffer length is not a
+// multiple of the codec's block size.
+func (c Codec) Unpad(buf []byte) ([]byte, error) {
+	return c.scheme.Unpad(buf, c.size)
+}
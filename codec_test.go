@@ -0,0 +1,49 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewCodec(PKCS7, aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Scheme() != PKCS7 || c.BlockSize() != aes.BlockSize {
+		t.Errorf("got codec %s/%d", c.Scheme().Name(), c.BlockSize())
+	}
+
+	for i, test := range PadTests {
+		buf := make([]byte, len(test.in))
+		copy(buf, test.in)
+		pad := c.Pad(buf)
+		if !bytes.Equal(pad, test.out) {
+			t.Errorf("[%d] %x != %x", i, pad, test.out)
+		}
+		unpad, err := c.Unpad(pad)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+}
+
+func TestNewCodecErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewCodec(nil, aes.BlockSize); err == nil {
+		t.Error("expected error for nil scheme")
+	}
+	for _, size := range []int{-1, 0, 256} {
+		if _, err := NewCodec(PKCS7, size); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("expected error for block size %d", size)
+		}
+	}
+}
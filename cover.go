@@ -0,0 +1,64 @@
+package pkcs7pad
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// coverHeader is the size of the length prefix written by PadCover.
+const coverHeader = 4
+
+// PadCover pads buf to exactly target bytes for applications that need more
+// padding than the 255 bytes PKCS#7 can express, such as hiding the length of
+// a message among much larger ones. The result is the length of buf as a 4-byte
+// big-endian integer, followed by buf, followed by random cover bytes.
+//
+// The cover bytes can't be checked by the receiver, so the padded message must
+// be encrypted and authenticated as a whole, length prefix included: an
+// attacker who can change the prefix can otherwise truncate the message.
+//
+// The random bytes are read from r. If r is nil, crypto/rand.Reader is used. An
+// error is returned if target is too small to hold the prefix and buf, or if
+// reading from r fails.
+func PadCover(buf []byte, target int, r io.Reader) ([]byte, error) {
+	if target < coverHeader+len(buf) {
+		return nil, fmt.Errorf("pkcs7pad: cover target of %d bytes is too small for a message of %d bytes", target, len(buf))
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+	n := len(buf)
+	if cap(buf) < target {
+		grown := make([]byte, n, target)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:target]
+	copy(buf[coverHeader:], buf[:n])
+	binary.BigEndian.PutUint32(buf, uint32(n))
+	if _, err := io.ReadFull(r, buf[coverHeader+n:]); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// UnpadCover returns the message from a buffer produced by PadCover, as a
+// subslice of buf. It returns an error wrapping ErrBadPadding if the length
+// prefix is missing or longer than the rest of buf. The prefix is checked in
+// constant time, but the cover bytes are not checked at all.
+func UnpadCover(buf []byte) ([]byte, error) {
+	if len(buf) < coverHeader {
+		return nil, fmt.Errorf("%w: %d bytes is too short for a length prefix", ErrBadPadding, len(buf))
+	}
+	rest := len(buf) - coverHeader
+	n := binary.BigEndian.Uint32(buf)
+	// Clamp n so that it fits in an int without changing the verdict.
+	n = min(n, uint32(min(rest+1, 1<<31-1)))
+	if subtle.ConstantTimeLessOrEq(int(n), rest) != 1 {
+		return nil, ErrBadPadding
+	}
+	return buf[coverHeader : coverHeader+int(n)], nil
+}
@@ -0,0 +1,73 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPadCover(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []int{4, 5, 300, 4096} {
+		for n := 0; n <= target-coverHeader; n += 1 + n/4 {
+			msg := bytes.Repeat([]byte{0xa5}, n)
+			padded, err := PadCover(bytes.Clone(msg), target, nil)
+			if err != nil {
+				t.Fatalf("[%d/%d] error padding: %v", target, n, err)
+			}
+			if len(padded) != target {
+				t.Fatalf("[%d/%d] padded to %d bytes", target, n, len(padded))
+			}
+			out, err := UnpadCover(padded)
+			if err != nil {
+				t.Fatalf("[%d/%d] error unpadding: %v", target, n, err)
+			}
+			if !bytes.Equal(out, msg) {
+				t.Fatalf("[%d/%d] %x != %x", target, n, out, msg)
+			}
+		}
+	}
+
+	// In place, with enough capacity.
+	buf := append(make([]byte, 0, 64), "YELLOW SUBMARINE"...)
+	padded, err := PadCover(buf, 64, bytes.NewReader(make([]byte, 64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &padded[0] != &buf[:1][0] {
+		t.Error("PadCover reallocated a buffer with enough capacity")
+	}
+	if want := append(append([]byte{0, 0, 0, 16}, "YELLOW SUBMARINE"...), make([]byte, 44)...); !bytes.Equal(padded, want) {
+		t.Errorf("%x != %x", padded, want)
+	}
+}
+
+func TestPadCoverErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := PadCover(make([]byte, 10), 13, nil); err == nil {
+		t.Error("expected an error for a target that is too small")
+	}
+	if _, err := PadCover(nil, 100, iotest.ErrReader(errors.New("boom"))); err == nil {
+		t.Error("expected an error when reading random bytes fails")
+	}
+}
+
+func TestUnpadCoverErrors(t *testing.T) {
+	t.Parallel()
+
+	bad := [][]byte{
+		{},
+		{0, 0, 0},
+		{0, 0, 0, 1},
+		{0, 0, 1, 0, 1, 2, 3},
+		{0xff, 0xff, 0xff, 0xff, 1, 2, 3},
+	}
+	for i, buf := range bad {
+		if _, err := UnpadCover(buf); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// UnpadCT is a branch-free variant of Unpad for callers that need to keep the
+// validity of the padding secret, such as TLS-style record processors that must
+// go on to verify a MAC regardless of whether the padding was good.
+//
+// It returns ok == 1 if the padding is well-formed and ok == 0 otherwise.
+// Following tls1_cbc_remove_padding from OpenSSL, malformed padding is treated
+// as if there were no padding at all: data is always a subslice of buf, and is
+// the entire buffer when ok == 0. Callers should fold ok into their own
+// constant-time checks rather than branching on it.
+func UnpadCT(buf []byte) (data []byte, ok int) {
+	padLen, good := checkPadding(buf)
+	n := subtle.ConstantTimeSelect(good, len(buf)-padLen, len(buf))
+	return buf[:n], good
+}
+
+// UnpadFixed is a length-hiding variant of UnpadCT. It copies buf into dst,
+// zeroing every byte past the end of the unpadded data, and returns the
+// unpadded length n along with ok == 1 if the padding is well-formed and
+// ok == 0 otherwise. As with UnpadCT, malformed padding is treated as if there
+// were no padding at all, so n == len(buf) when ok == 0.
+//
+// UnpadFixed always writes exactly dst[:len(buf)] and never slices by the
+// secret length, so the amount of work depends only on len(buf). It panics if
+// dst is shorter than buf.
+func UnpadFixed(dst, buf []byte) (n int, ok int) {
+	if len(dst) < len(buf) {
+		panic("pkcs7pad: output buffer too small")
+	}
+	padLen, good := checkPadding(buf)
+	n = subtle.ConstantTimeSelect(good, len(buf)-padLen, len(buf))
+	for i, b := range buf {
+		keep := subtle.ConstantTimeLessOrEq(i+1, n)
+		dst[i] = b & byte(-keep)
+	}
+	return n, good
+}
+
+// Validate reports whether buf is correctly padded for the given block size,
+// using the same checks as UnpadBlock. The padding is checked in constant time,
+// and Validate never allocates.
+func Validate(buf []byte, size int) bool {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(buf) == 0 || len(buf)%size != 0 {
+		return false
+	}
+	_, good := checkPadding(buf[len(buf)-size:])
+	return good == 1
+}
@@ -0,0 +1,112 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+	"testing/quick"
+)
+
+func TestUnpadCT(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		data, ok := UnpadCT(test.out)
+		if ok != 1 {
+			t.Errorf("[%d] ok = %d", i, ok)
+		}
+		if !bytes.Equal(data, test.in) {
+			t.Errorf("[%d] %x != %x", i, data, test.in)
+		}
+	}
+	for i, test := range BadPadTests {
+		data, ok := UnpadCT(test)
+		if ok != 0 {
+			t.Errorf("[%d] ok = %d", i, ok)
+		}
+		if !bytes.Equal(data, test) {
+			t.Errorf("[%d] %x != %x", i, data, test)
+		}
+	}
+}
+
+func TestUnpadCTBlackBox(t *testing.T) {
+	t.Parallel()
+
+	f := func(buf []byte) bool {
+		data, ok := UnpadCT(buf)
+		want, err := Unpad(buf)
+		if err != nil {
+			return ok == 0 && bytes.Equal(data, buf)
+		}
+		return ok == 1 && bytes.Equal(data, want)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnpadFixed(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		dst := bytes.Repeat([]byte{0xff}, len(test.out))
+		n, ok := UnpadFixed(dst, test.out)
+		if ok != 1 || n != len(test.in) {
+			t.Errorf("[%d] n, ok = %d, %d", i, n, ok)
+		}
+		want := make([]byte, len(test.out))
+		copy(want, test.in)
+		if !bytes.Equal(dst, want) {
+			t.Errorf("[%d] %x != %x", i, dst, want)
+		}
+	}
+	for i, test := range BadPadTests {
+		dst := make([]byte, len(test))
+		n, ok := UnpadFixed(dst, test)
+		if ok != 0 || n != len(test) {
+			t.Errorf("[%d] n, ok = %d, %d", i, n, ok)
+		}
+		if !bytes.Equal(dst, test) {
+			t.Errorf("[%d] %x != %x", i, dst, test)
+		}
+	}
+}
+
+func TestUnpadFixedShortBuffer(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	UnpadFixed(make([]byte, 15), PadTests[0].out)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		if !Validate(test.out, aes.BlockSize) {
+			t.Errorf("[%d] %x failed validation", i, test.out)
+		}
+	}
+	for i, test := range BadPadTests {
+		if Validate(test, 1) {
+			t.Errorf("[%d] %x passed validation", i, test)
+		}
+	}
+	if Validate(bytes.Repeat([]byte{0x11}, 32), aes.BlockSize) {
+		t.Error("padding longer than a block passed validation")
+	}
+}
+
+func TestValidateAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		Validate(PadTests[3].out, aes.BlockSize)
+	})
+	if allocs != 0 {
+		t.Errorf("Validate allocated %v times", allocs)
+	}
+}
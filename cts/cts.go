@@ -0,0 +1,168 @@
+// Package cts implements cipher block chaining with ciphertext stealing, as
+// defined in the addendum to NIST SP 800-38A.
+//
+// https://csrc.nist.gov/publications/detail/sp/800-38a/addendum/final
+//
+// Ciphertext stealing encrypts a message of any length of at least one block
+// into a ciphertext of exactly the same length, so there is no padding to
+// check and no padding oracle. The three variants differ only in the order of
+// the last two blocks of ciphertext. CS3 is the variant used by Kerberos (RFC
+// 3962).
+//
+// FromPadded and ToPadded convert between ciphertexts produced by
+// pkcs7pad.EncryptCBC and this package, for migrating stored data. Like plain
+// CBC, ciphertext stealing does not authenticate the ciphertext.
+package cts
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// A Variant selects the order of the last two blocks of ciphertext.
+type Variant int
+
+const (
+	// CS1 keeps the last two blocks in CBC order, with the partial block
+	// first.
+	CS1 Variant = iota + 1
+	// CS2 swaps the last two blocks only if the final block is partial, so
+	// aligned messages encrypt exactly as in CBC.
+	CS2
+	// CS3 always swaps the last two blocks.
+	CS3
+)
+
+var errShort = errors.New("cts: input is shorter than one block")
+
+// String returns the name of the variant, such as "CBC-CS1".
+func (v Variant) String() string {
+	switch v {
+	case CS1, CS2, CS3:
+		return fmt.Sprintf("CBC-CS%d", int(v))
+	}
+	return fmt.Sprintf("cts.Variant(%d)", int(v))
+}
+
+// swap reports whether the last two blocks of ciphertext are swapped when the
+// final block holds d of bs bytes.
+func (v Variant) swap(d, bs int) bool {
+	switch v {
+	case CS1:
+		return false
+	case CS2:
+		return d != bs
+	case CS3:
+		return true
+	}
+	panic(fmt.Sprintf("cts: unknown variant %d", int(v)))
+}
+
+// Encrypt encrypts plaintext using the given block cipher, variant, and
+// initialization vector, and returns a ciphertext of the same length. It
+// returns an error if plaintext is shorter than one block. The length of iv
+// must be the same as the Block's block size.
+func Encrypt(b cipher.Block, v Variant, iv, plaintext []byte) ([]byte, error) {
+	bs := b.BlockSize()
+	if len(plaintext) < bs {
+		return nil, errShort
+	}
+	n := (len(plaintext) + bs - 1) / bs
+	d := len(plaintext) - (n-1)*bs
+
+	// Encrypt the zero-padded message in CBC mode, then drop the bytes of
+	// the second-to-last block that decryption can recover from the last.
+	buf := make([]byte, n*bs)
+	copy(buf, plaintext)
+	cipher.NewCBCEncrypter(b, iv).CryptBlocks(buf, buf)
+	if n == 1 {
+		return buf, nil
+	}
+	swap := v.swap(d, bs)
+	out := make([]byte, len(plaintext))
+	off := copy(out, buf[:(n-2)*bs])
+	prev, last := buf[(n-2)*bs:(n-2)*bs+d], buf[(n-1)*bs:]
+	if swap {
+		prev, last = last, prev
+	}
+	off += copy(out[off:], prev)
+	copy(out[off:], last)
+	return out, nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt with the same block cipher,
+// variant, and initialization vector, and returns a plaintext of the same
+// length. It returns an error if ciphertext is shorter than one block.
+func Decrypt(b cipher.Block, v Variant, iv, ciphertext []byte) ([]byte, error) {
+	bs := b.BlockSize()
+	if len(ciphertext) < bs {
+		return nil, errShort
+	}
+	n := (len(ciphertext) + bs - 1) / bs
+	d := len(ciphertext) - (n-1)*bs
+
+	buf := make([]byte, n*bs)
+	copy(buf, ciphertext)
+	if n > 1 {
+		// Rebuild the CBC ciphertext of the zero-padded message. The
+		// stolen bytes of the second-to-last block are the trailing bytes
+		// of the decryption of the last block, since the padding is zero.
+		tail := ciphertext[(n-2)*bs:]
+		prev, last := tail[:d], tail[d:]
+		if v.swap(d, bs) {
+			last, prev = tail[:bs], tail[bs:]
+		}
+		z := buf[(n-1)*bs:]
+		b.Decrypt(z, last)
+		copy(buf[(n-2)*bs:], prev)
+		copy(buf[(n-2)*bs+d:], z[d:])
+		copy(z, last)
+	}
+	cipher.NewCBCDecrypter(b, iv).CryptBlocks(buf, buf)
+	return buf[:len(ciphertext)], nil
+}
+
+// FromPadded converts a ciphertext produced by pkcs7pad.EncryptCBC into one
+// encrypted with ciphertext stealing under a fresh random initialization
+// vector, which is prepended to the result. It returns an error if the padding
+// is malformed, or if the plaintext is shorter than one block, since such
+// messages can't be encrypted with ciphertext stealing.
+//
+// The padding check makes FromPadded a padding oracle, so it should only be
+// run over trusted, stored data.
+func FromPadded(b cipher.Block, v Variant, ciphertext []byte) ([]byte, error) {
+	pt, err := pkcs7pad.DecryptCBC(b, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	bs := b.BlockSize()
+	iv := make([]byte, bs)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	ct, err := Encrypt(b, v, iv, pt)
+	if err != nil {
+		return nil, err
+	}
+	return append(iv, ct...), nil
+}
+
+// ToPadded converts a ciphertext produced by FromPadded back into the format of
+// pkcs7pad.EncryptCBC, under a fresh random initialization vector, for rolling
+// back a migration.
+func ToPadded(b cipher.Block, v Variant, ciphertext []byte) ([]byte, error) {
+	bs := b.BlockSize()
+	if len(ciphertext) < bs {
+		return nil, errShort
+	}
+	pt, err := Decrypt(b, v, ciphertext[:bs], ciphertext[bs:])
+	if err != nil {
+		return nil, err
+	}
+	return pkcs7pad.EncryptCBC(b, pt)
+}
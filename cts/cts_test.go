@@ -0,0 +1,136 @@
+package cts
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Test vectors from RFC 3962, appendix B, which uses CBC-CS3.
+func TestRFC3962(t *testing.T) {
+	t.Parallel()
+
+	b, _ := aes.NewCipher([]byte("chicken teriyaki"))
+	iv := make([]byte, aes.BlockSize)
+	vectors := []struct {
+		pt, ct string
+	}{
+		{"4920776f756c64206c696b652074686520", "c6353568f2bf8cb4d8a580362da7ff7f97"},
+		{"4920776f756c64206c696b65207468652047656e6572616c20476175277320", "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5"},
+		{"4920776f756c64206c696b65207468652047656e6572616c2047617527732043", "39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584"},
+	}
+	for i, v := range vectors {
+		pt, want := mustHex(v.pt), mustHex(v.ct)
+		ct, err := Encrypt(b, CS3, iv, pt)
+		if err != nil || !bytes.Equal(ct, want) {
+			t.Errorf("[%d] Encrypt = %x, %v, want %x", i, ct, err, want)
+		}
+		out, err := Decrypt(b, CS3, iv, want)
+		if err != nil || !bytes.Equal(out, pt) {
+			t.Errorf("[%d] Decrypt = %x, %v, want %x", i, out, err, pt)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	b, _ := aes.NewCipher(make([]byte, 16))
+	iv := bytes.Repeat([]byte{0x42}, aes.BlockSize)
+	msg := make([]byte, 4*aes.BlockSize)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	for n := aes.BlockSize; n <= len(msg); n++ {
+		cbc := make([]byte, n-n%aes.BlockSize)
+		cipher.NewCBCEncrypter(b, iv).CryptBlocks(cbc, msg[:len(cbc)])
+		for _, v := range []Variant{CS1, CS2, CS3} {
+			ct, err := Encrypt(b, v, iv, msg[:n])
+			if err != nil || len(ct) != n {
+				t.Fatalf("[%d] %s: Encrypt = %x, %v", n, v, ct, err)
+			}
+			out, err := Decrypt(b, v, iv, ct)
+			if err != nil || !bytes.Equal(out, msg[:n]) {
+				t.Errorf("[%d] %s: Decrypt = %x, %v", n, v, out, err)
+			}
+			// Aligned messages are plain CBC, except that CS3 swaps
+			// the last two blocks.
+			if n%aes.BlockSize == 0 && (v != CS3 || n == aes.BlockSize) && !bytes.Equal(ct, cbc) {
+				t.Errorf("[%d] %s: %x != CBC %x", n, v, ct, cbc)
+			}
+		}
+	}
+}
+
+func TestShort(t *testing.T) {
+	t.Parallel()
+
+	b, _ := aes.NewCipher(make([]byte, 16))
+	iv := make([]byte, aes.BlockSize)
+	if _, err := Encrypt(b, CS1, iv, make([]byte, 15)); err == nil {
+		t.Error("expected error encrypting 15 bytes")
+	}
+	if _, err := Decrypt(b, CS1, iv, make([]byte, 15)); err == nil {
+		t.Error("expected error decrypting 15 bytes")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	b, _ := aes.NewCipher(make([]byte, 16))
+	msg := []byte("attack at dawn, or perhaps a little after")
+	for n := 0; n <= len(msg); n++ {
+		padded, err := pkcs7pad.EncryptCBC(b, msg[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		stolen, err := FromPadded(b, CS3, padded)
+		if n < aes.BlockSize {
+			if err == nil {
+				t.Errorf("[%d] expected an error for a short message", n)
+			}
+			continue
+		}
+		if err != nil || len(stolen) != aes.BlockSize+n {
+			t.Fatalf("[%d] FromPadded = %x, %v", n, stolen, err)
+		}
+		back, err := ToPadded(b, CS3, stolen)
+		if err != nil {
+			t.Fatalf("[%d] ToPadded: %v", n, err)
+		}
+		out, err := pkcs7pad.DecryptCBC(b, back)
+		if err != nil || !bytes.Equal(out, msg[:n]) {
+			t.Errorf("[%d] round trip = %x, %v", n, out, err)
+		}
+	}
+
+	bad := make([]byte, 2*aes.BlockSize)
+	if _, err := FromPadded(b, CS1, bad); !errors.Is(err, pkcs7pad.ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding, got %v", err)
+	}
+}
+
+func TestVariantString(t *testing.T) {
+	t.Parallel()
+
+	if s := CS2.String(); s != "CBC-CS2" {
+		t.Errorf("CS2.String() = %q", s)
+	}
+	if s := Variant(0).String(); s != "cts.Variant(0)" {
+		t.Errorf("Variant(0).String() = %q", s)
+	}
+}
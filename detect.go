@@ -0,0 +1,32 @@
+package pkcs7pad
+
+import "fmt"
+
+// DetectScheme returns the names of the registered padding schemes that could
+// have produced the final block of buf, in the order returned by Schemes, for
+// data migrations and forensics where the original scheme is unknown. A
+// scheme is consistent with buf if its Unpad accepts it for the given block
+// size.
+//
+// Some schemes are consistent with almost anything: "none" accepts every
+// aligned buffer, "zero" and "iso9797-m1" accept every non-empty one, and
+// "iso10126" only checks the final byte.
+// DetectScheme narrows down the possibilities, and more than one block of data
+// is usually needed to decide between them. It is not constant time, and must
+// not be used on attacker-controlled data.
+func DetectScheme(buf []byte, size int) []string {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	var names []string
+	for _, name := range Schemes() {
+		s, err := Lookup(name)
+		if err != nil {
+			continue
+		}
+		if _, err := s.Unpad(buf, size); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
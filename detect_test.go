@@ -0,0 +1,32 @@
+package pkcs7pad
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDetectScheme(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		buf  []byte
+		want []string
+	}{
+		{[]byte("YELLOW\x02\x02"), []string{"iso10126", "iso9797-m1", "none", "pkcs7", "zero"}},
+		{[]byte("YELLOW\x00\x02"), []string{"iso10126", "iso9797-m1", "none", "x923", "zero"}},
+		{[]byte("YELLOW\x80\x00"), []string{"iso7816", "iso9797-m1", "iso9797-m2", "none", "zero"}},
+		{[]byte("YELLOV\xff\xff"), []string{"iso9797-m1", "none", "tbc", "zero"}},
+		{[]byte("YELLOWS\x09"), []string{"iso9797-m1", "none", "zero"}},
+	}
+	for i, test := range tests {
+		got := DetectScheme(test.buf, 8)
+		// Other tests may register their own schemes.
+		got = slices.DeleteFunc(got, func(name string) bool { return name == "test" })
+		if !slices.Equal(got, test.want) {
+			t.Errorf("[%d] DetectScheme(%q) = %q, want %q", i, test.buf, got, test.want)
+		}
+	}
+	if got := DetectScheme([]byte("YELLOW"), 8); len(got) != 0 {
+		t.Errorf("misaligned buffer is consistent with %q", got)
+	}
+}
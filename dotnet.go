@@ -0,0 +1,89 @@
+package pkcs7pad
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A DotNetPaddingMode is a value of .NET's System.Security.Cryptography
+// PaddingMode enumeration, for configuring Go and .NET programs that exchange
+// ciphertexts identically. The constants have the same numeric values as in
+// .NET, and the text form is the .NET member name, e.g. "PKCS7".
+type DotNetPaddingMode int
+
+// The members of .NET's PaddingMode enumeration.
+const (
+	DotNetNone     DotNetPaddingMode = 1
+	DotNetPKCS7    DotNetPaddingMode = 2
+	DotNetZeros    DotNetPaddingMode = 3
+	DotNetANSIX923 DotNetPaddingMode = 4
+	DotNetISO10126 DotNetPaddingMode = 5
+)
+
+var dotNetModes = []struct {
+	mode   DotNetPaddingMode
+	name   string
+	scheme Scheme
+}{
+	{DotNetNone, "None", None},
+	{DotNetPKCS7, "PKCS7", PKCS7},
+	{DotNetZeros, "Zeros", Zero},
+	{DotNetANSIX923, "ANSIX923", X923},
+	{DotNetISO10126, "ISO10126", ISO10126},
+}
+
+// Scheme returns the padding scheme equivalent to m. Each scheme pads exactly
+// as .NET does. When unpadding, one difference remains: .NET leaves zero
+// padding in place, while Zero's Unpad removes every trailing zero byte. An
+// error is returned if m is not a member of the enumeration.
+func (m DotNetPaddingMode) Scheme() (Scheme, error) {
+	for _, d := range dotNetModes {
+		if d.mode == m {
+			return d.scheme, nil
+		}
+	}
+	return nil, fmt.Errorf("pkcs7pad: unknown .NET PaddingMode %d", int(m))
+}
+
+// DotNetPaddingModeOf returns the .NET PaddingMode equivalent to s. It returns
+// an error if .NET has no equivalent of s.
+func DotNetPaddingModeOf(s Scheme) (DotNetPaddingMode, error) {
+	for _, d := range dotNetModes {
+		if d.scheme == s {
+			return d.mode, nil
+		}
+	}
+	return 0, fmt.Errorf("pkcs7pad: no .NET PaddingMode for scheme %q", s.Name())
+}
+
+// String returns the .NET name of m, or its number if it is not a member of
+// the enumeration.
+func (m DotNetPaddingMode) String() string {
+	for _, d := range dotNetModes {
+		if d.mode == m {
+			return d.name
+		}
+	}
+	return fmt.Sprintf("DotNetPaddingMode(%d)", int(m))
+}
+
+// MarshalText implements encoding.TextMarshaler. An error is returned if m is
+// not a member of the enumeration.
+func (m DotNetPaddingMode) MarshalText() ([]byte, error) {
+	if _, err := m.Scheme(); err != nil {
+		return nil, err
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the .NET names
+// of the members of the enumeration, ignoring case as .NET's Enum.Parse can.
+func (m *DotNetPaddingMode) UnmarshalText(text []byte) error {
+	for _, d := range dotNetModes {
+		if strings.EqualFold(string(text), d.name) {
+			*m = d.mode
+			return nil
+		}
+	}
+	return fmt.Errorf("pkcs7pad: unknown .NET PaddingMode %q", text)
+}
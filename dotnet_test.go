@@ -0,0 +1,90 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// dotNetVectors are the plaintexts produced by decrypting with
+// PaddingMode.None in .NET what was encrypted with each mode, for the input
+// "YELLOW".
+var dotNetVectors = []struct {
+	mode DotNetPaddingMode
+	out  []byte
+}{
+	{DotNetPKCS7, []byte("YELLOW\x0a\x0a\x0a\x0a\x0a\x0a\x0a\x0a\x0a\x0a")},
+	{DotNetZeros, []byte("YELLOW\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")},
+	{DotNetANSIX923, []byte("YELLOW\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0a")},
+}
+
+func TestDotNetPaddingMode(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range dotNetVectors {
+		s, err := test.mode.Scheme()
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+		if got := s.Pad([]byte("YELLOW"), 16); !bytes.Equal(got, test.out) {
+			t.Errorf("[%d] %v: %x != %x", i, test.mode, got, test.out)
+		}
+		if got, err := s.Unpad(test.out, 16); err != nil || string(got) != "YELLOW" {
+			t.Errorf("[%d] %v: Unpad = %q, %v", i, test.mode, got, err)
+		}
+	}
+
+	s, _ := DotNetISO10126.Scheme()
+	if got := s.Pad([]byte("YELLOW"), 16); len(got) != 16 || got[15] != 10 {
+		t.Errorf("ISO10126: %x", got)
+	}
+	s, _ = DotNetNone.Scheme()
+	if got := s.Pad([]byte("YELLOW SUBMARINE"), 16); string(got) != "YELLOW SUBMARINE" {
+		t.Errorf("None: %x", got)
+	}
+	if _, err := DotNetPaddingMode(0).Scheme(); err == nil {
+		t.Error("expected an error for PaddingMode 0")
+	}
+}
+
+func TestDotNetPaddingModeOf(t *testing.T) {
+	t.Parallel()
+
+	for m := DotNetNone; m <= DotNetISO10126; m++ {
+		s, err := m.Scheme()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := DotNetPaddingModeOf(s); err != nil || got != m {
+			t.Errorf("[%v] DotNetPaddingModeOf(%s) = %v, %v", m, s.Name(), got, err)
+		}
+	}
+	if _, err := DotNetPaddingModeOf(ISO7816); err == nil {
+		t.Error("expected an error for ISO/IEC 7816-4")
+	}
+}
+
+func TestDotNetPaddingModeText(t *testing.T) {
+	t.Parallel()
+
+	var config struct{ Padding DotNetPaddingMode }
+	if err := json.Unmarshal([]byte(`{"Padding": "ansix923"}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Padding != DotNetANSIX923 {
+		t.Errorf("got %v", config.Padding)
+	}
+	out, err := json.Marshal(config)
+	if err != nil || string(out) != `{"Padding":"ANSIX923"}` {
+		t.Errorf("Marshal = %s, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Padding": "PKCS5"}`), &config); err == nil {
+		t.Error("expected an error for an unknown name")
+	}
+	if _, err := DotNetPaddingMode(9).MarshalText(); err == nil {
+		t.Error("expected an error marshaling an unknown mode")
+	}
+	if s := DotNetPaddingMode(9).String(); s != "DotNetPaddingMode(9)" {
+		t.Errorf("String() = %q", s)
+	}
+}
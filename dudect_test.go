@@ -0,0 +1,182 @@
+package pkcs7pad
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// The test in this file checks that padding validation runs in constant time,
+// using the
+// statistical approach of dudect (Reparaz, Balasch, and Verbauwhede, "Dude, is
+// my code constant time?", https://eprint.iacr.org/2016/1123). Inputs are
+// split into two classes, one with valid padding and one with padding that is
+// malformed in various ways. Each measurement times the validation of an input
+// from a randomly chosen class, and Welch's t-test decides whether the two
+// timing distributions differ.
+//
+// The functions measured are the constant-time cores of the Unpad functions.
+// The Unpad functions themselves necessarily take different paths depending
+// on the result, which tells the caller no more than the returned error does.
+//
+// Timing tests are slow and sensitive to a noisy machine, so the test only
+// runs when PKCS7PAD_DUDECT is set:
+//
+//	PKCS7PAD_DUDECT=1 go test -run TestDudect -v
+
+const (
+	dudectMeasurements = 500000
+	dudectBatch        = 8 // calls per measurement, to rise above timer resolution
+
+	// dudectThreshold is the |t| above which the distributions are considered
+	// different. dudect treats values above 10 as a definite leak; 4.5 is a
+	// common threshold for suspicion, but is prone to false positives.
+	dudectThreshold = 10
+)
+
+func TestDudect(t *testing.T) {
+	if os.Getenv("PKCS7PAD_DUDECT") == "" {
+		t.Skip("set PKCS7PAD_DUDECT=1 to run the timing test")
+	}
+
+	tests := []struct {
+		name  string
+		size  int
+		check func([]byte) int
+	}{
+		{"checkPadding", 16, func(buf []byte) int {
+			_, good := checkPadding(buf)
+			return good
+		}},
+		{"checkPaddingGeneric", 16, func(buf []byte) int {
+			return checkPaddingGeneric(buf, buf[len(buf)-1])
+		}},
+		{"checkPadding16", 16, func(buf []byte) int {
+			_, good := checkPadding16((*[16]byte)(buf[len(buf)-16:]))
+			return good
+		}},
+		{"checkPadding8", 8, func(buf []byte) int {
+			_, good := checkPadding8((*[8]byte)(buf[len(buf)-8:]))
+			return good
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmax := dudect(test.size, test.check)
+			t.Logf("max |t| = %.2f", tmax)
+			if tmax > dudectThreshold {
+				t.Errorf("timing depends on padding validity: |t| = %.2f > %d", tmax, dudectThreshold)
+			}
+		})
+	}
+
+	// Make sure the harness can detect a leak at all.
+	tmax := dudect(16, func(buf []byte) int {
+		_, err := completelyUnsafeNotConstantTimeUnpad(buf)
+		if err != nil {
+			return 0
+		}
+		return 1
+	})
+	t.Logf("variable-time reference: max |t| = %.2f", tmax)
+	if tmax <= dudectThreshold {
+		t.Errorf("failed to detect the variable-time reference: |t| = %.2f", tmax)
+	}
+}
+
+var dudectSink int
+
+// dudect runs the measurements for check, which validates padding for the
+// given block size, and returns the largest |t| statistic
+// over a range of percentile crops.
+func dudect(size int, check func([]byte) int) float64 {
+	rng := rand.New(rand.NewSource(1))
+	const inputs = 1024
+	var classes [2][][]byte
+	for i := 0; i < inputs; i++ {
+		// Class 0 has valid padding of random length.
+		p := 1 + rng.Intn(size)
+		valid := make([]byte, 64)
+		rng.Read(valid)
+		for j := len(valid) - p; j < len(valid); j++ {
+			valid[j] = byte(p)
+		}
+		classes[0] = append(classes[0], valid)
+
+		// Class 1 has padding with a single wrong byte, or a random final
+		// block.
+		invalid := make([]byte, 64)
+		copy(invalid, valid)
+		if i%2 == 0 {
+			invalid[len(invalid)-1-rng.Intn(p)] ^= byte(1 + rng.Intn(255))
+		} else {
+			rng.Read(invalid[len(invalid)-size:])
+		}
+		if _, err := UnpadBlock(invalid, size); err == nil {
+			invalid[len(invalid)-1] = 0
+		}
+		classes[1] = append(classes[1], invalid)
+	}
+
+	class := make([]uint8, dudectMeasurements)
+	times := make([]float64, dudectMeasurements)
+	for i := range class {
+		c := uint8(rng.Intn(2))
+		in := classes[c][rng.Intn(inputs)]
+		start := time.Now()
+		for j := 0; j < dudectBatch; j++ {
+			dudectSink += check(in)
+		}
+		times[i] = float64(time.Since(start))
+		class[i] = c
+	}
+
+	// Like dudect, test the uncropped measurements and measurements cropped
+	// at a range of percentiles, since timing noise is heavily right-skewed.
+	sorted := slices.Clone(times)
+	slices.Sort(sorted)
+	tmax := 0.0
+	for _, pct := range []float64{1, 0.99, 0.95, 0.9, 0.75, 0.5} {
+		cutoff := sorted[int(pct*float64(len(sorted)-1))]
+		var w [2]welford
+		for i, d := range times {
+			if d <= cutoff {
+				w[class[i]].add(d)
+			}
+		}
+		tmax = max(tmax, math.Abs(welchT(w[0], w[1])))
+	}
+	return tmax
+}
+
+// welford accumulates the mean and variance of a sample.
+type welford struct {
+	n, mean, m2 float64
+}
+
+func (w *welford) add(x float64) {
+	w.n++
+	d := x - w.mean
+	w.mean += d / w.n
+	w.m2 += d * (x - w.mean)
+}
+
+func (w welford) variance() float64 {
+	if w.n < 2 {
+		return 0
+	}
+	return w.m2 / (w.n - 1)
+}
+
+// welchT returns Welch's t statistic for the difference between the means of
+// two samples.
+func welchT(a, b welford) float64 {
+	se := math.Sqrt(a.variance()/a.n + b.variance()/b.n)
+	if se == 0 {
+		return 0
+	}
+	return (a.mean - b.mean) / se
+}
@@ -0,0 +1,69 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"slices"
+)
+
+// espTrailer is the size of the Pad Length and Next Header fields.
+const espTrailer = 2
+
+// espAlign returns the multiple an ESP payload must be aligned to: the
+// cipher's block size, or 4, whichever is larger.
+func espAlign(size int) int {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return max(size, 4)
+}
+
+// PadESP appends IPsec ESP padding and the ESP trailer to buf, as defined in
+// RFC 4303, section 2.4: the padding bytes 1, 2, 3, ..., followed by a Pad
+// Length byte and the given Next Header byte, so that the result is a multiple
+// of the cipher's block size or of 4, whichever is larger. The least padding
+// satisfying these rules is used.
+//
+// https://tools.ietf.org/html/rfc4303#section-2.4
+func PadESP(buf []byte, nextHeader byte, size int) []byte {
+	align := espAlign(size)
+	p := (align - (len(buf)+espTrailer)%align) % align
+	buf = slices.Grow(buf, p+espTrailer)
+	for i := 1; i <= p; i++ {
+		buf = append(buf, byte(i))
+	}
+	return append(buf, byte(p), nextHeader)
+}
+
+// UnpadESP removes the padding and trailer added by PadESP, returning the
+// payload as a subslice of buf along with the Next Header byte. It returns an
+// error wrapping ErrBadPadding if the length of buf is not aligned as described
+// by PadESP, or if the padding is not the sequence 1, 2, 3, ... of the length
+// given by the Pad Length byte. The padding is checked in constant time.
+func UnpadESP(buf []byte, size int) ([]byte, byte, error) {
+	align := espAlign(size)
+	if len(buf)%align != 0 {
+		return nil, 0, errMisaligned(int64(len(buf)), align)
+	}
+	if len(buf) < espTrailer {
+		return nil, 0, ErrBadPadding
+	}
+
+	nextHeader := buf[len(buf)-1]
+	padLen := int(buf[len(buf)-2])
+	body := buf[:len(buf)-espTrailer]
+	toCheck := min(255, len(body))
+
+	var diff byte
+	for j := 0; j < toCheck; j++ {
+		// The byte j from the end of the padding should be padLen-j.
+		inPad := byte(subtle.ConstantTimeLessOrEq(j+1, padLen))
+		diff |= (body[len(body)-1-j] ^ byte(padLen-j)) & -inPad
+	}
+	good := subtle.ConstantTimeByteEq(diff, 0)
+	good &= subtle.ConstantTimeLessOrEq(padLen, len(body))
+	if good != 1 {
+		return nil, 0, ErrBadPadding
+	}
+	return body[:len(body)-padLen], nextHeader, nil
+}
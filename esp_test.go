@@ -0,0 +1,59 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPadESP(t *testing.T) {
+	t.Parallel()
+
+	// RFC 4303 padding for a 5-byte payload with a 16-byte block cipher.
+	want := []byte{'h', 'e', 'l', 'l', 'o', 1, 2, 3, 4, 5, 6, 7, 8, 9, 9, 4}
+	if got := PadESP([]byte("hello"), 4, 16); !bytes.Equal(got, want) {
+		t.Errorf("%x != %x", got, want)
+	}
+
+	for _, size := range []int{1, 4, 8, 16, 255} {
+		align := max(size, 4)
+		for n := 0; n < 2*align; n++ {
+			payload := bytes.Repeat([]byte{byte(n)}, n)
+			padded := PadESP(bytes.Clone(payload), 41, size)
+			if len(padded)%align != 0 || len(padded)-n-2 >= align {
+				t.Fatalf("[%d/%d] padded to %d bytes", size, n, len(padded))
+			}
+			out, next, err := UnpadESP(padded, size)
+			if err != nil {
+				t.Fatalf("[%d/%d] error unpadding: %v", size, n, err)
+			}
+			if !bytes.Equal(out, payload) || next != 41 {
+				t.Fatalf("[%d/%d] got %x and next header %d", size, n, out, next)
+			}
+		}
+	}
+}
+
+func TestUnpadESPErrors(t *testing.T) {
+	t.Parallel()
+
+	good := PadESP([]byte("hello"), 4, 16)
+	with := func(f func([]byte)) []byte {
+		b := bytes.Clone(good)
+		f(b)
+		return b
+	}
+	bad := [][]byte{
+		good[:15],
+		with(func(b []byte) { b[5] = 0 }),
+		with(func(b []byte) { b[13] = 10 }),
+		with(func(b []byte) { b[14] = 10 }),
+		with(func(b []byte) { b[14] = 15 }),
+		with(func(b []byte) { b[14] = 255 }),
+	}
+	for i, buf := range bad {
+		if _, _, err := UnpadESP(buf, 16); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
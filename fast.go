@@ -0,0 +1,24 @@
+package pkcs7pad
+
+// UnpadFast is like Unpad, but it is NOT constant time: it stops at the first
+// malformed padding byte, and its running time depends on the padding length.
+// It is only suitable for data that is not secret, such as when PKCS#7 padding
+// is used as a framing convention for fixed-size records in storage.
+//
+// Never use UnpadFast on decrypted ciphertext. Doing so can expose a padding
+// oracle, which allows an attacker to decrypt messages.
+func UnpadFast(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+	padLen := buf[len(buf)-1]
+	if padLen == 0 || int(padLen) > len(buf) {
+		return nil, ErrBadPadding
+	}
+	for _, b := range buf[len(buf)-int(padLen):] {
+		if b != padLen {
+			return nil, ErrBadPadding
+		}
+	}
+	return buf[:len(buf)-int(padLen)], nil
+}
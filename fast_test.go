@@ -0,0 +1,37 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+	"testing/quick"
+)
+
+func TestUnpadFast(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		unpad, err := UnpadFast(test.out)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+	for i, test := range BadPadTests {
+		if _, err := UnpadFast(test); err != ErrBadPadding {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+	if err := quick.CheckEqual(UnpadFast, Unpad, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkUnpadFast(b *testing.B) {
+	buf := Pad(bytes.Repeat(testString, 4), aes.BlockSize)
+	for i := 0; i < b.N; i++ {
+		UnpadFast(buf)
+	}
+}
@@ -0,0 +1,95 @@
+package pkcs7pad
+
+import (
+	"fmt"
+	"os"
+)
+
+// A FileOption configures the behavior of PadFile and UnpadFile.
+type FileOption func(*fileConfig)
+
+type fileConfig struct {
+	sync bool
+}
+
+// WithSync commits the file to stable storage with f.Sync after it has been
+// modified, so that the change survives a crash.
+func WithSync() FileOption {
+	return func(c *fileConfig) {
+		c.sync = true
+	}
+}
+
+func (c *fileConfig) finish(f *os.File) error {
+	if c.sync {
+		return f.Sync()
+	}
+	return nil
+}
+
+// PadFile appends PKCS#7 padding to the file f in place, without reading it.
+// The padding is computed from the size of the file and written at its end
+// with f.WriteAt, so f must be open for writing and not in append mode. Like
+// Pad, PadFile panics if size is not between 1 and 255.
+func PadFile(f *os.File, size int, opts ...FileOption) error {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	var c fileConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	n := fi.Size()
+	i := PadLen(int(n%int64(size)), size)
+	padding := make([]byte, i)
+	for j := range padding {
+		padding[j] = byte(i)
+	}
+	if _, err := f.WriteAt(padding, n); err != nil {
+		return err
+	}
+	return c.finish(f)
+}
+
+// UnpadFile removes the PKCS#7 padding from the file f in place, and returns
+// the new size of the file. Only the final block is read, and the padding is
+// removed with f.Truncate, so it is much faster than rewriting a large file.
+// Like UnpadBlock, it returns an error wrapping ErrBadPadding if the size of
+// the file is not a non-zero multiple of size or if the padding is malformed,
+// in which case the file is left unchanged.
+func UnpadFile(f *os.File, size int, opts ...FileOption) (int64, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	var c fileConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	n := fi.Size()
+	if n == 0 || n%int64(size) != 0 {
+		return 0, errMisaligned(n, size)
+	}
+
+	var buf [255]byte
+	block := buf[:size]
+	if _, err := f.ReadAt(block, n-int64(size)); err != nil {
+		return 0, err
+	}
+	data, err := unpadBlock(block, size)
+	if err != nil {
+		return 0, err
+	}
+	n -= int64(size - len(data))
+	if err := f.Truncate(n); err != nil {
+		return 0, err
+	}
+	return n, c.finish(f)
+}
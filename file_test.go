@@ -0,0 +1,94 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tempFile(t *testing.T, data []byte) *os.File {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func readFile(t *testing.T, f *os.File) []byte {
+	t.Helper()
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestPadUnpadFile(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{0, 1, 7, 8, 9, 100} {
+		msg := bytes.Repeat([]byte{0x5a}, n)
+		f := tempFile(t, msg)
+		if err := PadFile(f, 8, WithSync()); err != nil {
+			t.Fatalf("[%d] PadFile: %v", n, err)
+		}
+		if got, want := readFile(t, f), Pad(bytes.Clone(msg), 8); !bytes.Equal(got, want) {
+			t.Errorf("[%d] padded file %x, want %x", n, got, want)
+		}
+		size, err := UnpadFile(f, 8, WithSync())
+		if err != nil || size != int64(n) {
+			t.Errorf("[%d] UnpadFile = %d, %v", n, size, err)
+		}
+		if got := readFile(t, f); !bytes.Equal(got, msg) {
+			t.Errorf("[%d] unpadded file %x, want %x", n, got, msg)
+		}
+	}
+}
+
+func TestUnpadFileErrors(t *testing.T) {
+	t.Parallel()
+
+	for i, data := range [][]byte{
+		nil,
+		[]byte("hello\x03\x03"),
+		[]byte("hello\x03\x02\x03"),
+	} {
+		f := tempFile(t, data)
+		if _, err := UnpadFile(f, 8); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+		if got := readFile(t, f); !bytes.Equal(got, data) {
+			t.Errorf("[%d] file was modified: %x", i, got)
+		}
+	}
+
+	f := tempFile(t, nil)
+	ro, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ro.Close()
+	if err := PadFile(ro, 8); err == nil {
+		t.Error("expected error padding a read-only file")
+	}
+}
+
+func TestPadFilePanics(t *testing.T) {
+	t.Parallel()
+
+	f := tempFile(t, []byte("hello"))
+	defer func() {
+		if r := recover(); r != "pkcs7pad: inappropriate block size 0" {
+			t.Errorf("unexpected panic %v", r)
+		}
+	}()
+	PadFile(f, 0)
+}
@@ -0,0 +1,69 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+// The functions in this file specialize padding and unpadding to the block
+// sizes of AES (16) and DES (8), which cover nearly every real use of PKCS#7.
+// Each block is handled as one or two words, without loops or branches.
+
+// pad16 fills the last n bytes of the final block b with PKCS#7 padding,
+// leaving the rest of the block untouched.
+func pad16(b *[16]byte, n int) {
+	pattern := uint64(n) * 0x0101010101010101
+	hiMask := paddingMask(n, 0)
+	loMask := paddingMask(n, 8)
+	hi := binary.LittleEndian.Uint64(b[8:])
+	lo := binary.LittleEndian.Uint64(b[:8])
+	binary.LittleEndian.PutUint64(b[8:], hi&^hiMask|pattern&hiMask)
+	binary.LittleEndian.PutUint64(b[:8], lo&^loMask|pattern&loMask)
+}
+
+// pad8 is like pad16, but for an 8-byte block.
+func pad8(b *[8]byte, n int) {
+	pattern := uint64(n) * 0x0101010101010101
+	mask := paddingMask(n, 0)
+	w := binary.LittleEndian.Uint64(b[:])
+	binary.LittleEndian.PutUint64(b[:], w&^mask|pattern&mask)
+}
+
+// checkPadding16 is checkPadding specialized to a single 16-byte block.
+func checkPadding16(b *[16]byte) (int, int) {
+	padLen := int(b[15])
+	pattern := uint64(padLen) * 0x0101010101010101
+	hi := binary.LittleEndian.Uint64(b[8:]) ^ pattern
+	lo := binary.LittleEndian.Uint64(b[:8]) ^ pattern
+	diff := hi&paddingMask(padLen, 0) | lo&paddingMask(padLen, 8)
+
+	good := int((diff|-diff)>>63) ^ 1
+	good &= subtle.ConstantTimeLessOrEq(1, padLen)
+	good &= subtle.ConstantTimeLessOrEq(padLen, 16)
+	return padLen, good
+}
+
+// checkPadding8 is checkPadding specialized to a single 8-byte block.
+func checkPadding8(b *[8]byte) (int, int) {
+	padLen := int(b[7])
+	pattern := uint64(padLen) * 0x0101010101010101
+	diff := (binary.LittleEndian.Uint64(b[:]) ^ pattern) & paddingMask(padLen, 0)
+
+	good := int((diff|-diff)>>63) ^ 1
+	good &= subtle.ConstantTimeLessOrEq(1, padLen)
+	good &= subtle.ConstantTimeLessOrEq(padLen, 8)
+	return padLen, good
+}
+
+// PadFinalBlock writes the final 16-byte block of the PKCS#7 padding of tail to
+// dst, and returns the number of padding bytes written. Only the last
+// len(tail)%16 bytes of tail are used, so tail may be the entire message: the
+// padded message is tail[:len(tail)-len(tail)%16] followed by dst.
+//
+// PadFinalBlock never allocates, so it is suitable for the hot path of
+// encryptors using 16-byte block ciphers such as AES.
+func PadFinalBlock(dst *[16]byte, tail []byte) int {
+	n := copy(dst[:], tail[len(tail)-len(tail)%16:])
+	pad16(dst, 16-n)
+	return 16 - n
+}
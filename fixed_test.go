@@ -0,0 +1,113 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+	"testing/quick"
+)
+
+func TestPadFixed(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{8, 16} {
+		for n := 1; n <= size; n++ {
+			block := bytes.Repeat([]byte{0xaa}, size)
+			for j := size - n; j < size; j++ {
+				block[j] = 0x55
+			}
+			want := bytes.Clone(block)
+			for j := size - n; j < size; j++ {
+				want[j] = byte(n)
+			}
+			if size == 16 {
+				pad16((*[16]byte)(block), n)
+			} else {
+				pad8((*[8]byte)(block), n)
+			}
+			if !bytes.Equal(block, want) {
+				t.Errorf("[%d/%d] %x != %x", size, n, block, want)
+			}
+		}
+	}
+}
+
+func TestCheckPaddingFixed(t *testing.T) {
+	t.Parallel()
+
+	check := func(block []byte) bool {
+		var padLen, good int
+		if len(block) == 16 {
+			padLen, good = checkPadding16((*[16]byte)(block))
+		} else {
+			padLen, good = checkPadding8((*[8]byte)(block))
+		}
+		wantLen, wantGood := checkPadding(block)
+		if wantLen > len(block) {
+			wantGood = 0
+		}
+		return padLen == wantLen && good == wantGood
+	}
+	for _, size := range []int{8, 16} {
+		for p := 0; p < 256; p++ {
+			block := bytes.Repeat([]byte{byte(p)}, size)
+			if !check(block) {
+				t.Errorf("[%d/%d] disagreement", size, p)
+			}
+			for i := 0; i < size; i++ {
+				block[i] ^= 0x01
+				if !check(block) {
+					t.Errorf("[%d/%d] disagreement with byte %d corrupted", size, p, i)
+				}
+				block[i] ^= 0x01
+			}
+		}
+	}
+	f16 := func(b [16]byte) bool { return check(b[:]) }
+	f8 := func(b [8]byte) bool { return check(b[:]) }
+	if err := quick.Check(f16, nil); err != nil {
+		t.Error(err)
+	}
+	if err := quick.Check(f8, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkUnpadBlock(b *testing.B) {
+	buf := Pad(bytes.Repeat(testString, 4), aes.BlockSize)
+	for i := 0; i < b.N; i++ {
+		UnpadBlock(buf, aes.BlockSize)
+	}
+}
+
+func TestPadFinalBlock(t *testing.T) {
+	t.Parallel()
+
+	for n := 0; n <= 40; n++ {
+		msg := bytes.Repeat(testString, 3)[:n]
+		want := Pad(bytes.Clone(msg), aes.BlockSize)
+
+		var dst [16]byte
+		for i := range dst {
+			dst[i] = 0xaa
+		}
+		padLen := PadFinalBlock(&dst, msg)
+		if padLen != len(want)-n {
+			t.Errorf("[%d] padLen %d != %d", n, padLen, len(want)-n)
+		}
+		got := append(bytes.Clone(msg[:n-n%16]), dst[:]...)
+		if !bytes.Equal(got, want) {
+			t.Errorf("[%d] %x != %x", n, got, want)
+		}
+	}
+}
+
+func TestPadFinalBlockAllocs(t *testing.T) {
+	var dst [16]byte
+	allocs := testing.AllocsPerRun(100, func() {
+		PadFinalBlock(&dst, testString[:7])
+	})
+	if allocs != 0 {
+		t.Errorf("PadFinalBlock allocated %v times", allocs)
+	}
+}
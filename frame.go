@@ -0,0 +1,94 @@
+package pkcs7pad
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MaxFrameLen is the largest padded payload a FrameDecoder accepts, so that a
+// corrupt length prefix can't make it allocate without bound.
+const MaxFrameLen = 16 << 20
+
+var errFrameLen = errors.New("pkcs7pad: frame length prefix is malformed or too large")
+
+// AppendFrame appends msg to dst as a frame: the length of the padded payload
+// as a uvarint (see encoding/binary), followed by msg with PKCS#7 padding for
+// the given block size. Frames can be concatenated on a byte stream and read
+// back one at a time with a FrameDecoder. Like Pad, AppendFrame panics if size
+// is not between 1 and 255.
+func AppendFrame(dst, msg []byte, size int) []byte {
+	dst = binary.AppendUvarint(dst, uint64(PaddedLen(len(msg), size)))
+	return AppendPad(dst, msg, size)
+}
+
+// A FrameEncoder writes frames, as described by AppendFrame, to a stream.
+type FrameEncoder struct {
+	w    io.Writer
+	size int
+	buf  []byte
+}
+
+// NewFrameEncoder returns a FrameEncoder that writes to w with the given block
+// size.
+func NewFrameEncoder(w io.Writer, size int) *FrameEncoder {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return &FrameEncoder{w: w, size: size}
+}
+
+// Encode writes msg to the stream as a single frame, with a single call to the
+// underlying writer.
+func (e *FrameEncoder) Encode(msg []byte) error {
+	e.buf = AppendFrame(e.buf[:0], msg, e.size)
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+// A FrameDecoder reads frames, as described by AppendFrame, from a stream.
+type FrameDecoder struct {
+	r    *bufio.Reader
+	size int
+}
+
+// NewFrameDecoder returns a FrameDecoder that reads from r with the given block
+// size. The FrameDecoder buffers its input, so it may read past the end of the
+// last frame.
+func NewFrameDecoder(r io.Reader, size int) *FrameDecoder {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return &FrameDecoder{r: bufio.NewReader(r), size: size}
+}
+
+// Decode reads the next frame and returns its unpadded payload. It returns
+// io.EOF if the stream ends cleanly between frames, io.ErrUnexpectedEOF if it
+// ends inside one, and an error wrapping ErrBadPadding if the payload is not a
+// non-zero multiple of the block size or its padding is malformed. The
+// padding is checked in constant time.
+func (d *FrameDecoder) Decode() ([]byte, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		return nil, errFrameLen
+	}
+	if n > MaxFrameLen {
+		return nil, errFrameLen
+	}
+	if n%uint64(d.size) != 0 {
+		return nil, errMisaligned(int64(n), d.size)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return unpadBlock(buf, d.size)
+}
@@ -0,0 +1,68 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFrames(t *testing.T) {
+	t.Parallel()
+
+	msgs := []string{"", "hello", "exactly sixteen!", string(bytes.Repeat([]byte{0xff}, 300))}
+	var buf bytes.Buffer
+	enc := NewFrameEncoder(&buf, 16)
+	for _, m := range msgs {
+		if err := enc.Encode([]byte(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := buf.Bytes()[:17], AppendFrame(nil, nil, 16); !bytes.Equal(got, want) || want[0] != 16 {
+		t.Errorf("first frame %x, want %x", got, want)
+	}
+
+	dec := NewFrameDecoder(iotest.OneByteReader(bytes.NewReader(buf.Bytes())), 16)
+	for i, m := range msgs {
+		out, err := dec.Decode()
+		if err != nil || string(out) != m {
+			t.Errorf("[%d] Decode = %q, %v", i, out, err)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestFrameErrors(t *testing.T) {
+	t.Parallel()
+
+	frame := AppendFrame(nil, []byte("hello"), 8)
+	badPad := bytes.Clone(frame)
+	badPad[len(badPad)-1] = 9
+	misaligned := append([]byte{7}, frame[1:]...)
+	huge := binary.AppendUvarint(nil, MaxFrameLen+8)
+	overflow := bytes.Repeat([]byte{0xff}, 11)
+
+	tests := []struct {
+		in   []byte
+		want error
+	}{
+		{frame[:1], io.ErrUnexpectedEOF},
+		{frame[:len(frame)-1], io.ErrUnexpectedEOF},
+		{[]byte{0x80}, io.ErrUnexpectedEOF},
+		{badPad, ErrBadPadding},
+		{misaligned, ErrBadPadding},
+		{[]byte{0}, ErrBadPadding},
+		{huge, errFrameLen},
+		{overflow, errFrameLen},
+	}
+	for i, test := range tests {
+		_, err := NewFrameDecoder(bytes.NewReader(test.in), 8).Decode()
+		if !errors.Is(err, test.want) {
+			t.Errorf("[%d] Decode(%x) = %v, want %v", i, test.in, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The fuzz targets in this file compare the optimized implementations against
+// straightforward reference implementations, such as
+// completelyUnsafeNotConstantTimeUnpad and checkPaddingBytewise, so that each
+// optimization stays semantically identical. Run them with, e.g.,
+//
+//	go test -fuzz FuzzUnpad
+
+func addSeeds(f *testing.F) {
+	for _, test := range PadTests {
+		f.Add(test.out, uint8(16))
+		f.Add(test.in, uint8(16))
+	}
+	for _, test := range BadPadTests {
+		f.Add(test, uint8(16))
+	}
+	f.Add(bytes.Repeat([]byte{0xff}, 255), uint8(255))
+	f.Add(bytes.Repeat([]byte{0x08}, 8), uint8(8))
+}
+
+func FuzzUnpad(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, buf []byte, _ uint8) {
+		got, err := Unpad(buf)
+		want, wantErr := completelyUnsafeNotConstantTimeUnpad(buf)
+		if (err == nil) != (wantErr == nil) || !bytes.Equal(got, want) {
+			t.Errorf("Unpad(%x) = %x, %v; reference %x, %v", buf, got, err, want, wantErr)
+		}
+	})
+}
+
+func FuzzUnpadBlock(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, buf []byte, size uint8) {
+		if size == 0 {
+			size = 1
+		}
+		got, err := UnpadBlock(buf, int(size))
+		want, wantErr := completelyUnsafeNotConstantTimeUnpad(buf)
+		if len(buf)%int(size) != 0 || (wantErr == nil && len(buf)-len(want) > int(size)) {
+			want, wantErr = nil, ErrBadPadding
+		}
+		if (err == nil) != (wantErr == nil) || !bytes.Equal(got, want) {
+			t.Errorf("UnpadBlock(%x, %d) = %x, %v; reference %x, %v", buf, size, got, err, want, wantErr)
+		}
+	})
+}
+
+func FuzzCheckPadding(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, buf []byte, padLen uint8) {
+		want := checkPaddingBytewise(buf, padLen)
+		if got := checkPaddingGeneric(buf, padLen); got != want {
+			t.Errorf("checkPaddingGeneric(%x, %d) = %d, reference %d", buf, padLen, got, want)
+		}
+		// The vector implementations only need to agree when checkPadding
+		// would accept padLen.
+		if padLen != 0 && int(padLen) <= len(buf) {
+			if got := checkPaddingBytes(buf, padLen); got != want {
+				t.Errorf("checkPaddingBytes(%x, %d) = %d, reference %d", buf, padLen, got, want)
+			}
+		}
+	})
+}
+
+func FuzzPad(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, buf []byte, size uint8) {
+		if size == 0 {
+			size = 1
+		}
+		i := int(size) - len(buf)%int(size)
+		want := append(bytes.Clone(buf), bytes.Repeat([]byte{byte(i)}, i)...)
+		if got := Pad(bytes.Clone(buf), int(size)); !bytes.Equal(got, want) {
+			t.Errorf("Pad(%x, %d) = %x, reference %x", buf, size, got, want)
+		}
+		if got := AppendPad(nil, buf, int(size)); !bytes.Equal(got, want) {
+			t.Errorf("AppendPad(nil, %x, %d) = %x, reference %x", buf, size, got, want)
+		}
+	})
+}
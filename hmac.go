@@ -0,0 +1,94 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"encoding"
+	"errors"
+	"hash"
+)
+
+// ErrBadMAC is returned by UnpadAndVerifyHMAC when either the padding or the
+// MAC is bad. The two cases are deliberately indistinguishable.
+var ErrBadMAC = errors.New("pkcs7pad: bad padding or MAC")
+
+// UnpadAndVerifyHMAC removes the PKCS#7 padding from buf and verifies that tag
+// is the HMAC of the unpadded plaintext under the given hash function and key.
+// buf must be the decrypted plaintext, not the ciphertext. This is the
+// MAC-then-pad ordering used by many legacy protocols, which is notoriously
+// hard to get right: checking the padding and the MAC separately leaks which
+// of them failed, and computing the MAC over a secret-length plaintext leaks
+// the padding length.
+//
+// UnpadAndVerifyHMAC combines both checks into a single constant-time verdict,
+// returning ErrBadMAC if either fails. If the padding is malformed, the MAC is
+// computed over the whole buffer instead. Since the padding is at most 255
+// bytes, the inner hash is finished for every length the plaintext could have,
+// and the right digest is selected in constant time, so the hash function does
+// the same work whatever the padding length. This costs up to 256 extra digests
+// per call. It is fastest with hash functions that implement
+// encoding.BinaryMarshaler, as those in the standard library do.
+func UnpadAndVerifyHMAC(buf, tag []byte, h func() hash.Hash, key []byte) ([]byte, error) {
+	padLen, good := checkPadding(buf)
+	n := subtle.ConstantTimeSelect(good, len(buf)-padLen, len(buf))
+
+	inner, outer := h(), h()
+	ipad, opad := hmacPads(outer, key)
+
+	// Everything before start is part of the plaintext whatever the padding
+	// length, so it is hashed once. The candidate lengths from start on
+	// are each hashed starting from a saved copy of that state.
+	start := max(0, len(buf)-255)
+	inner.Write(ipad)
+	inner.Write(buf[:start])
+	restore := func() {
+		inner.Reset()
+		inner.Write(ipad)
+		inner.Write(buf[:start])
+	}
+	if m, ok := inner.(encoding.BinaryMarshaler); ok {
+		if u, ok := inner.(encoding.BinaryUnmarshaler); ok {
+			if state, err := m.MarshalBinary(); err == nil {
+				restore = func() { u.UnmarshalBinary(state) }
+			}
+		}
+	}
+
+	sum := make([]byte, inner.Size())
+	var digest []byte
+	for m := start; m <= len(buf); m++ {
+		restore()
+		inner.Write(buf[start:m])
+		digest = inner.Sum(digest[:0])
+		subtle.ConstantTimeCopy(subtle.ConstantTimeEq(int32(m-start), int32(n-start)), sum, digest)
+	}
+
+	outer.Reset()
+	outer.Write(opad)
+	outer.Write(sum)
+	good &= subtle.ConstantTimeCompare(outer.Sum(nil), tag)
+
+	if good != 1 {
+		return nil, ErrBadMAC
+	}
+	return buf[:n], nil
+}
+
+// hmacPads returns the inner and outer padded keys HMAC derives from key, as
+// defined in RFC 2104. h is used to hash keys longer than its block size.
+//
+// https://tools.ietf.org/html/rfc2104#section-2
+func hmacPads(h hash.Hash, key []byte) (ipad, opad []byte) {
+	if len(key) > h.BlockSize() {
+		h.Write(key)
+		key = h.Sum(nil)
+	}
+	ipad = make([]byte, h.BlockSize())
+	opad = make([]byte, h.BlockSize())
+	copy(ipad, key)
+	copy(opad, key)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+	return ipad, opad
+}
@@ -0,0 +1,81 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"hash"
+	"testing"
+)
+
+func TestUnpadAndVerifyHMAC(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("hmac key")
+	sign := func(b []byte) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(b)
+		return mac.Sum(nil)
+	}
+
+	for i, test := range PadTests {
+		out, err := UnpadAndVerifyHMAC(test.out, sign(test.in), sha256.New, key)
+		if err != nil {
+			t.Errorf("[%d] error verifying: %v", i, err)
+		}
+		if !bytes.Equal(out, test.in) {
+			t.Errorf("[%d] %x != %x", i, out, test.in)
+		}
+
+		// A good MAC with bad padding, and vice versa.
+		if _, err := UnpadAndVerifyHMAC(test.out, sign(test.in)[1:], sha256.New, key); err != ErrBadMAC {
+			t.Errorf("[%d] expected %v, got %v", i, ErrBadMAC, err)
+		}
+		bad := append(append([]byte(nil), test.in...), 0x00)
+		if _, err := UnpadAndVerifyHMAC(bad, sign(test.in), sha256.New, key); err != ErrBadMAC {
+			t.Errorf("[%d] expected %v, got %v", i, ErrBadMAC, err)
+		}
+	}
+
+	// Even a MAC over the whole (badly padded) buffer must be rejected.
+	bad := testString[:aes.BlockSize]
+	if _, err := UnpadAndVerifyHMAC(bad, sign(bad), sha256.New, key); err != ErrBadMAC {
+		t.Errorf("expected %v, got %v", ErrBadMAC, err)
+	}
+}
+
+// opaqueHash hides everything but the hash.Hash methods of the hash it wraps.
+type opaqueHash struct{ hash.Hash }
+
+func TestUnpadAndVerifyHMACPadLengths(t *testing.T) {
+	t.Parallel()
+
+	hashes := []func() hash.Hash{
+		sha256.New,
+		func() hash.Hash { return opaqueHash{sha1.New()} },
+	}
+	// The second key is longer than the hash block size.
+	keys := [][]byte{[]byte("hmac key"), bytes.Repeat([]byte{0x0b}, 100)}
+	for i, h := range hashes {
+		for _, key := range keys {
+			for _, n := range []int{0, 1, 100, 300} {
+				in := bytes.Repeat([]byte{0xa5}, n)
+				mac := hmac.New(h, key)
+				mac.Write(in)
+				tag := mac.Sum(nil)
+
+				padded := Pad(bytes.Clone(in), 255)
+				out, err := UnpadAndVerifyHMAC(padded, tag, h, key)
+				if err != nil || !bytes.Equal(out, in) {
+					t.Errorf("[%d/%d/%d] UnpadAndVerifyHMAC = %x, %v", i, len(key), n, out, err)
+				}
+				padded[len(padded)-1]--
+				if _, err := UnpadAndVerifyHMAC(padded, tag, h, key); err != ErrBadMAC {
+					t.Errorf("[%d/%d/%d] expected %v, got %v", i, len(key), n, ErrBadMAC, err)
+				}
+			}
+		}
+	}
+}
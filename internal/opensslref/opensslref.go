@@ -0,0 +1,113 @@
+//go:build cgo && openssl
+
+// Package opensslref exposes OpenSSL's EVP block cipher padding, for
+// differential testing against this module. It is only built with the openssl
+// build tag, and requires cgo and libcrypto.
+//
+// EVP does not expose its padding directly, so it is observed through a block
+// cipher in ECB mode: Pad encrypts with padding enabled and decrypts without,
+// and Unpad does the reverse. Block sizes are therefore limited to those of
+// the available ciphers: 8 (Triple DES) and 16 (AES).
+package opensslref
+
+/*
+#cgo LDFLAGS: -lcrypto
+#include <openssl/evp.h>
+
+static const unsigned char key[24] = "opensslref test key 0123";
+
+static const EVP_CIPHER *cipher_for(int size) {
+	switch (size) {
+	case 8:
+		return EVP_des_ede3_ecb();
+	case 16:
+		return EVP_aes_128_ecb();
+	}
+	return NULL;
+}
+
+// crypt runs in through the cipher for the given block size, with padding
+// enabled or disabled, and returns the length of the output or -1 on failure.
+static int crypt(int size, int enc, int padding, const unsigned char *in, int inlen, unsigned char *out) {
+	const EVP_CIPHER *c = cipher_for(size);
+	EVP_CIPHER_CTX *ctx = EVP_CIPHER_CTX_new();
+	int n = -1, m = 0;
+	if (c == NULL || ctx == NULL) {
+		goto done;
+	}
+	if (!EVP_CipherInit_ex(ctx, c, NULL, key, NULL, enc)) {
+		goto done;
+	}
+	EVP_CIPHER_CTX_set_padding(ctx, padding);
+	if (!EVP_CipherUpdate(ctx, out, &n, in, inlen)) {
+		n = -1;
+		goto done;
+	}
+	if (!EVP_CipherFinal_ex(ctx, out + n, &m)) {
+		n = -1;
+		goto done;
+	}
+	n += m;
+done:
+	EVP_CIPHER_CTX_free(ctx);
+	return n;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// BlockSizes are the block sizes supported by Pad and Unpad.
+var BlockSizes = []int{8, 16}
+
+func crypt(size int, enc, padding bool, in []byte) ([]byte, bool) {
+	switch size {
+	case 8, 16:
+	default:
+		panic(fmt.Sprintf("opensslref: unsupported block size %d", size))
+	}
+	// Keep the buffers non-empty so that they have addresses.
+	in = append(in[:len(in):len(in)], 0)
+	out := make([]byte, len(in)+2*size)
+	n := C.crypt(C.int(size), C.int(b2i(enc)), C.int(b2i(padding)),
+		(*C.uchar)(unsafe.Pointer(&in[0])), C.int(len(in)-1),
+		(*C.uchar)(unsafe.Pointer(&out[0])))
+	if n < 0 {
+		return nil, false
+	}
+	return out[:n], true
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Pad returns buf with PKCS#7 padding applied by OpenSSL.
+func Pad(buf []byte, size int) []byte {
+	ct, ok := crypt(size, true, true, buf)
+	if !ok {
+		panic("opensslref: encryption failed")
+	}
+	pt, ok := crypt(size, false, false, ct)
+	if !ok {
+		panic("opensslref: decryption failed")
+	}
+	return pt
+}
+
+// Unpad returns buf with its PKCS#7 padding removed by OpenSSL, and whether
+// OpenSSL accepted the padding. It returns false for buffers whose length is
+// not a multiple of size.
+func Unpad(buf []byte, size int) ([]byte, bool) {
+	ct, ok := crypt(size, true, false, buf)
+	if !ok {
+		return nil, false
+	}
+	return crypt(size, false, true, ct)
+}
@@ -0,0 +1,48 @@
+package pkcs7pad_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad/vectors"
+)
+
+// TestInterop checks the vector files in testdata/interop, which were produced
+// by other implementations of PKCS#7 padding. See the scripts alongside them.
+func TestInterop(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "interop", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files {
+		var load func(io.Reader) ([]vectors.Vector, error)
+		switch filepath.Ext(name) {
+		case ".json":
+			load = vectors.LoadJSON
+		case ".csv":
+			load = vectors.LoadCSV
+		default:
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs, err := load(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("[%s] %v", name, err)
+			continue
+		}
+		if len(vs) == 0 {
+			t.Errorf("[%s] no vectors", name)
+		}
+		if err := vectors.CheckAll(vs); err != nil {
+			t.Errorf("[%s] %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package pkcs7pad
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"io"
+)
+
+// PadISO10126 appends ISO 10126 padding to the given buffer such that the
+// resulting slice of bytes has a length divisible by the given size. ISO 10126
+// padding consists of random bytes followed by a final byte holding the total
+// number of padding bytes.
+//
+// The random bytes are read from r. If r is nil, crypto/rand.Reader is used. An
+// error is returned only if reading from r fails.
+func PadISO10126(buf []byte, size int, r io.Reader) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+	i := size - (len(buf) % size)
+	buf = append(buf, make([]byte, i)...)
+	if _, err := io.ReadFull(r, buf[len(buf)-i:len(buf)-1]); err != nil {
+		return nil, err
+	}
+	buf[len(buf)-1] = byte(i)
+	return buf, nil
+}
+
+// UnpadISO10126 returns a subslice of the input buffer with trailing ISO 10126
+// padding removed. Since the padding bytes themselves are random, only the final
+// length byte is checked, and it is checked in constant time. An error is
+// returned if the length byte is out of range.
+func UnpadISO10126(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+
+	padLen := buf[len(buf)-1]
+	good := subtle.ConstantTimeLessOrEq(1, int(padLen))
+	good &= subtle.ConstantTimeLessOrEq(int(padLen), len(buf))
+
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+
+	return buf[:len(buf)-int(padLen)], nil
+}
+
+// iso10126 always reads from crypto/rand. Since the Scheme interface has no way
+// to report errors from Pad, it panics if reading random bytes fails.
+type iso10126 struct{}
+
+func (iso10126) Name() string { return "iso10126" }
+func (iso10126) Pad(buf []byte, size int) []byte {
+	buf, err := PadISO10126(buf, size, nil)
+	if err != nil {
+		panic("pkcs7pad: " + err.Error())
+	}
+	return buf
+}
+func (iso10126) Unpad(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	// As with PKCS7, the padding can be no longer than a block.
+	n := len(buf) - min(len(buf), size)
+	out, err := UnpadISO10126(buf[n:])
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n+len(out)], nil
+}
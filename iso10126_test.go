@@ -0,0 +1,95 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPadISO10126(t *testing.T) {
+	t.Parallel()
+
+	fill := bytes.Repeat([]byte{0xaa}, 1024)
+	for i, test := range PadTests {
+		buf := make([]byte, len(test.in))
+		copy(buf, test.in)
+		pad, err := PadISO10126(buf, aes.BlockSize, bytes.NewReader(fill))
+		if err != nil {
+			t.Fatalf("[%d] error padding: %v", i, err)
+		}
+
+		want := make([]byte, len(test.out))
+		copy(want, test.in)
+		for j := len(test.in); j < len(want)-1; j++ {
+			want[j] = 0xaa
+		}
+		want[len(want)-1] = test.out[len(test.out)-1]
+		if !bytes.Equal(pad, want) {
+			t.Errorf("[%d] %x != %x", i, pad, want)
+		}
+
+		unpad, err := UnpadISO10126(pad)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+}
+
+func TestPadISO10126DefaultRand(t *testing.T) {
+	t.Parallel()
+
+	pad, err := PadISO10126(testString[:3:3], aes.BlockSize, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pad) != aes.BlockSize || pad[len(pad)-1] != 13 {
+		t.Errorf("bad padding %x", pad)
+	}
+}
+
+func TestPadISO10126ReadError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	_, err := PadISO10126(nil, aes.BlockSize, iotest.ErrReader(boom))
+	if err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+}
+
+var BadISO10126Tests = [][]byte{
+	{},
+	{0xaa, 0xaa, 0x04},
+	{0xde, 0xad, 0xbe, 0xef, 0x00},
+}
+
+func TestUnpadISO10126Errors(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range BadISO10126Tests {
+		_, err := UnpadISO10126(test)
+		if !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+}
+
+func TestISO10126SchemeFinalBlock(t *testing.T) {
+	t.Parallel()
+
+	// The padding is longer than a block, which UnpadISO10126 accepts but
+	// the scheme must not.
+	buf := make([]byte, 2*aes.BlockSize)
+	buf[len(buf)-1] = aes.BlockSize + 1
+	if _, err := UnpadISO10126(buf); err != nil {
+		t.Fatalf("UnpadISO10126: %v", err)
+	}
+	if _, err := ISO10126.Unpad(buf, aes.BlockSize); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding, got %v", err)
+	}
+}
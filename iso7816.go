@@ -0,0 +1,74 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// PadISO7816 appends ISO/IEC 7816-4 padding to the given buffer such that the
+// resulting slice of bytes has a length divisible by the given size. ISO/IEC
+// 7816-4 padding consists of a single 0x80 byte followed by as many zero bytes
+// as are necessary to reach a block boundary.
+func PadISO7816(buf []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	i := size - (len(buf) % size)
+	buf = append(buf, make([]byte, i)...)
+	buf[len(buf)-i] = 0x80
+	return buf
+}
+
+// UnpadISO7816 returns a subslice of the input buffer with trailing ISO/IEC
+// 7816-4 padding removed. It locates the 0x80 marker in constant time, and
+// returns an error if the padding bytes are malformed.
+func UnpadISO7816(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+
+	// Walk backwards over (at most) the last 255 bytes, remembering the
+	// position of the first non-zero byte we see. The padding is good if
+	// that byte exists and is the 0x80 marker. Every byte in the window is
+	// examined regardless of where the marker is.
+	toCheck := 255
+	if toCheck > len(buf) {
+		toCheck = len(buf)
+	}
+	padLen := 0
+	found := 0
+	good := 0
+	for i := 0; i < toCheck; i++ {
+		b := buf[len(buf)-1-i]
+
+		first := (found ^ 1) & (subtle.ConstantTimeByteEq(0, b) ^ 1)
+		marker := first & subtle.ConstantTimeByteEq(0x80, b)
+		padLen = subtle.ConstantTimeSelect(marker, i+1, padLen)
+		good |= marker
+		found |= first
+	}
+
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+
+	return buf[:len(buf)-padLen], nil
+}
+
+type iso7816 struct{}
+
+func (iso7816) Name() string                    { return "iso7816" }
+func (iso7816) Pad(buf []byte, size int) []byte { return PadISO7816(buf, size) }
+func (iso7816) Unpad(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	// Padding never spans more than one block, so the marker must be in
+	// the final block.
+	n := len(buf) - min(len(buf), size)
+	out, err := UnpadISO7816(buf[n:])
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n+len(out)], nil
+}
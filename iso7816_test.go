@@ -0,0 +1,90 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+	"testing/quick"
+)
+
+func TestPadISO7816(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		buf := make([]byte, len(test.in))
+		copy(buf, test.in)
+		pad := PadISO7816(buf, aes.BlockSize)
+
+		want := make([]byte, len(test.out))
+		copy(want, test.in)
+		want[len(test.in)] = 0x80
+		if !bytes.Equal(pad, want) {
+			t.Errorf("[%d] %x != %x", i, pad, want)
+		}
+
+		unpad, err := UnpadISO7816(pad)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+}
+
+var BadISO7816Tests = [][]byte{
+	{},
+	{0x00, 0x00, 0x00},
+	{0xde, 0xad, 0xbe, 0xef},
+	{0xde, 0xad, 0x80, 0x01, 0x00},
+}
+
+func TestUnpadISO7816Errors(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range BadISO7816Tests {
+		_, err := UnpadISO7816(test)
+		if !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+}
+
+func completelyUnsafeNotConstantTimeUnpadISO7816(buf []byte) ([]byte, error) {
+	for i := len(buf) - 1; i >= 0 && i >= len(buf)-255; i-- {
+		switch buf[i] {
+		case 0x00:
+			continue
+		case 0x80:
+			return buf[:i], nil
+		}
+		break
+	}
+	return nil, ErrBadPadding
+}
+
+func TestUnpadISO7816BlackBox(t *testing.T) {
+	t.Parallel()
+	err := quick.CheckEqual(UnpadISO7816, completelyUnsafeNotConstantTimeUnpadISO7816, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestISO7816SchemeFinalBlock(t *testing.T) {
+	t.Parallel()
+
+	// The marker is more than a block from the end, which UnpadISO7816
+	// accepts but the scheme must not.
+	buf := make([]byte, 2*aes.BlockSize)
+	buf[aes.BlockSize-1] = 0x80
+	if _, err := UnpadISO7816(buf); err != nil {
+		t.Fatalf("UnpadISO7816: %v", err)
+	}
+	for _, s := range []Scheme{ISO7816, ISO9797M2} {
+		if _, err := s.Unpad(buf, aes.BlockSize); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%s] expected ErrBadPadding, got %v", s.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// The functions in this file implement the padding methods of ISO/IEC 9797-1,
+// which are used by CBC-MAC and the MAC algorithms in many banking protocols.
+// They are referred to by number:
+//
+//   - Method 1 appends as few zero bytes as possible (none, if the data is
+//     already aligned), except that empty data is padded to a full block. Like
+//     zero padding, it is ambiguous.
+//   - Method 2 appends a 0x80 byte and then zero bytes. It is identical to
+//     ISO/IEC 7816-4 padding.
+//   - Method 3 prepends a block holding the length of the data in bits, and
+//     then pads the data as in Method 1 (but leaves empty data empty).
+
+// PadISO9797M1 appends ISO/IEC 9797-1 Method 1 padding to the given buffer such
+// that the resulting slice of bytes has a length divisible by the given size.
+func PadISO9797M1(buf []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(buf) == 0 {
+		return append(buf, make([]byte, size)...)
+	}
+	return PadZeros(buf, size)
+}
+
+// PadISO9797M3 returns the given data with ISO/IEC 9797-1 Method 3 padding: a
+// block holding the length of buf in bits as a big-endian integer, followed by
+// buf and as few zero bytes as are needed to reach a block boundary. Since
+// Method 3 puts padding at the front, the result is newly allocated. An error
+// is returned if the length doesn't fit in a block.
+func PadISO9797M3(buf []byte, size int) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	var header [8]byte
+	bits := uint64(len(buf)) * 8
+	binary.BigEndian.PutUint64(header[:], bits)
+	if size < 8 && bits>>(8*size) != 0 {
+		return nil, fmt.Errorf("pkcs7pad: %d bytes is too long for ISO/IEC 9797-1 Method 3 with block size %d", len(buf), size)
+	}
+
+	out := make([]byte, size, size+len(buf)+size)
+	copy(out[max(size-8, 0):], header[max(8-size, 0):])
+	out = append(out, buf...)
+	return PadZeros(out, size), nil
+}
+
+// UnpadISO9797M3 returns the data from a buffer with ISO/IEC 9797-1 Method 3
+// padding, as a subslice of buf. It returns an error wrapping ErrBadPadding if
+// the length block does not match the length of buf, or if the padding bytes
+// are not zero. It does not run in constant time: the length block is
+// normally public, since MACs are computed over the padded data.
+func UnpadISO9797M3(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+	header, data := buf[:size], buf[size:]
+	if size > 8 && !allZero(header[:size-8]) {
+		return nil, ErrBadPadding
+	}
+	var b [8]byte
+	copy(b[max(8-size, 0):], header[max(size-8, 0):])
+	bits := binary.BigEndian.Uint64(b[:])
+	if bits%8 != 0 || bits/8 > uint64(len(data)) {
+		return nil, ErrBadPadding
+	}
+	n := int(bits / 8)
+	if len(data)-n >= size || (n == 0) != (len(data) == 0) || !allZero(data[n:]) {
+		return nil, ErrBadPadding
+	}
+	return data[:n], nil
+}
+
+func allZero(b []byte) bool {
+	return len(bytes.TrimLeft(b, "\x00")) == 0
+}
+
+type iso9797m1 struct{}
+
+func (iso9797m1) Name() string                    { return "iso9797-m1" }
+func (iso9797m1) Pad(buf []byte, size int) []byte { return PadISO9797M1(buf, size) }
+func (iso9797m1) Unpad(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+	return UnpadZeros(buf), nil
+}
+
+// iso9797m2 is ISO/IEC 7816-4 padding under its ISO/IEC 9797-1 name.
+type iso9797m2 struct{ iso7816 }
+
+func (iso9797m2) Name() string { return "iso9797-m2" }
@@ -0,0 +1,91 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPadISO9797M1(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, out []byte
+	}{
+		{[]byte{}, make([]byte, 8)},
+		{[]byte("YELLOW"), []byte("YELLOW\x00\x00")},
+		{[]byte("YELLOWSU"), []byte("YELLOWSU")},
+	}
+	for i, test := range tests {
+		if got := PadISO9797M1(bytes.Clone(test.in), 8); !bytes.Equal(got, test.out) {
+			t.Errorf("[%d] %x != %x", i, got, test.out)
+		}
+		if got, err := ISO9797M1.Unpad(test.out, 8); err != nil || !bytes.Equal(got, test.in) {
+			t.Errorf("[%d] Unpad = %x, %v", i, got, err)
+		}
+	}
+	if _, err := ISO9797M1.Unpad(nil, 8); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding for an empty buffer, got %v", err)
+	}
+}
+
+func TestISO9797M2(t *testing.T) {
+	t.Parallel()
+
+	in := []byte("YELLOW")
+	if got, want := ISO9797M2.Pad(bytes.Clone(in), 8), PadISO7816(bytes.Clone(in), 8); !bytes.Equal(got, want) {
+		t.Errorf("%x != %x", got, want)
+	}
+}
+
+func TestPadISO9797M3(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   []byte
+		size int
+		out  []byte
+	}{
+		{[]byte{}, 8, make([]byte, 8)},
+		{[]byte("YELLOW"), 8, []byte("\x00\x00\x00\x00\x00\x00\x00\x30YELLOW\x00\x00")},
+		{[]byte("YELLOWSU"), 8, []byte("\x00\x00\x00\x00\x00\x00\x00\x40YELLOWSU")},
+		{[]byte("YELLOW"), 16, []byte("\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x30YELLOW\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")},
+		{[]byte("YE"), 2, []byte("\x00\x10YE")},
+	}
+	for i, test := range tests {
+		got, err := PadISO9797M3(test.in, test.size)
+		if err != nil || !bytes.Equal(got, test.out) {
+			t.Errorf("[%d] PadISO9797M3 = %x, %v; want %x", i, got, err, test.out)
+		}
+		if got, err := UnpadISO9797M3(test.out, test.size); err != nil || !bytes.Equal(got, test.in) {
+			t.Errorf("[%d] UnpadISO9797M3 = %x, %v", i, got, err)
+		}
+	}
+
+	if _, err := PadISO9797M3(make([]byte, 32), 1); err == nil {
+		t.Error("expected an error for a length that doesn't fit in a block")
+	}
+}
+
+func TestUnpadISO9797M3Errors(t *testing.T) {
+	t.Parallel()
+
+	bad := [][]byte{
+		{},
+		[]byte("\x00\x00\x00\x00\x00\x00\x00\x30YELLOW\x00"),
+		[]byte("\x00\x00\x00\x00\x00\x00\x00\x31YELLOW\x00\x00"),
+		[]byte("\x00\x00\x00\x00\x00\x00\x00\x40YELLOW\x00\x00\x00\x00\x00\x00\x00\x00"),
+		[]byte("\x00\x00\x00\x00\x00\x00\x00\x30YELLOW\x00\x01"),
+		[]byte("\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"),
+		[]byte("\x00\x00\x00\x00\x00\x00\x00\x30YELLOW\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"),
+		[]byte("\x00\x00\x00\x00\x00\x00\x01\x00YELLOW\x00\x00"),
+	}
+	for i, buf := range bad {
+		if _, err := UnpadISO9797M3(buf, 8); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+	if _, err := UnpadISO9797M3([]byte("\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x30YELLOW\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), 16); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding for a header that is too long, got %v", err)
+	}
+}
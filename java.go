@@ -0,0 +1,38 @@
+package pkcs7pad
+
+import (
+	"fmt"
+	"strings"
+)
+
+// javaPaddings maps the padding names used in Java Cipher transformations,
+// including the additional ones provided by Bouncy Castle, to schemes. Java
+// calls PKCS#7 padding PKCS5Padding regardless of the block size.
+var javaPaddings = map[string]Scheme{
+	"nopadding":         None,
+	"pkcs5padding":      PKCS7,
+	"pkcs7padding":      PKCS7,
+	"iso10126padding":   ISO10126,
+	"iso10126-2padding": ISO10126,
+	"x923padding":       X923,
+	"x9.23padding":      X923,
+	"iso7816-4padding":  ISO7816,
+	"iso9797-1padding":  ISO7816,
+	"zerobytepadding":   Zero,
+	"tbcpadding":        TBC,
+}
+
+// ParseJavaPadding returns the padding scheme named by a Java Cipher
+// transformation, such as "PKCS5Padding", or by the padding part of a full
+// transformation, such as "AES/CBC/PKCS5Padding". Like Java, it ignores case.
+// The names added by the Bouncy Castle provider are also recognized.
+func ParseJavaPadding(name string) (Scheme, error) {
+	padding := name
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		padding = name[i+1:]
+	}
+	if s, ok := javaPaddings[strings.ToLower(padding)]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("pkcs7pad: unknown Java padding %q", name)
+}
@@ -0,0 +1,39 @@
+package pkcs7pad
+
+import "testing"
+
+func TestParseJavaPadding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want Scheme
+	}{
+		{"PKCS5Padding", PKCS7},
+		{"pkcs5padding", PKCS7},
+		{"AES/CBC/PKCS5Padding", PKCS7},
+		{"DESede/ECB/PKCS7Padding", PKCS7},
+		{"NoPadding", None},
+		{"AES/CBC/NoPadding", None},
+		{"ISO10126Padding", ISO10126},
+		{"ISO10126-2Padding", ISO10126},
+		{"X923Padding", X923},
+		{"ISO7816-4Padding", ISO7816},
+		{"ZeroBytePadding", Zero},
+		{"TBCPadding", TBC},
+	}
+	for i, test := range tests {
+		s, err := ParseJavaPadding(test.name)
+		if err != nil {
+			t.Errorf("[%d] %v", i, err)
+		} else if s != test.want {
+			t.Errorf("[%d] %q parsed as %q, want %q", i, test.name, s.Name(), test.want.Name())
+		}
+	}
+
+	for i, name := range []string{"", "PKCS5", "AES/CBC/", "OAEPPadding", "AES/GCM/NoPadding/"} {
+		if _, err := ParseJavaPadding(name); err == nil {
+			t.Errorf("[%d] expected an error for %q", i, name)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package pkcs7pad
+
+import "fmt"
+
+// Quirks are deviations from PKCS#7 that LenientUnpad accepts. They can be
+// combined with the | operator.
+type Quirks uint
+
+const (
+	// QuirkUnequalPadBytes accepts padding whose final byte is a plausible
+	// length, whatever the values of the other padding bytes, as written
+	// by implementations that fill padding with garbage (and as
+	// WithLenient does).
+	QuirkUnequalPadBytes Quirks = 1 << iota
+
+	// QuirkZeroFill accepts the zero padding written by PHP's mcrypt
+	// extension and similar libraries: if the final byte is zero, the
+	// trailing zero bytes of the final block are removed. Plaintexts that
+	// end in zero bytes lose them.
+	QuirkZeroFill
+
+	// QuirkZeroFullBlock accepts a full block of padding encoded as zero
+	// bytes, as WithZeroFullBlock does. It takes precedence over
+	// QuirkZeroFill for a final block of zeros.
+	QuirkZeroFullBlock
+
+	// QuirkOversizePad accepts padding longer than the block size, up to
+	// 255 bytes, as written by implementations that got the block size
+	// wrong.
+	QuirkOversizePad
+
+	// QuirkUnaligned accepts buffers whose length is not a multiple of
+	// the block size, such as files that were truncated or had trailing
+	// bytes appended.
+	QuirkUnaligned
+
+	// QuirkMissingPadding returns the buffer unchanged, rather than an
+	// error, if no other rule recognizes its padding, as for data written
+	// by implementations that didn't always pad.
+	QuirkMissingPadding
+)
+
+// LenientUnpad removes PKCS#7 padding from buf, as UnpadBlock does, but also
+// accepts the deviations from PKCS#7 selected by quirks. It is meant for
+// rescuing data written by broken implementations, and each quirk makes
+// padding errors, and so corruption or tampering, harder to detect.
+//
+// LenientUnpad does not run in constant time, and must not be used on data an
+// attacker can choose.
+func LenientUnpad(buf []byte, size int, quirks Quirks) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(buf)%size != 0 && quirks&QuirkUnaligned == 0 {
+		return nil, errMisaligned(int64(len(buf)), size)
+	}
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+
+	maxPad := size
+	if quirks&QuirkOversizePad != 0 {
+		maxPad = 255
+	}
+	maxPad = min(maxPad, len(buf))
+	block := buf[len(buf)-min(size, len(buf)):]
+	p := int(buf[len(buf)-1])
+
+	switch {
+	case p == 0 && quirks&QuirkZeroFullBlock != 0 && len(block) == size && allZero(block):
+		return buf[:len(buf)-size], nil
+	case p == 0 && quirks&QuirkZeroFill != 0:
+		n := len(buf)
+		for n > len(buf)-len(block) && buf[n-1] == 0 {
+			n--
+		}
+		return buf[:n], nil
+	case p >= 1 && p <= maxPad:
+		if quirks&QuirkUnequalPadBytes != 0 || allEqual(buf[len(buf)-p:], byte(p)) {
+			return buf[:len(buf)-p], nil
+		}
+	}
+	if quirks&QuirkMissingPadding != 0 {
+		return buf, nil
+	}
+	return nil, ErrBadPadding
+}
+
+func allEqual(b []byte, v byte) bool {
+	for _, c := range b {
+		if c != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+)
+
+func TestLenientUnpadStrict(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		out, err := LenientUnpad(test.out, aes.BlockSize, 0)
+		if err != nil || !bytes.Equal(out, test.in) {
+			t.Errorf("[%d] LenientUnpad = %x, %v", i, out, err)
+		}
+	}
+	for i, buf := range [][]byte{{}, testString[:15], testString, bytes.Repeat([]byte{0x11}, 32)} {
+		if _, err := LenientUnpad(buf, aes.BlockSize, 0); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
+
+func TestLenientUnpadQuirks(t *testing.T) {
+	t.Parallel()
+
+	garbage := append(testString[:13:13], 0xaa, 0xbb, 0x03)
+	zeroFill := append(testString[:5:5], make([]byte, 11)...)
+	zeroBlock := append(testString[:16:16], make([]byte, 16)...)
+	oversize := append(testString[:15:15], bytes.Repeat([]byte{17}, 17)...)
+	short := append(testString[:5:5], 3, 3, 3)
+	tests := []struct {
+		quirks Quirks
+		in     []byte
+		out    []byte
+		err    bool
+	}{
+		{0, garbage, nil, true},
+		{QuirkUnequalPadBytes, garbage, testString[:13], false},
+		{0, zeroFill, nil, true},
+		{QuirkZeroFill, zeroFill, testString[:5], false},
+		{QuirkZeroFill, zeroBlock, testString, false},
+		{QuirkZeroFullBlock, zeroBlock, testString, false},
+		{QuirkZeroFullBlock, zeroFill, nil, true},
+		{QuirkZeroFill | QuirkZeroFullBlock, zeroFill, testString[:5], false},
+		{0, oversize, nil, true},
+		{QuirkOversizePad, oversize, testString[:15], false},
+		{0, short, nil, true},
+		{QuirkUnaligned, short, testString[:5], false},
+		{QuirkUnaligned, []byte{1, 2, 3}, nil, true},
+		{QuirkUnaligned | QuirkMissingPadding, []byte{1, 2, 3}, []byte{1, 2, 3}, false},
+		{QuirkMissingPadding, testString, testString, false},
+		{QuirkMissingPadding, PadTests[3].out, testString[:3], false},
+	}
+	for i, test := range tests {
+		out, err := LenientUnpad(test.in, aes.BlockSize, test.quirks)
+		if test.err {
+			if !errors.Is(err, ErrBadPadding) {
+				t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(out, test.out) {
+			t.Errorf("[%d] %x != %x", i, out, test.out)
+		}
+	}
+}
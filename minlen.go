@@ -0,0 +1,56 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// minPaddedLen returns the smallest multiple of size that is at least minLen.
+func minPaddedLen(size, minLen int) int {
+	return (max(minLen, 0) + size - 1) / size * size
+}
+
+// PadMinLen is like Pad, but it also pads buf to at least minLen bytes, rounded
+// up to a multiple of size, so that short messages can't be told apart by their
+// length. The result is max(PaddedLen(len(buf), size), minLen rounded up).
+//
+// Messages that are already long enough get ordinary PKCS#7 padding. Short
+// ones may need more than one block of padding, which is written in the format
+// described by PadToBucket, so that more than 255 bytes can be added. Use
+// UnpadMinLen, with the same size and minLen, to remove it. Like Pad, PadMinLen
+// panics if size is not between 1 and 255.
+func PadMinLen(buf []byte, size, minLen int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return padTo(buf, max(PaddedLen(len(buf), size), minPaddedLen(size, minLen)))
+}
+
+// UnpadMinLen removes the padding added by PadMinLen with the same size and
+// minLen. It returns an error wrapping ErrBadPadding if the length of buf is
+// not a multiple of size or is less than minLen, or if the padding is
+// malformed. More than a block of padding is only accepted in a buffer of
+// exactly the minimum length. The padding is checked in constant time.
+func UnpadMinLen(buf []byte, size, minLen int) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(buf)%size != 0 {
+		return nil, errMisaligned(int64(len(buf)), size)
+	}
+	floor := minPaddedLen(size, minLen)
+	if len(buf) < floor {
+		return nil, fmt.Errorf("%w: length %d is less than the minimum of %d", ErrBadPadding, len(buf), floor)
+	}
+	// Both lengths are public, so the bound can be chosen with a branch.
+	maxPad := size
+	if len(buf) == floor {
+		maxPad = len(buf)
+	}
+	padLen, good := checkPaddingTo(buf)
+	good &= subtle.ConstantTimeLessOrEq(padLen, maxPad)
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+	return buf[:len(buf)-padLen], nil
+}
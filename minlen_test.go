@@ -0,0 +1,55 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPadMinLen(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{1, 8, 16, 255} {
+		for _, minLen := range []int{0, 1, 15, 100, 1000} {
+			floor := minPaddedLen(size, minLen)
+			for n := 0; n < floor+2*size; n++ {
+				msg := bytes.Repeat([]byte{byte(n)}, n)
+				padded := PadMinLen(bytes.Clone(msg), size, minLen)
+				want := max(PaddedLen(n, size), floor)
+				if len(padded) != want {
+					t.Fatalf("[%d/%d/%d] padded to %d bytes, want %d", size, minLen, n, len(padded), want)
+				}
+				if want == PaddedLen(n, size) && !bytes.Equal(padded, Pad(bytes.Clone(msg), size)) {
+					t.Fatalf("[%d/%d/%d] %x is not ordinary PKCS#7 padding", size, minLen, n, padded)
+				}
+				out, err := UnpadMinLen(padded, size, minLen)
+				if err != nil {
+					t.Fatalf("[%d/%d/%d] error unpadding: %v", size, minLen, n, err)
+				}
+				if !bytes.Equal(out, msg) {
+					t.Fatalf("[%d/%d/%d] %x != %x", size, minLen, n, out, msg)
+				}
+			}
+		}
+	}
+}
+
+func TestUnpadMinLenErrors(t *testing.T) {
+	t.Parallel()
+
+	bad := [][]byte{
+		{},
+		Pad(nil, 16),
+		Pad(make([]byte, 33), 16)[:47],
+		// More than a block of padding, beyond the minimum length.
+		append(make([]byte, 32), bytes.Repeat([]byte{32}, 32)...),
+	}
+	for i, buf := range bad {
+		if _, err := UnpadMinLen(buf, 16, 48); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+	if out, err := UnpadMinLen(bytes.Repeat([]byte{48}, 48), 16, 48); err != nil || len(out) != 0 {
+		t.Errorf("UnpadMinLen of a padded empty message = %x, %v", out, err)
+	}
+}
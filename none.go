@@ -0,0 +1,34 @@
+package pkcs7pad
+
+import "fmt"
+
+// PadNone returns the given buffer unchanged. It exists so that unpadded cipher
+// modes can be treated uniformly with padded ones, and it panics if the buffer
+// is not already a multiple of the given block size.
+func PadNone(buf []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(buf)%size != 0 {
+		panic(fmt.Sprintf("pkcs7pad: buffer length %d is not a multiple of block size %d", len(buf), size))
+	}
+	return buf
+}
+
+// UnpadNone returns the given buffer unchanged, or an error if its length is not
+// a multiple of the given block size.
+func UnpadNone(buf []byte, size int) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(buf)%size != 0 {
+		return nil, errMisaligned(int64(len(buf)), size)
+	}
+	return buf, nil
+}
+
+type none struct{}
+
+func (none) Name() string                               { return "none" }
+func (none) Pad(buf []byte, size int) []byte            { return PadNone(buf, size) }
+func (none) Unpad(buf []byte, size int) ([]byte, error) { return UnpadNone(buf, size) }
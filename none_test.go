@@ -0,0 +1,42 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPadNone(t *testing.T) {
+	t.Parallel()
+
+	buf := testString[:8]
+	if pad := PadNone(buf, 8); !bytes.Equal(pad, buf) {
+		t.Errorf("%x != %x", pad, buf)
+	}
+	unpad, err := UnpadNone(buf, 8)
+	if err != nil {
+		t.Errorf("error unpadding: %v", err)
+	}
+	if !bytes.Equal(unpad, buf) {
+		t.Errorf("%x != %x", unpad, buf)
+	}
+}
+
+func TestPadNoneMisaligned(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	PadNone(testString[:7], 8)
+}
+
+func TestUnpadNoneErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := UnpadNone(testString[:7], 8); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected BadCiphertext, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build cgo && openssl
+
+package pkcs7pad
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad/internal/opensslref"
+)
+
+// FuzzOpenSSL compares Pad and UnpadBlock against OpenSSL's EVP padding. Run it
+// with
+//
+//	go test -tags openssl -fuzz FuzzOpenSSL
+func FuzzOpenSSL(f *testing.F) {
+	for _, test := range PadTests {
+		f.Add(test.in, false)
+		f.Add(test.out, true)
+	}
+	for _, test := range BadPadTests {
+		f.Add(test, false)
+	}
+
+	f.Fuzz(func(t *testing.T, buf []byte, size16 bool) {
+		size := 8
+		if size16 {
+			size = 16
+		}
+
+		want := opensslref.Pad(buf, size)
+		if got := Pad(bytes.Clone(buf), size); !bytes.Equal(got, want) {
+			t.Errorf("Pad(%x, %d) = %x, OpenSSL %x", buf, size, got, want)
+		}
+
+		got, err := UnpadBlock(buf, size)
+		want, ok := opensslref.Unpad(buf, size)
+		if (err == nil) != ok {
+			t.Fatalf("UnpadBlock(%x, %d) error %v, OpenSSL ok %v", buf, size, err, ok)
+		}
+		if ok && !bytes.Equal(got, want) {
+			t.Errorf("UnpadBlock(%x, %d) = %x, OpenSSL %x", buf, size, got, want)
+		}
+	})
+}
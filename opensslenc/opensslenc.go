@@ -0,0 +1,172 @@
+// Package opensslenc reads and writes the "Salted__" file format produced by
+// the openssl enc command, using AES in CBC mode with PKCS#7 padding.
+//
+// A file consists of the 8 bytes "Salted__", an 8-byte random salt, and the
+// ciphertext. The key and IV are derived from the password and salt, either
+// with OpenSSL's EVP_BytesToKey (the default for openssl enc) or with PBKDF2
+// (openssl enc -pbkdf2). The options must match those given to openssl, since
+// the file does not record them.
+//
+// The format has no integrity protection: a wrong password or a corrupted file
+// is only detected if the padding happens to be malformed. It is intended for
+// exchanging files with existing tools, and new designs should use an AEAD
+// instead.
+package opensslenc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+const (
+	magic    = "Salted__"
+	saltSize = 8
+
+	// DefaultIter is the PBKDF2 iteration count used by openssl enc -pbkdf2
+	// when -iter is not given.
+	DefaultIter = 10000
+)
+
+// ErrFormat is returned by Decrypt when its input is not in the "Salted__"
+// format.
+var ErrFormat = errors.New("opensslenc: not in openssl enc salted format")
+
+// Options describe how openssl enc was (or will be) invoked. The zero Options
+// corresponds to openssl enc -aes-256-cbc with the default message digest.
+type Options struct {
+	// KeySize is the AES key size in bytes: 16, 24, or 32 for
+	// -aes-128-cbc, -aes-192-cbc, or -aes-256-cbc. If zero, 32 is used.
+	KeySize int
+
+	// PBKDF2 selects PBKDF2 key derivation, as with -pbkdf2 or -iter.
+	// Otherwise EVP_BytesToKey with a single iteration is used.
+	PBKDF2 bool
+
+	// Iter is the PBKDF2 iteration count, as with -iter. If zero,
+	// DefaultIter is used. It is ignored if PBKDF2 is false.
+	Iter int
+
+	// Hash is the message digest, as with -md. If nil, SHA-256 is used,
+	// which is the default since OpenSSL 1.1.0. Files written by older
+	// versions need md5.New.
+	Hash func() hash.Hash
+
+	// Rand is the source of the salt used by Encrypt. If nil,
+	// crypto/rand.Reader is used.
+	Rand io.Reader
+}
+
+func (o *Options) keySize() (int, error) {
+	if o == nil || o.KeySize == 0 {
+		return 32, nil
+	}
+	switch o.KeySize {
+	case 16, 24, 32:
+		return o.KeySize, nil
+	}
+	return 0, fmt.Errorf("opensslenc: invalid AES key size %d", o.KeySize)
+}
+
+// deriveKey returns the key and IV for the given password and salt.
+func (o *Options) deriveKey(password string, salt []byte) (key, iv []byte, err error) {
+	keySize, err := o.keySize()
+	if err != nil {
+		return nil, nil, err
+	}
+	h := sha256.New
+	if o != nil && o.Hash != nil {
+		h = o.Hash
+	}
+
+	var b []byte
+	if o != nil && o.PBKDF2 {
+		iter := o.Iter
+		if iter == 0 {
+			iter = DefaultIter
+		}
+		b, err = pbkdf2.Key(h, password, salt, iter, keySize+aes.BlockSize)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		b = bytesToKey(h, password, salt, keySize+aes.BlockSize)
+	}
+	return b[:keySize], b[keySize:], nil
+}
+
+// bytesToKey implements EVP_BytesToKey with an iteration count of 1, which is
+// what openssl enc uses: D_i = H(D_{i-1} || password || salt).
+func bytesToKey(h func() hash.Hash, password string, salt []byte, n int) []byte {
+	var out, d []byte
+	for len(out) < n {
+		md := h()
+		md.Write(d)
+		io.WriteString(md, password)
+		md.Write(salt)
+		d = md.Sum(nil)
+		out = append(out, d...)
+	}
+	return out[:n]
+}
+
+// Encrypt encrypts plaintext with the given password, producing the same
+// output as openssl enc with the corresponding options.
+func Encrypt(password string, plaintext []byte, opts *Options) ([]byte, error) {
+	r := io.Reader(rand.Reader)
+	if opts != nil && opts.Rand != nil {
+		r = opts.Rand
+	}
+	out := make([]byte, len(magic)+saltSize, len(magic)+saltSize+pkcs7pad.PaddedLen(len(plaintext), aes.BlockSize))
+	copy(out, magic)
+	salt := out[len(magic):]
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return nil, err
+	}
+
+	key, iv, err := opts.deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ct := pkcs7pad.AppendPad(out[len(out):], plaintext, aes.BlockSize)
+	cipher.NewCBCEncrypter(b, iv).CryptBlocks(ct, ct)
+	return out[:len(out)+len(ct)], nil
+}
+
+// Decrypt decrypts data written by openssl enc (or by Encrypt) with the given
+// password. It returns ErrFormat if data does not start with a salt header,
+// and an error wrapping pkcs7pad.ErrBadPadding if the password or options are
+// wrong or the data is corrupt.
+func Decrypt(password string, data []byte, opts *Options) ([]byte, error) {
+	if len(data) < len(magic)+saltSize || string(data[:len(magic)]) != magic {
+		return nil, ErrFormat
+	}
+	salt, ct := data[len(magic):len(magic)+saltSize], data[len(magic)+saltSize:]
+	if len(ct) == 0 || len(ct)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("%w: ciphertext length %d is not a non-zero multiple of the block size", pkcs7pad.ErrBadPadding, len(ct))
+	}
+
+	key, iv, err := opts.deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	pt := make([]byte, len(ct))
+	cipher.NewCBCDecrypter(b, iv).CryptBlocks(pt, ct)
+	return pkcs7pad.UnpadBlock(pt, aes.BlockSize)
+}
@@ -0,0 +1,122 @@
+package opensslenc
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+var testSalt = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+// Vectors generated with, e.g.,
+//
+//	printf 'hello, openssl' | openssl enc -aes-256-cbc -pass pass:secret -S 0102030405060708 -pbkdf2 -iter 1000
+//
+// OpenSSL 3 omits the header when -S is given, so it is added here.
+var vectors = []struct {
+	opts       Options
+	plaintext  string
+	ciphertext string
+}{
+	{Options{}, "hello, openssl", "AUtwCFPHugLNpSZUWG6rVw=="},
+	{Options{PBKDF2: true, Iter: 1000}, "hello, openssl", "bX0jX5s/0fcZGFd/zzBU7Q=="},
+	{Options{KeySize: 16, Hash: md5.New}, "hello, openssl", "FSZ9J2Jf48gLpg/KTp/bVQ=="},
+	{Options{PBKDF2: true}, "", "K3D9/AZ6+Fp3HcaXGI/MOQ=="},
+}
+
+func TestVectors(t *testing.T) {
+	t.Parallel()
+
+	for i, v := range vectors {
+		ct, err := base64.StdEncoding.DecodeString(v.ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := append(append([]byte(magic), testSalt...), ct...)
+
+		opts := v.opts
+		opts.Rand = bytes.NewReader(testSalt)
+		out, err := Encrypt("secret", []byte(v.plaintext), &opts)
+		if err != nil {
+			t.Errorf("[%d] error encrypting: %v", i, err)
+		}
+		if !bytes.Equal(out, want) {
+			t.Errorf("[%d] %x != %x", i, out, want)
+		}
+
+		pt, err := Decrypt("secret", want, &v.opts)
+		if err != nil {
+			t.Errorf("[%d] error decrypting: %v", i, err)
+		}
+		if string(pt) != v.plaintext {
+			t.Errorf("[%d] %q != %q", i, pt, v.plaintext)
+		}
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	t.Parallel()
+
+	data, err := Encrypt("secret", []byte("hello, openssl"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt("secret", data[8:], nil); err != ErrFormat {
+		t.Errorf("expected ErrFormat, got %v", err)
+	}
+	if _, err := Decrypt("secret", data[:len(data)-1], nil); !errors.Is(err, pkcs7pad.ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding for truncated data, got %v", err)
+	}
+	if _, err := Decrypt("secret", data, &Options{KeySize: 12}); err == nil {
+		t.Error("expected an error for a bad key size")
+	}
+}
+
+// TestOpenSSL checks interoperability with the openssl command, if it is
+// installed.
+func TestOpenSSL(t *testing.T) {
+	path, err := exec.LookPath("openssl")
+	if err != nil {
+		t.Skip("openssl not found")
+	}
+	plaintext := strings.Repeat("interoperability ", 5)
+
+	tests := []struct {
+		args []string
+		opts Options
+	}{
+		{[]string{"-aes-256-cbc", "-md", "sha256"}, Options{}},
+		{[]string{"-aes-192-cbc", "-pbkdf2"}, Options{KeySize: 24, PBKDF2: true}},
+		{[]string{"-aes-128-cbc", "-pbkdf2", "-iter", "20"}, Options{KeySize: 16, PBKDF2: true, Iter: 20}},
+	}
+	for i, test := range tests {
+		args, opts := test.args, test.opts
+		cmd := exec.Command(path, append([]string{"enc", "-pass", "pass:secret"}, args...)...)
+		cmd.Stdin = strings.NewReader(plaintext)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("[%d] openssl enc: %v", i, err)
+		}
+		pt, err := Decrypt("secret", out, &opts)
+		if err != nil || string(pt) != plaintext {
+			t.Errorf("[%d] Decrypt: %q, %v", i, pt, err)
+		}
+
+		data, err := Encrypt("secret", []byte(plaintext), &opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmd = exec.Command(path, append([]string{"enc", "-d", "-pass", "pass:secret"}, args...)...)
+		cmd.Stdin = bytes.NewReader(data)
+		out, err = cmd.Output()
+		if err != nil || string(out) != plaintext {
+			t.Errorf("[%d] openssl enc -d: %q, %v", i, out, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// An UnpadOption configures the behavior of UnpadWith.
+type UnpadOption func(*unpadConfig)
+
+type unpadConfig struct {
+	maxPadLen int
+	blockSize int
+	zeroize   bool
+	lenient   bool
+	zeroFull  bool
+	wantLen   int // or -1 for any length
+}
+
+// WithMaxPadLen rejects padding longer than n bytes. Only the last n bytes of
+// the buffer are scanned, so small limits also make unpadding faster. It panics
+// if n is not between 1 and 255.
+func WithMaxPadLen(n int) UnpadOption {
+	if n < 1 || n > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate maximum padding length %d", n))
+	}
+	return func(c *unpadConfig) {
+		c.maxPadLen = n
+	}
+}
+
+// WithStrictBlockSize applies the checks of UnpadBlock: the buffer must be a
+// non-zero multiple of size, and the padding may not be longer than a block.
+// It panics if size is not between 1 and 255.
+func WithStrictBlockSize(size int) UnpadOption {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return func(c *unpadConfig) {
+		c.blockSize = size
+	}
+}
+
+// WithZeroize overwrites the padding bytes with zeros after they have been
+// validated, as UnpadZeroize does.
+func WithZeroize() UnpadOption {
+	return func(c *unpadConfig) {
+		c.zeroize = true
+	}
+}
+
+// WithLenient only checks that the final byte is a plausible padding length,
+// and ignores the values of the other padding bytes. This is occasionally
+// necessary to read data written by broken implementations, but it makes
+// corruption much harder to detect and should not otherwise be used.
+func WithLenient() UnpadOption {
+	return func(c *unpadConfig) {
+		c.lenient = true
+	}
+}
+
+// WithZeroFullBlock also accepts a full block of padding encoded as size zero
+// bytes, as some old implementations write it, in addition to the standard
+// size bytes of the value size. It implies WithStrictBlockSize(size). This only
+// exists to read data written by such implementations, and should not
+// otherwise be used.
+func WithZeroFullBlock(size int) UnpadOption {
+	strict := WithStrictBlockSize(size)
+	return func(c *unpadConfig) {
+		strict(c)
+		c.zeroFull = true
+	}
+}
+
+// WithExpectedLen also requires the unpadded plaintext to be exactly n bytes
+// long, for when the length is known from an authenticated header. This
+// catches tampering that changed only the padding, and the comparison is made
+// in constant time along with the padding check, so a mismatch is
+// indistinguishable from bad padding. It panics if n is negative.
+func WithExpectedLen(n int) UnpadOption {
+	if n < 0 {
+		panic(fmt.Sprintf("pkcs7pad: negative expected length %d", n))
+	}
+	return func(c *unpadConfig) {
+		c.wantLen = n
+	}
+}
+
+// UnpadWith is like Unpad, but its behavior can be adjusted with options. With
+// no options, it is equivalent to Unpad. In all cases, the padding is checked
+// in constant time.
+func UnpadWith(buf []byte, opts ...UnpadOption) ([]byte, error) {
+	c := unpadConfig{maxPadLen: 255, wantLen: -1}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if c.blockSize != 0 {
+		if len(buf)%c.blockSize != 0 {
+			return nil, errMisaligned(int64(len(buf)), c.blockSize)
+		}
+		c.maxPadLen = min(c.maxPadLen, c.blockSize)
+	}
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+
+	// The padding can never extend before the start of this window, so
+	// there's no need to look at anything else.
+	window := buf[len(buf)-min(len(buf), c.maxPadLen):]
+	var padLen, good int
+	if c.lenient {
+		padLen = int(window[len(window)-1])
+		good = subtle.ConstantTimeLessOrEq(1, padLen)
+		good &= subtle.ConstantTimeLessOrEq(padLen, len(window))
+	} else {
+		padLen, good = checkPadding(window)
+	}
+	if c.zeroFull && len(window) == c.blockSize {
+		// A block of zeros is a full block of padding.
+		var acc byte
+		for _, b := range window {
+			acc |= b
+		}
+		if c.lenient {
+			acc = window[len(window)-1]
+		}
+		zero := subtle.ConstantTimeByteEq(acc, 0)
+		padLen = subtle.ConstantTimeSelect(zero, c.blockSize, padLen)
+		good |= zero
+	}
+	if c.wantLen >= 0 {
+		d := uint64(len(buf)-padLen) ^ uint64(c.wantLen)
+		good &= int((d|-d)>>63) ^ 1
+	}
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+
+	if c.zeroize {
+		clear(buf[len(buf)-padLen:])
+	}
+	return buf[:len(buf)-padLen], nil
+}
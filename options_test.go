@@ -0,0 +1,92 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+	"testing/quick"
+)
+
+func TestUnpadWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	f := func(buf []byte) bool {
+		got, err := UnpadWith(buf)
+		want, wantErr := Unpad(buf)
+		return bytes.Equal(got, want) && err == wantErr
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnpadWithOptions(t *testing.T) {
+	t.Parallel()
+
+	long := bytes.Repeat([]byte{0x11}, 32)
+	lenient := append(testString[:13:13], 0xaa, 0xbb, 0x03)
+	zeroBlock := append(testString[:16:16], make([]byte, 16)...)
+	lastZero := append(bytes.Repeat(testString, 2)[:31], 0)
+	tests := []struct {
+		opts []UnpadOption
+		in   []byte
+		out  []byte
+		err  bool
+	}{
+		{nil, long, long[:15], false},
+		{[]UnpadOption{WithMaxPadLen(16)}, long, nil, true},
+		{[]UnpadOption{WithMaxPadLen(16)}, PadTests[3].out, testString[:3], false},
+		{[]UnpadOption{WithStrictBlockSize(aes.BlockSize)}, long, nil, true},
+		{[]UnpadOption{WithStrictBlockSize(aes.BlockSize)}, PadTests[1].out[1:], nil, true},
+		{[]UnpadOption{WithStrictBlockSize(aes.BlockSize)}, PadTests[16].out, testString, false},
+		{nil, lenient, nil, true},
+		{[]UnpadOption{WithLenient()}, lenient, testString[:13], false},
+		{[]UnpadOption{WithLenient()}, []byte{0x00}, nil, true},
+		{[]UnpadOption{WithLenient(), WithMaxPadLen(2)}, lenient, nil, true},
+		{nil, zeroBlock, nil, true},
+		{[]UnpadOption{WithZeroFullBlock(aes.BlockSize)}, zeroBlock, testString, false},
+		{[]UnpadOption{WithZeroFullBlock(aes.BlockSize)}, PadTests[16].out, testString, false},
+		{[]UnpadOption{WithZeroFullBlock(aes.BlockSize)}, PadTests[5].out, testString[:5], false},
+		{[]UnpadOption{WithZeroFullBlock(aes.BlockSize)}, zeroBlock[:31], nil, true},
+		{[]UnpadOption{WithZeroFullBlock(aes.BlockSize)}, lastZero, nil, true},
+		{[]UnpadOption{WithZeroFullBlock(aes.BlockSize), WithLenient()}, lastZero, testString[:16], false},
+		{[]UnpadOption{WithZeroFullBlock(aes.BlockSize), WithMaxPadLen(8)}, zeroBlock, nil, true},
+		{[]UnpadOption{WithExpectedLen(5)}, PadTests[5].out, testString[:5], false},
+		{[]UnpadOption{WithExpectedLen(4)}, PadTests[5].out, nil, true},
+		{[]UnpadOption{WithExpectedLen(6)}, PadTests[5].out, nil, true},
+		{[]UnpadOption{WithExpectedLen(5)}, PadTests[6].out, nil, true},
+		{[]UnpadOption{WithExpectedLen(16), WithZeroFullBlock(aes.BlockSize)}, zeroBlock, testString, false},
+	}
+	for i, test := range tests {
+		out, err := UnpadWith(test.in, test.opts...)
+		if test.err {
+			if !errors.Is(err, ErrBadPadding) {
+				t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(out, test.out) {
+			t.Errorf("[%d] %x != %x", i, out, test.out)
+		}
+	}
+}
+
+func TestUnpadWithZeroize(t *testing.T) {
+	t.Parallel()
+
+	buf := append([]byte(nil), PadTests[5].out...)
+	out, err := UnpadWith(buf, WithZeroize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, testString[:5]) {
+		t.Errorf("%x != %x", out, testString[:5])
+	}
+	if tail := buf[len(out):]; !bytes.Equal(tail, make([]byte, len(tail))) {
+		t.Errorf("padding not wiped: %x", tail)
+	}
+}
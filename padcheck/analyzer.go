@@ -0,0 +1,18 @@
+//go:build padcheck_analysis
+
+package padcheck
+
+import "golang.org/x/tools/go/analysis"
+
+// Analyzer reports the same diagnostics as Check, for drivers built on
+// golang.org/x/tools/go/analysis.
+var Analyzer = &analysis.Analyzer{
+	Name: "padcheck",
+	Doc:  "report uses of padding errors that can create a padding oracle",
+	Run: func(pass *analysis.Pass) (any, error) {
+		for _, d := range Check(pass.Files, pass.TypesInfo) {
+			pass.Reportf(d.Pos, "%s", d.Message)
+		}
+		return nil, nil
+	},
+}
@@ -0,0 +1,291 @@
+// Package padcheck finds code that uses this module in ways that can create a
+// padding oracle. It reports two patterns:
+//
+//   - Returning early when unpadding fails, before a MAC is verified later in
+//     the same function. The MAC should be verified first (encrypt-then-MAC),
+//     or the two checks combined with pkcs7pad.UnpadAndVerifyHMAC, so that an
+//     attacker can't tell a padding failure from a MAC failure.
+//   - Logging a padding error together with a byte slice, such as the
+//     ciphertext or the buffer being unpadded. Logs are often visible to more
+//     people than the data is, and tying errors to inputs makes them an
+//     oracle.
+//
+// The checks are heuristics, and work on one function at a time.
+//
+// The analysis is written against the standard library's go/ast and go/types
+// so that it has no dependencies. Check runs it on a type-checked package,
+// and cmd/padcheck runs it from the command line. An adapter for
+// golang.org/x/tools/go/analysis, for use with tools like gopls and
+// multichecker drivers, is in analyzer.go; it is built only with the
+// padcheck_analysis build tag, since this module does not depend on x/tools.
+package padcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// A Diagnostic is a problem found by Check.
+type Diagnostic struct {
+	Pos     token.Pos
+	Message string
+}
+
+const (
+	msgEarlyReturn = "padding error returned before the MAC is verified, which can create a padding oracle; verify the MAC first or use pkcs7pad.UnpadAndVerifyHMAC"
+	msgLogged      = "padding error logged along with a byte slice, which can create a padding oracle"
+)
+
+// Check returns the diagnostics for the given files, which must have been
+// type-checked with info's Types, Defs, Uses, and Selections maps populated.
+func Check(files []*ast.File, info *types.Info) []Diagnostic {
+	var diags []Diagnostic
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			var body *ast.BlockStmt
+			switch fn := n.(type) {
+			case *ast.FuncDecl:
+				body = fn.Body
+			case *ast.FuncLit:
+				body = fn.Body
+			}
+			if body != nil {
+				diags = append(diags, checkFunc(body, info)...)
+			}
+			return true
+		})
+	}
+	return diags
+}
+
+// checkFunc checks a single function body. Nested function literals are
+// checked separately, so they are skipped here.
+func checkFunc(body *ast.BlockStmt, info *types.Info) []Diagnostic {
+	// First find the error variables that hold padding errors, and the
+	// position of the last MAC comparison.
+	errVars := make(map[types.Object]bool)
+	var lastMAC token.Pos
+	inspectFunc(body, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Rhs) == 1 && isUnpadCall(n.Rhs[0], info) {
+				if id, ok := n.Lhs[len(n.Lhs)-1].(*ast.Ident); ok {
+					if obj := objectOf(id, info); obj != nil {
+						errVars[obj] = true
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if isMACCompare(n, info) {
+				lastMAC = max(lastMAC, n.Pos())
+			}
+		}
+	})
+	if len(errVars) == 0 {
+		return nil
+	}
+
+	var diags []Diagnostic
+	inspectFunc(body, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			if n.Pos() < lastMAC && isErrCheck(n.Cond, errVars, info) && returns(n.Body) {
+				diags = append(diags, Diagnostic{n.Pos(), msgEarlyReturn})
+			}
+		case *ast.CallExpr:
+			if isLogCall(n, info) && logsErrWithBytes(n, errVars, info) {
+				diags = append(diags, Diagnostic{n.Pos(), msgLogged})
+			}
+		}
+	})
+	return diags
+}
+
+// inspectFunc calls f for each node in body, not descending into function
+// literals.
+func inspectFunc(body *ast.BlockStmt, f func(ast.Node)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if n != nil {
+			f(n)
+		}
+		return true
+	})
+}
+
+func objectOf(id *ast.Ident, info *types.Info) types.Object {
+	if obj := info.Defs[id]; obj != nil {
+		return obj
+	}
+	return info.Uses[id]
+}
+
+// callee returns the function or method called by call, if it is statically
+// known.
+func callee(call *ast.CallExpr, info *types.Info) *types.Func {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := info.Uses[id].(*types.Func)
+	return fn
+}
+
+func isModulePkg(pkg *types.Package) bool {
+	if pkg == nil {
+		return false
+	}
+	path := pkg.Path()
+	return path == "github.com/zenazn/pkcs7pad" || path == "github.com/zenazn/pkcs7pad/v2"
+}
+
+// isUnpadCall reports whether e calls a function from this module that returns
+// an error when padding is malformed.
+func isUnpadCall(e ast.Expr, info *types.Info) bool {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn := callee(call, info)
+	if fn == nil {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Results().Len() == 0 || !isError(sig.Results().At(sig.Results().Len()-1).Type()) {
+		return false
+	}
+
+	name := fn.Name()
+	if isModulePkg(fn.Pkg()) {
+		switch {
+		case name == "UnpadAndVerifyHMAC":
+			return false
+		case strings.HasPrefix(name, "Unpad"), name == "DecryptCBC":
+			return true
+		case name == "Finish":
+			if recv := sig.Recv(); recv != nil {
+				return strings.HasSuffix(types.TypeString(recv.Type(), nil), ".PaddedDecrypter")
+			}
+		}
+	}
+	// Calls through the Scheme interface are attributed to the package
+	// of the interface.
+	if sig.Recv() != nil && name == "Unpad" {
+		if named, ok := derefNamed(sig.Recv().Type()); ok && isModulePkg(named.Obj().Pkg()) {
+			return true
+		}
+	}
+	return false
+}
+
+func derefNamed(t types.Type) (*types.Named, bool) {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return named, ok
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// isMACCompare reports whether call compares MACs.
+func isMACCompare(call *ast.CallExpr, info *types.Info) bool {
+	fn := callee(call, info)
+	if fn == nil || fn.Pkg() == nil {
+		return false
+	}
+	switch fn.Pkg().Path() + "." + fn.Name() {
+	case "crypto/hmac.Equal", "crypto/subtle.ConstantTimeCompare":
+		return true
+	}
+	return false
+}
+
+// isErrCheck reports whether cond is err != nil for one of the error
+// variables.
+func isErrCheck(cond ast.Expr, errVars map[types.Object]bool, info *types.Info) bool {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || bin.Op != token.NEQ {
+		return false
+	}
+	for _, pair := range [][2]ast.Expr{{bin.X, bin.Y}, {bin.Y, bin.X}} {
+		id, ok := ast.Unparen(pair[0]).(*ast.Ident)
+		if ok && errVars[objectOf(id, info)] && isNil(pair[1], info) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNil(e ast.Expr, info *types.Info) bool {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, isNil := info.Uses[id].(*types.Nil)
+	return isNil
+}
+
+// returns reports whether block contains a return statement outside of any
+// function literal.
+func returns(block *ast.BlockStmt) bool {
+	found := false
+	inspectFunc(block, func(n ast.Node) {
+		if _, ok := n.(*ast.ReturnStmt); ok {
+			found = true
+		}
+	})
+	return found
+}
+
+// isLogCall reports whether call writes a log message or prints.
+func isLogCall(call *ast.CallExpr, info *types.Info) bool {
+	fn := callee(call, info)
+	if fn == nil || fn.Pkg() == nil {
+		return false
+	}
+	name := fn.Name()
+	switch fn.Pkg().Path() {
+	case "log":
+		return strings.HasPrefix(name, "Print") || strings.HasPrefix(name, "Fatal") || strings.HasPrefix(name, "Panic")
+	case "log/slog":
+		switch name {
+		case "Debug", "Info", "Warn", "Error", "Log", "DebugContext", "InfoContext", "WarnContext", "ErrorContext":
+			return true
+		}
+	case "fmt":
+		return strings.HasPrefix(name, "Print") || strings.HasPrefix(name, "Fprint")
+	}
+	return false
+}
+
+// logsErrWithBytes reports whether call's arguments include one of the error
+// variables and a byte slice.
+func logsErrWithBytes(call *ast.CallExpr, errVars map[types.Object]bool, info *types.Info) bool {
+	var hasErr, hasBytes bool
+	for _, arg := range call.Args {
+		if id, ok := ast.Unparen(arg).(*ast.Ident); ok && errVars[objectOf(id, info)] {
+			hasErr = true
+			continue
+		}
+		if t := info.TypeOf(arg); t != nil {
+			if s, ok := t.Underlying().(*types.Slice); ok {
+				if b, ok := s.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+					hasBytes = true
+				}
+			}
+		}
+	}
+	return hasErr && hasBytes
+}
@@ -0,0 +1,96 @@
+package padcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var wantRE = regexp.MustCompile(`// want ("[^"]*")`)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob("testdata/src/a/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []*ast.File
+	for _, path := range paths {
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("a", fset, files, info); err != nil {
+		t.Fatal(err)
+	}
+
+	// Collect the expected diagnostics from the // want comments, keyed by
+	// file and line.
+	want := make(map[string]string)
+	for _, f := range files {
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				m := wantRE.FindStringSubmatch(c.Text)
+				if m == nil {
+					continue
+				}
+				msg, err := strconv.Unquote(m[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				want[position(fset, c.Pos())] = msg
+			}
+		}
+	}
+
+	got := make(map[string]string)
+	for _, d := range Check(files, info) {
+		got[position(fset, d.Pos)] = d.Message
+	}
+
+	var keys []string
+	for k := range want {
+		keys = append(keys, k)
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch w, g := want[k], got[k]; {
+		case g == "":
+			t.Errorf("%s: missing diagnostic %q", k, w)
+		case w == "":
+			t.Errorf("%s: unexpected diagnostic %q", k, g)
+		case !strings.Contains(g, w):
+			t.Errorf("%s: diagnostic %q does not contain %q", k, g, w)
+		}
+	}
+}
+
+func position(fset *token.FileSet, pos token.Pos) string {
+	p := fset.Position(pos)
+	return fmt.Sprintf("%s:%d", filepath.Base(p.Filename), p.Line)
+}
@@ -0,0 +1,81 @@
+package a
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func mac(key, msg []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+func unpadThenMAC(key, pt, tag []byte) ([]byte, error) {
+	out, err := pkcs7pad.Unpad(pt)
+	if err != nil { // want "returned before the MAC is verified"
+		return nil, err
+	}
+	if !hmac.Equal(mac(key, out), tag) {
+		return nil, errors.New("bad mac")
+	}
+	return out, nil
+}
+
+func macThenUnpad(key, pt, tag []byte) ([]byte, error) {
+	if !hmac.Equal(mac(key, pt), tag) {
+		return nil, errors.New("bad mac")
+	}
+	out, err := pkcs7pad.UnpadBlock(pt, 16)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func combined(key, pt, tag []byte) ([]byte, error) {
+	out, err := pkcs7pad.UnpadAndVerifyHMAC(pt, tag, sha256.New, key)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(tag, tag) {
+		return nil, err
+	}
+	return out, nil
+}
+
+func scheme(s pkcs7pad.Scheme, key, pt, tag []byte) ([]byte, error) {
+	out, err := s.Unpad(pt, 16)
+	if err != nil { // want "returned before the MAC is verified"
+		return nil, err
+	}
+	if !hmac.Equal(mac(key, out), tag) {
+		return nil, errors.New("bad mac")
+	}
+	return out, nil
+}
+
+func logged(ct []byte) {
+	if _, err := pkcs7pad.Unpad(ct); err != nil {
+		log.Printf("bad padding in %x: %v", ct, err) // want "logged along with a byte slice"
+	}
+}
+
+func printed(id string, ct []byte) []byte {
+	out, err := pkcs7pad.Unpad(ct)
+	if err != nil {
+		fmt.Println("decrypting", id, err)
+		return nil
+	}
+	return out
+}
+
+func unrelated(ct []byte) {
+	err := errors.New("other")
+	log.Printf("%x: %v", ct, err)
+}
@@ -0,0 +1,152 @@
+// Package padconn implements a minimal secure channel over a net.Conn, built
+// from a block cipher in CBC mode with PKCS#7 padding and HMAC.
+//
+// Data written to a Conn is split into records of at most MaxRecordSize bytes.
+// Each record is sent as a 4-byte big-endian length, followed by that many
+// bytes: a random nonce (the CBC initialization vector) and the content sealed
+// with an AEAD such as those in the cbchmac package. The additional data is the
+// record's 64-bit big-endian sequence number, so records can't be reordered,
+// replayed, or dropped without detection. The length prefix is not itself
+// authenticated, but changing it changes what is read as the sealed content.
+//
+// The package does no key exchange: each side needs the same pair of keys,
+// with one side's sending key being the other's receiving key, and a key pair
+// must never be used for more than one connection, since sequence numbers
+// start at zero. It is intended for talking to legacy peers that can only do
+// CBC; new designs should use crypto/tls.
+package padconn
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+// MaxRecordSize is the largest amount of data carried by a single record.
+const MaxRecordSize = 16 << 10
+
+const headerSize = 4
+
+// ErrBadRecord is returned by Read when a record fails authentication or is too
+// large. Bad padding is deliberately not distinguished from a bad MAC. After it
+// is returned, the Conn can't be read from again.
+var ErrBadRecord = errors.New("padconn: bad record")
+
+// A Conn is a net.Conn whose reads and writes are protected by records. Read
+// and Write may be called concurrently with each other, as for any net.Conn.
+// Close, deadlines, and addresses are those of the underlying connection.
+type Conn struct {
+	net.Conn
+
+	wmu     sync.Mutex
+	send    cipher.AEAD
+	sendSeq uint64
+	wbuf    []byte
+	werr    error
+
+	rmu     sync.Mutex
+	recv    cipher.AEAD
+	recvSeq uint64
+	rbuf    []byte
+	plain   []byte
+	rerr    error
+}
+
+// New returns a Conn that seals the records it writes to c with send and opens
+// the records it reads with recv. Both are typically created with one of the
+// constructors in the cbchmac package, such as cbchmac.NewA128CBCHS256; any
+// AEAD that accepts random nonces may be used.
+func New(c net.Conn, send, recv cipher.AEAD) *Conn {
+	return &Conn{Conn: c, send: send, recv: recv}
+}
+
+func additionalData(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, seq)
+}
+
+// Write seals p into one or more records and writes them to the underlying
+// connection. After an error, every later Write fails.
+func (c *Conn) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.werr != nil {
+		return 0, c.werr
+	}
+	n := 0
+	for len(p) > 0 {
+		k := min(len(p), MaxRecordSize)
+		if err := c.writeRecord(p[:k]); err != nil {
+			c.werr = err
+			return n, err
+		}
+		n += k
+		p = p[k:]
+	}
+	return n, nil
+}
+
+func (c *Conn) writeRecord(content []byte) error {
+	ns := c.send.NonceSize()
+	if n := headerSize + ns + len(content) + c.send.Overhead(); cap(c.wbuf) < n {
+		c.wbuf = make([]byte, 0, n)
+	}
+	buf := c.wbuf[:headerSize+ns]
+	nonce := buf[headerSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	buf = c.send.Seal(buf, nonce, content, additionalData(c.sendSeq))
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)-headerSize))
+	c.sendSeq++
+	_, err := c.Conn.Write(buf)
+	return err
+}
+
+// Read reads data from the next record into p. Each record is authenticated
+// before any of its data is returned.
+func (c *Conn) Read(p []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	for len(c.plain) == 0 {
+		if c.rerr != nil {
+			return 0, c.rerr
+		}
+		c.rerr = c.readRecord()
+	}
+	n := copy(p, c.plain)
+	c.plain = c.plain[n:]
+	return n, nil
+}
+
+func (c *Conn) readRecord() error {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(c.Conn, header[:]); err != nil {
+		return err
+	}
+	ns := c.recv.NonceSize()
+	n := int(binary.BigEndian.Uint32(header[:]))
+	if n < ns || n > ns+MaxRecordSize+c.recv.Overhead() {
+		return ErrBadRecord
+	}
+	if cap(c.rbuf) < n {
+		c.rbuf = make([]byte, n)
+	}
+	buf := c.rbuf[:n]
+	if _, err := io.ReadFull(c.Conn, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	pt, err := c.recv.Open(buf[ns:ns], buf[:ns], buf[ns:], additionalData(c.recvSeq))
+	if err != nil {
+		return ErrBadRecord
+	}
+	c.recvSeq++
+	c.plain = pt
+	return nil
+}
@@ -0,0 +1,143 @@
+package padconn
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad/cbchmac"
+)
+
+func testAEAD(t *testing.T, b byte) cipher.AEAD {
+	t.Helper()
+	a, err := cbchmac.NewA128CBCHS256(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// testPair returns two ends of a connection, whose keys are crossed.
+func testPair(t *testing.T) (*Conn, *Conn) {
+	t.Helper()
+	a, b := net.Pipe()
+	t.Cleanup(func() { a.Close(); b.Close() })
+	return New(a, testAEAD(t, 1), testAEAD(t, 2)), New(b, testAEAD(t, 2), testAEAD(t, 1))
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	client, server := testPair(t)
+	msg := make([]byte, 2*MaxRecordSize+100)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	go func() {
+		client.Write([]byte("hello"))
+		client.Write(msg)
+		client.Close()
+	}()
+	got, err := io.ReadAll(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append([]byte("hello"), msg...); !bytes.Equal(got, want) {
+		t.Errorf("read %d bytes, want %d", len(got), len(want))
+	}
+}
+
+func TestDuplex(t *testing.T) {
+	t.Parallel()
+
+	client, server := testPair(t)
+	go func() {
+		buf := make([]byte, 5)
+		io.ReadFull(server, buf)
+		server.Write(bytes.ToUpper(buf))
+	}()
+	if _, err := client.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(client, buf); err != nil || string(buf) != "HELLO" {
+		t.Errorf("read %q, %v", buf, err)
+	}
+}
+
+// records seals each message as a record from a fresh Conn, and returns the raw
+// bytes written to the wire.
+func records(t *testing.T, msgs ...string) []byte {
+	t.Helper()
+	a, b := net.Pipe()
+	defer b.Close()
+	c := New(a, testAEAD(t, 1), testAEAD(t, 2))
+	go func() {
+		for _, m := range msgs {
+			c.Write([]byte(m))
+		}
+		a.Close()
+	}()
+	wire, _ := io.ReadAll(b)
+	return wire
+}
+
+// readWire reads everything from a Conn whose peer sent the given bytes.
+func readWire(t *testing.T, wire []byte) ([]byte, error) {
+	t.Helper()
+	a, b := net.Pipe()
+	defer b.Close()
+	go func() {
+		a.Write(wire)
+		a.Close()
+	}()
+	return io.ReadAll(New(b, testAEAD(t, 3), testAEAD(t, 1)))
+}
+
+func TestTampering(t *testing.T) {
+	t.Parallel()
+
+	wire := records(t, "first", "second")
+	if got, err := readWire(t, wire); err != nil || string(got) != "firstsecond" {
+		t.Fatalf("untampered: %q, %v", got, err)
+	}
+	first := headerSize + int(binary.BigEndian.Uint32(wire))
+
+	for i := headerSize; i < len(wire); i++ {
+		if i >= first && i < first+headerSize {
+			// Length prefixes are covered below.
+			continue
+		}
+		bad := bytes.Clone(wire)
+		bad[i] ^= 1
+		if _, err := readWire(t, bad); !errors.Is(err, ErrBadRecord) {
+			t.Errorf("[%d] flipped byte: %v", i, err)
+		}
+	}
+
+	// Swapping, dropping, or replaying records must be detected.
+	swapped := append(bytes.Clone(wire[first:]), wire[:first]...)
+	if _, err := readWire(t, swapped); !errors.Is(err, ErrBadRecord) {
+		t.Errorf("swapped: %v", err)
+	}
+	if _, err := readWire(t, wire[first:]); !errors.Is(err, ErrBadRecord) {
+		t.Errorf("dropped: %v", err)
+	}
+	replayed := append(bytes.Clone(wire[:first]), wire[:first]...)
+	if _, err := readWire(t, replayed); !errors.Is(err, ErrBadRecord) {
+		t.Errorf("replayed: %v", err)
+	}
+
+	if _, err := readWire(t, wire[:len(wire)-1]); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated: %v", err)
+	}
+	huge := bytes.Clone(wire)
+	binary.BigEndian.PutUint32(huge, 1<<30)
+	if _, err := readWire(t, huge); !errors.Is(err, ErrBadRecord) {
+		t.Errorf("huge record: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package padme implements PADMÉ, the length-hiding padding scheme from
+// "Reducing Metadata Leakage from Encrypted Files and Communication with
+// PURBs" (Nikitin et al., PETS 2019).
+//
+// https://petsymposium.org/2019/files/papers/issue4/popets-2019-0056.pdf
+//
+// PADMÉ rounds a length up to one whose binary representation has at most
+// about log log n significant bits, so that a padded length leaks O(log log n)
+// bits about the original length, rather than the O(log n) bits leaked by
+// padding to a multiple of a block size. The overhead is at most 12%, and
+// decreases as lengths grow.
+//
+// The padding is zeros and is not self-describing: the original length must be
+// carried elsewhere, such as in an encrypted header, and passed to Unpad.
+package padme
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math/bits"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// Len returns the length that a message of n bytes is padded to. It panics if
+// n is negative.
+func Len(n int) int {
+	if n < 0 {
+		panic(fmt.Sprintf("padme: negative length %d", n))
+	}
+	if n < 2 {
+		return n
+	}
+	e := bits.Len(uint(n)) - 1 // floor(log2(n))
+	s := bits.Len(uint(e))     // floor(log2(e)) + 1
+	mask := 1<<(e-s) - 1
+	return (n + mask) &^ mask
+}
+
+// Pad appends zeros to buf until its length is Len(len(buf)). If buf has
+// enough spare capacity, the padding is written into it in place. Otherwise,
+// Pad copies buf into a new array whose capacity is exactly the padded length.
+func Pad(buf []byte) []byte {
+	n := Len(len(buf))
+	if cap(buf) < n {
+		grown := make([]byte, len(buf), n)
+		copy(grown, buf)
+		buf = grown
+	}
+	i := len(buf)
+	buf = buf[:n]
+	clear(buf[i:])
+	return buf
+}
+
+// Unpad returns the first n bytes of buf, which must have been produced by Pad
+// from a message of n bytes. It returns an error wrapping
+// pkcs7pad.ErrBadPadding if the length of buf is not Len(n), or if the padding
+// is not all zeros. The padding is checked in constant time.
+func Unpad(buf []byte, n int) ([]byte, error) {
+	if n < 0 || len(buf) != Len(n) {
+		return nil, fmt.Errorf("%w: length %d is not the padded length of %d bytes", pkcs7pad.ErrBadPadding, len(buf), n)
+	}
+	var acc byte
+	for _, b := range buf[n:] {
+		acc |= b
+	}
+	if subtle.ConstantTimeByteEq(acc, 0) != 1 {
+		return nil, pkcs7pad.ErrBadPadding
+	}
+	return buf[:n], nil
+}
@@ -0,0 +1,100 @@
+package padme
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// refLen is PADMÉ as written in the paper, using floating point.
+func refLen(n int) int {
+	if n < 2 {
+		return n
+	}
+	e := math.Floor(math.Log2(float64(n)))
+	s := math.Floor(math.Log2(e)) + 1
+	mask := 1<<int(e-s) - 1
+	return (n + mask) &^ mask
+}
+
+func TestLen(t *testing.T) {
+	t.Parallel()
+
+	known := [][2]int{
+		{0, 0}, {1, 1}, {2, 2}, {3, 3}, {8, 8}, {9, 10}, {10, 10}, {11, 12},
+		{100, 104}, {1000, 1024}, {1025, 1088}, {65537, 67584},
+	}
+	for i, test := range known {
+		if got := Len(test[0]); got != test[1] {
+			t.Errorf("[%d] Len(%d) = %d, want %d", i, test[0], got, test[1])
+		}
+	}
+
+	for n := 0; n < 1<<16; n++ {
+		got := Len(n)
+		if got != refLen(n) {
+			t.Fatalf("[%d] Len = %d, want %d", n, got, refLen(n))
+		}
+		if got < n || float64(got-n) > 0.12*float64(n) {
+			t.Fatalf("[%d] Len = %d has too much overhead", n, got)
+		}
+		if Len(got) != got {
+			t.Fatalf("[%d] Len(%d) = %d, padded lengths must be fixed points", n, got, Len(got))
+		}
+	}
+}
+
+func TestLenPanics(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Len(-1) did not panic")
+		}
+	}()
+	Len(-1)
+}
+
+func TestPadUnpad(t *testing.T) {
+	t.Parallel()
+
+	for n := 0; n < 2000; n += 7 {
+		msg := bytes.Repeat([]byte{0xa5}, n)
+		padded := Pad(bytes.Clone(msg))
+		if len(padded) != Len(n) {
+			t.Fatalf("[%d] padded to %d bytes", n, len(padded))
+		}
+		out, err := Unpad(padded, n)
+		if err != nil || !bytes.Equal(out, msg) {
+			t.Fatalf("[%d] Unpad = %x, %v", n, out, err)
+		}
+
+		inPlace := make([]byte, n, Len(n))
+		if n > 0 && &Pad(inPlace)[0] != &inPlace[0] {
+			t.Errorf("[%d] Pad reallocated a buffer with enough capacity", n)
+		}
+	}
+}
+
+func TestUnpadErrors(t *testing.T) {
+	t.Parallel()
+
+	padded := Pad(bytes.Repeat([]byte{1}, 9))
+	bad := []struct {
+		buf []byte
+		n   int
+	}{
+		{padded, 8},
+		{padded, -1},
+		{padded[:9], 9},
+		{append(bytes.Clone(padded[:9]), 1), 9},
+	}
+	for i, test := range bad {
+		if _, err := Unpad(test.buf, test.n); !errors.Is(err, pkcs7pad.ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
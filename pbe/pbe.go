@@ -0,0 +1,132 @@
+// Package pbe implements password-based encryption of small files, such as
+// configuration files, using PBKDF2, AES-256 in CBC mode with PKCS#7 padding,
+// and HMAC-SHA256.
+//
+// An encrypted file consists of a version byte, a 16-byte random salt, the
+// PBKDF2 iteration count as a 32-bit big-endian integer, a 16-byte random
+// initialization vector, the ciphertext, and a 32-byte HMAC tag. PBKDF2 with
+// SHA-256 derives a 64-byte key from the passphrase and salt: the first half
+// is the AES key and the second half is the HMAC key. The file is sealed with
+// the cbchmac AEAD, with everything before the initialization vector as the
+// additional data, so the tag covers the whole file and is checked before
+// anything is decrypted.
+//
+// The whole file is held in memory, so the format is not suited to large files.
+package pbe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zenazn/pkcs7pad/cbchmac"
+)
+
+const (
+	version    = 1
+	saltSize   = 16
+	headerSize = 1 + saltSize + 4
+	keySize    = 32
+
+	// DefaultIter is the PBKDF2 iteration count used by Encrypt when none is
+	// given, following the OWASP recommendation for PBKDF2-HMAC-SHA256.
+	DefaultIter = 600000
+
+	// MaxIter is the largest iteration count Decrypt accepts, so that a
+	// crafted file can't make it run for an unbounded amount of time.
+	MaxIter = 1 << 24
+)
+
+var (
+	// ErrFormat is returned by Decrypt when its input is not an encrypted
+	// file in a version of the format it understands.
+	ErrFormat = errors.New("pbe: not a password-encrypted file")
+
+	// ErrDecrypt is returned by Decrypt when the passphrase is wrong or the
+	// file has been modified. The two cases are deliberately
+	// indistinguishable.
+	ErrDecrypt = errors.New("pbe: wrong passphrase or corrupt file")
+)
+
+// Options configure Encrypt. A nil *Options is equivalent to the zero Options.
+type Options struct {
+	// Iter is the PBKDF2 iteration count. If zero, DefaultIter is used.
+	Iter int
+
+	// Rand is the source of the salt and initialization vector. If nil,
+	// crypto/rand.Reader is used.
+	Rand io.Reader
+}
+
+func newAEAD(passphrase string, salt []byte, iter int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iter, 2*keySize)
+	if err != nil {
+		return nil, err
+	}
+	b, err := aes.NewCipher(key[:keySize])
+	if err != nil {
+		return nil, err
+	}
+	return cbchmac.New(b, key[keySize:], sha256.New, sha256.Size)
+}
+
+// Encrypt encrypts plaintext with the given passphrase.
+func Encrypt(passphrase string, plaintext []byte, opts *Options) ([]byte, error) {
+	iter, r := DefaultIter, io.Reader(rand.Reader)
+	if opts != nil && opts.Iter != 0 {
+		iter = opts.Iter
+	}
+	if opts != nil && opts.Rand != nil {
+		r = opts.Rand
+	}
+	if iter < 1 || iter > MaxIter {
+		return nil, fmt.Errorf("pbe: iteration count %d must be between 1 and %d", iter, MaxIter)
+	}
+
+	out := make([]byte, headerSize+aes.BlockSize)
+	out[0] = version
+	salt, iv := out[1:1+saltSize], out[headerSize:]
+	binary.BigEndian.PutUint32(out[1+saltSize:], uint32(iter))
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, iv); err != nil {
+		return nil, err
+	}
+	a, err := newAEAD(passphrase, salt, iter)
+	if err != nil {
+		return nil, err
+	}
+	return a.Seal(out, iv, plaintext, out[:headerSize]), nil
+}
+
+// Decrypt decrypts a file produced by Encrypt with the given passphrase. It
+// returns ErrFormat if data is not in a known version of the format, and
+// ErrDecrypt if the passphrase is wrong or data has been modified.
+func Decrypt(passphrase string, data []byte) ([]byte, error) {
+	if len(data) < headerSize+aes.BlockSize || data[0] != version {
+		return nil, ErrFormat
+	}
+	salt := data[1 : 1+saltSize]
+	iter := binary.BigEndian.Uint32(data[1+saltSize:])
+	if iter < 1 || iter > MaxIter {
+		return nil, ErrFormat
+	}
+	iv, ct := data[headerSize:headerSize+aes.BlockSize], data[headerSize+aes.BlockSize:]
+
+	a, err := newAEAD(passphrase, salt, int(iter))
+	if err != nil {
+		return nil, err
+	}
+	pt, err := a.Open(nil, iv, ct, data[:headerSize])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return pt, nil
+}
@@ -0,0 +1,85 @@
+package pbe
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// testOpts keeps the tests fast; real files should use DefaultIter.
+var testOpts = &Options{Iter: 1000}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte("db_password = hunter2\n")
+	for n := 0; n <= len(msg); n++ {
+		data, err := Encrypt("correct horse", msg[:n], testOpts)
+		if err != nil {
+			t.Fatalf("[%d] Encrypt: %v", n, err)
+		}
+		out, err := Decrypt("correct horse", data)
+		if err != nil || !bytes.Equal(out, msg[:n]) {
+			t.Errorf("[%d] Decrypt = %q, %v", n, out, err)
+		}
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	t.Parallel()
+
+	opts := &Options{Iter: 1000, Rand: bytes.NewReader(make([]byte, 32))}
+	data, err := Encrypt("pass", []byte("hello"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != headerSize+16+16+32 {
+		t.Errorf("len = %d", len(data))
+	}
+	if data[0] != version || !bytes.Equal(data[1+saltSize:headerSize], []byte{0, 0, 0x03, 0xe8}) {
+		t.Errorf("bad header %x", data[:headerSize])
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	t.Parallel()
+
+	data, err := Encrypt("pass", []byte("hello, world"), testOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt("wrong", data); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("wrong passphrase: %v", err)
+	}
+	for i := range data {
+		if i == 0 || (i >= 1+saltSize && i < headerSize) {
+			// The version and iteration count are checked separately.
+			continue
+		}
+		bad := bytes.Clone(data)
+		bad[i] ^= 1
+		if _, err := Decrypt("pass", bad); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("[%d] flipped byte: %v", i, err)
+		}
+	}
+	if _, err := Decrypt("pass", data[:len(data)-1]); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("truncated: %v", err)
+	}
+
+	bad := bytes.Clone(data)
+	bad[0] = 2
+	if _, err := Decrypt("pass", bad); !errors.Is(err, ErrFormat) {
+		t.Errorf("bad version: %v", err)
+	}
+	bad = bytes.Clone(data)
+	bad[1+saltSize] = 0xff
+	if _, err := Decrypt("pass", bad); !errors.Is(err, ErrFormat) {
+		t.Errorf("huge iteration count: %v", err)
+	}
+	if _, err := Decrypt("pass", data[:headerSize]); !errors.Is(err, ErrFormat) {
+		t.Errorf("short: %v", err)
+	}
+	if _, err := Encrypt("pass", nil, &Options{Iter: -1}); err == nil {
+		t.Error("expected error for negative iteration count")
+	}
+}
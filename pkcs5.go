@@ -0,0 +1,50 @@
+package pkcs7pad
+
+import "fmt"
+
+// pkcs5BlockSize is the only block size PKCS#5 padding is defined for.
+const pkcs5BlockSize = 8
+
+// Pad5 appends PKCS#5 padding to buf, as defined in RFC 8018, section 6.1.1.
+// PKCS#5 padding is PKCS#7 padding for a block size of 8, as used by DES and
+// other 64-bit block ciphers. Many specifications say "PKCS#5 padding" when
+// they mean PKCS#7; if the block size is not 8, use Pad instead.
+//
+// https://tools.ietf.org/html/rfc8018#section-6.1.1
+func Pad5(buf []byte) []byte {
+	return pad(buf, pkcs5BlockSize)
+}
+
+// Unpad5 removes PKCS#5 padding from buf. It is equivalent to UnpadBlock with
+// a block size of 8.
+func Unpad5(buf []byte) ([]byte, error) {
+	return unpadBlock(buf, pkcs5BlockSize)
+}
+
+type pkcs5 struct{}
+
+func (pkcs5) Name() string { return "pkcs5" }
+
+func (pkcs5) Pad(buf []byte, size int) []byte {
+	if err := checkPKCS5Size(size); err != nil {
+		panic(err.Error())
+	}
+	return Pad5(buf)
+}
+
+func (pkcs5) Unpad(buf []byte, size int) ([]byte, error) {
+	if err := checkPKCS5Size(size); err != nil {
+		return nil, err
+	}
+	return Unpad5(buf)
+}
+
+func checkPKCS5Size(size int) error {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if size != pkcs5BlockSize {
+		return fmt.Errorf("%w %d: PKCS#5 padding requires a block size of 8", ErrBlockSize, size)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPad5(t *testing.T) {
+	t.Parallel()
+
+	for n := 0; n < 24; n++ {
+		msg := bytes.Repeat([]byte{0xa5}, n)
+		padded := Pad5(bytes.Clone(msg))
+		if want := Pad(bytes.Clone(msg), 8); !bytes.Equal(padded, want) {
+			t.Errorf("[%d] %x != %x", n, padded, want)
+		}
+		if out, err := Unpad5(padded); err != nil || !bytes.Equal(out, msg) {
+			t.Errorf("[%d] Unpad5 = %x, %v", n, out, err)
+		}
+		if out, err := PKCS5.Unpad(PKCS5.Pad(bytes.Clone(msg), 8), 8); err != nil || !bytes.Equal(out, msg) {
+			t.Errorf("[%d] PKCS5.Unpad = %x, %v", n, out, err)
+		}
+	}
+	if _, err := Unpad5(Pad(nil, 16)); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding for 16-byte padding, got %v", err)
+	}
+}
+
+func TestPKCS5BlockSize(t *testing.T) {
+	t.Parallel()
+
+	if _, err := PKCS5.Unpad(Pad(nil, 16), 16); !errors.Is(err, ErrBlockSize) {
+		t.Errorf("expected ErrBlockSize, got %v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Pad with a block size of 16 did not panic")
+			}
+		}()
+		PKCS5.Pad(nil, 16)
+	}()
+}
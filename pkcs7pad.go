@@ -1,35 +1,267 @@
 // Package pkcs7pad implements PKCS#7 padding, as defined in RFC 5652.
 //
 // https://tools.ietf.org/html/rfc5652#section-6.3
+//
+// The package also implements several other block cipher padding schemes
+// (ANSI X9.23, ISO/IEC 7816-4, ISO 10126, ISO/IEC 9797-1, trailing bit
+// complement, zero padding, and no padding) for interoperability with other
+// systems. Each scheme is available both as a pair of functions and as a
+// Scheme, which can be selected by name using Lookup.
 package pkcs7pad
 
 import (
-	"bytes"
 	"crypto/subtle"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 )
 
-var errPKCS7Padding = errors.New("pkcs7pad: bad padding")
+// ErrBadPadding is returned by every Unpad function and Scheme in this package
+// when the padding is malformed. Errors that carry more detail wrap it, so
+// callers should test for it with errors.Is.
+//
+// To avoid creating a padding oracle, callers decrypting attacker-controlled
+// ciphertext should take care not to reveal this error, or the time at which
+// it was returned, to the attacker.
+var ErrBadPadding = errors.New("pkcs7pad: bad padding")
+
+// ErrBlockSize is returned by functions that validate a block size instead of
+// panicking when it is not between 1 and 255.
+var ErrBlockSize = errors.New("pkcs7pad: inappropriate block size")
+
+var errCapacity = errors.New("pkcs7pad: insufficient capacity for padding")
+
+func errBlockSize(size int) error {
+	return fmt.Errorf("%w %d", ErrBlockSize, size)
+}
+
+// errMisaligned wraps ErrBadPadding for buffers whose length is not a multiple
+// of the block size. The length of a buffer is public, so it's fine to report.
+func errMisaligned(n int64, size int) error {
+	return fmt.Errorf("%w: length %d is not a multiple of block size %d", ErrBadPadding, n, size)
+}
 
 // Pad appends PKCS#7 padding to the given buffer such that the resulting slice
 // of bytes has a length divisible by the given size. If you are using this
 // function to pad a plaintext before encrypting it with a block cipher, the
 // size should be equal to the block size of the cipher (e.g., aes.BlockSize).
+//
+// If buf has enough spare capacity, the padding is written into it in place.
+// Otherwise, Pad copies buf into a new array whose capacity is exactly the
+// padded length.
 func Pad(buf []byte, size int) []byte {
+	buf, err := PadErr(buf, size)
+	if err != nil {
+		panic(err.Error())
+	}
+	return buf
+}
+
+// PadErr is like Pad, but it returns an error wrapping ErrBlockSize instead of
+// panicking if the block size is not between 1 and 255. It is intended for
+// servers that take the block size from configuration.
+func PadErr(buf []byte, size int) ([]byte, error) {
+	if size < 1 || size > 255 {
+		return nil, errBlockSize(size)
+	}
+	return pad(buf, size), nil
+}
+
+// pad implements Pad for a block size that has already been validated.
+func pad(buf []byte, size int) []byte {
+	i := size - (len(buf) % size)
+	if cap(buf)-len(buf) < i {
+		// Grow exactly once, to exactly the padded length, rather than
+		// leaving the choice to append's growth heuristics.
+		grown := make([]byte, len(buf), len(buf)+i)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:len(buf)+i]
+	switch size {
+	case 16:
+		pad16((*[16]byte)(buf[len(buf)-16:]), i)
+	case 8:
+		pad8((*[8]byte)(buf[len(buf)-8:]), i)
+	default:
+		for j := len(buf) - i; j < len(buf); j++ {
+			buf[j] = byte(i)
+		}
+	}
+	return buf
+}
+
+// AppendPad appends src followed by its PKCS#7 padding to dst and returns the
+// extended buffer. The padding is computed from the length of src alone, so dst
+// may already hold unrelated data, such as a record header. If dst has enough
+// spare capacity, AppendPad does not allocate; otherwise it grows dst exactly
+// once.
+func AppendPad(dst, src []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	i := size - (len(src) % size)
+	dst = slices.Grow(dst, len(src)+i)
+	dst = append(dst, src...)
+	for j := 0; j < i; j++ {
+		dst = append(dst, byte(i))
+	}
+	return dst
+}
+
+// PadLen returns the number of bytes of PKCS#7 padding that Pad would append to
+// a buffer of length n. It is always between 1 and size. Like Pad, it panics if
+// size is not between 1 and 255.
+func PadLen(n, size int) int {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return size - (n % size)
+}
+
+// PaddedLen returns the length of a buffer of length n after Pad has been
+// applied to it, for preallocating output buffers or computing wire sizes.
+func PaddedLen(n, size int) int {
+	return n + PadLen(n, size)
+}
+
+// PadExact appends PKCS#7 padding to buf such that the result is exactly target
+// bytes long, for protocols that dictate the padded size directly rather than
+// a block size. It returns an error if target is not between len(buf)+1 and
+// len(buf)+255, since PKCS#7 can't express any other amount of padding. The
+// result can be unpadded with Unpad.
+func PadExact(buf []byte, target int) ([]byte, error) {
+	i := target - len(buf)
+	if i < 1 || i > 255 {
+		return nil, fmt.Errorf("pkcs7pad: can't pad %d bytes to %d with PKCS#7", len(buf), target)
+	}
+	buf = slices.Grow(buf, i)
+	for j := 0; j < i; j++ {
+		buf = append(buf, byte(i))
+	}
+	return buf, nil
+}
+
+// PadInPlace is like Pad, but it never allocates: the padding is written into the
+// spare capacity of buf, and an error is returned if there is not enough of it.
+// A buffer with a capacity of at least len(buf)+size always has enough room.
+func PadInPlace(buf []byte, size int) ([]byte, error) {
 	if size < 1 || size > 255 {
 		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
 	}
 	i := size - (len(buf) % size)
-	return append(buf, bytes.Repeat([]byte{byte(i)}, i)...)
+	if cap(buf)-len(buf) < i {
+		return nil, errCapacity
+	}
+	buf = buf[:len(buf)+i]
+	switch size {
+	case 16:
+		pad16((*[16]byte)(buf[len(buf)-16:]), i)
+	case 8:
+		pad8((*[8]byte)(buf[len(buf)-8:]), i)
+	default:
+		for j := len(buf) - i; j < len(buf); j++ {
+			buf[j] = byte(i)
+		}
+	}
+	return buf, nil
 }
 
 // Unpad returns a subslice of the input buffer with trailing PKCS#7 padding
 // removed. It checks the correctness of the padding bytes in constant time, and
 // returns an error if the padding bytes are malformed.
 func Unpad(buf []byte) ([]byte, error) {
+	padLen, good := checkPadding(buf)
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+
+	return buf[:len(buf)-padLen], nil
+}
+
+// UnpadBlock is a stricter variant of Unpad for when the block size is known.
+// In addition to the checks Unpad performs, it returns an error if the length
+// of buf is not a non-zero multiple of size, or if the padding is longer than a
+// single block. Only the final block is scanned, so UnpadBlock is faster than
+// Unpad for small block sizes.
+func UnpadBlock(buf []byte, size int) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return unpadBlock(buf, size)
+}
+
+// unpadBlock implements UnpadBlock for a block size that has already been
+// validated.
+func unpadBlock(buf []byte, size int) ([]byte, error) {
+	if len(buf)%size != 0 {
+		return nil, errMisaligned(int64(len(buf)), size)
+	}
 	if len(buf) == 0 {
-		return nil, errPKCS7Padding
+		return nil, ErrBadPadding
+	}
+	var padLen, good int
+	switch size {
+	case 16:
+		padLen, good = checkPadding16((*[16]byte)(buf[len(buf)-16:]))
+	case 8:
+		padLen, good = checkPadding8((*[8]byte)(buf[len(buf)-8:]))
+	default:
+		padLen, good = checkPadding(buf[len(buf)-size:])
+	}
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+
+	return buf[:len(buf)-padLen], nil
+}
+
+// UnpadLen returns the length of the input buffer with trailing PKCS#7 padding
+// removed, leaving the buffer itself untouched. It performs the same
+// constant-time validation as Unpad, and returns an error if the padding bytes
+// are malformed.
+func UnpadLen(buf []byte) (int, error) {
+	padLen, good := checkPadding(buf)
+	if good != 1 {
+		return 0, ErrBadPadding
+	}
+
+	return len(buf) - padLen, nil
+}
+
+// UnpadWithLen is like Unpad, but it also returns the number of padding bytes
+// that were removed, for protocols that need to account for them. The padding
+// is validated in constant time, exactly as in Unpad.
+func UnpadWithLen(buf []byte) ([]byte, int, error) {
+	padLen, good := checkPadding(buf)
+	if good != 1 {
+		return nil, 0, ErrBadPadding
+	}
+
+	return buf[:len(buf)-padLen], padLen, nil
+}
+
+// UnpadZeroize is like Unpad, but after validating the padding it also
+// overwrites the padding bytes with zeros in the underlying array, so that they
+// do not linger in buffers that are later reused. Malformed input is left
+// untouched.
+func UnpadZeroize(buf []byte) ([]byte, error) {
+	padLen, good := checkPadding(buf)
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+
+	clear(buf[len(buf)-padLen:])
+	return buf[:len(buf)-padLen], nil
+}
+
+// checkPadding returns the padding length claimed by the final byte of buf,
+// along with 1 if the padding is well-formed and 0 otherwise.
+func checkPadding(buf []byte) (int, int) {
+	if len(buf) == 0 {
+		return 0, 0
 	}
 
 	// Here be dragons. We're attempting to check the padding in constant
@@ -37,25 +269,65 @@ func Unpad(buf []byte) ([]byte, error) {
 	// This code is modeled loosely after tls1_cbc_remove_padding from
 	// OpenSSL.
 	padLen := buf[len(buf)-1]
-	toCheck := 255
-	good := 1
-	if toCheck > len(buf) {
-		toCheck = len(buf)
-	}
-	for i := 0; i < toCheck; i++ {
-		b := buf[len(buf)-1-i]
-
-		outOfRange := subtle.ConstantTimeLessOrEq(int(padLen), i)
-		equal := subtle.ConstantTimeByteEq(padLen, b)
-		good &= subtle.ConstantTimeSelect(outOfRange, 1, equal)
-	}
+	good := checkPaddingBytes(buf, padLen)
 
 	good &= subtle.ConstantTimeLessOrEq(1, int(padLen))
 	good &= subtle.ConstantTimeLessOrEq(int(padLen), len(buf))
 
-	if good != 1 {
-		return nil, errPKCS7Padding
+	return int(padLen), good
+}
+
+// checkPaddingGeneric returns 1 if each of the last padLen bytes of buf (or the
+// last 255 bytes, if that's fewer) is equal to padLen, and 0 otherwise. It
+// does not check that padLen itself is in range. Platforms with a faster
+// implementation provide it as checkPaddingBytes.
+//
+// The bytes are examined eight at a time. Which words are loaded depends only
+// on len(buf), and the padding is selected from each word with a mask computed
+// without branches.
+func checkPaddingGeneric(buf []byte, padLen byte) int {
+	toCheck := min(255, len(buf))
+	tail := buf[len(buf)-toCheck:]
+	pattern := uint64(padLen) * 0x0101010101010101
+
+	var diff uint64
+	for i := 0; i < toCheck; i += 8 {
+		// w holds the bytes at offsets i through i+7 from the end of buf
+		// XORed with padLen, with offset i in the most significant byte.
+		// Offsets before the start of buf are left as zero.
+		end := toCheck - i
+		var w uint64
+		if end >= 8 {
+			w = binary.LittleEndian.Uint64(tail[end-8:end]) ^ pattern
+		} else {
+			for j := 0; j < end; j++ {
+				w |= uint64(tail[j]^padLen) << (8 * (8 - end + j))
+			}
+		}
+
+		diff |= w & paddingMask(int(padLen), i)
 	}
+	return int((diff|-diff)>>63) ^ 1
+}
+
+// paddingMask returns a mask selecting the bytes that are part of padLen bytes
+// of padding from a word holding the bytes at offsets i through i+7 from the
+// end of a buffer, with offset i in the most significant byte. It is computed
+// without branches.
+func paddingMask(padLen, i int) uint64 {
+	// n = min(max(padLen-i, 0), 8) is the number of bytes of the word that
+	// are part of the padding.
+	n := padLen - i
+	n &^= n >> (strconv.IntSize - 1)
+	m := n - 8
+	n = 8 + (m & (m >> (strconv.IntSize - 1)))
+	return ^(^uint64(0) >> (8 * uint(n)))
+}
+
+type pkcs7 struct{}
 
-	return buf[:len(buf)-int(padLen)], nil
+func (pkcs7) Name() string                    { return "pkcs7" }
+func (pkcs7) Pad(buf []byte, size int) []byte { return Pad(buf, size) }
+func (pkcs7) Unpad(buf []byte, size int) ([]byte, error) {
+	return UnpadBlock(buf, size)
 }
@@ -3,6 +3,8 @@ package pkcs7pad
 import (
 	"bytes"
 	"crypto/aes"
+	"crypto/subtle"
+	"errors"
 	"testing"
 	"testing/quick"
 )
@@ -98,6 +100,159 @@ func TestPad(t *testing.T) {
 	}
 }
 
+func TestPadErr(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		buf := make([]byte, len(test.in))
+		copy(buf, test.in)
+		pad, err := PadErr(buf, aes.BlockSize)
+		if err != nil {
+			t.Errorf("[%d] error padding: %v", i, err)
+		}
+		if !bytes.Equal(pad, test.out) {
+			t.Errorf("[%d] %x != %x", i, pad, test.out)
+		}
+	}
+	for _, size := range []int{-1, 0, 256} {
+		if _, err := PadErr(nil, size); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[%d] expected ErrBlockSize, got %v", size, err)
+		}
+	}
+}
+
+func TestPadPanics(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r != "pkcs7pad: inappropriate block size 256" {
+			t.Errorf("unexpected panic %v", r)
+		}
+	}()
+	Pad(nil, 256)
+}
+
+func TestAppendPad(t *testing.T) {
+	t.Parallel()
+
+	header := []byte{0xff, 0xfe}
+	for i, test := range PadTests {
+		out := AppendPad(header[:len(header):len(header)], test.in, aes.BlockSize)
+		want := append(append([]byte(nil), header...), test.out...)
+		if !bytes.Equal(out, want) {
+			t.Errorf("[%d] %x != %x", i, out, want)
+		}
+	}
+}
+
+func TestPadCapacity(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		out := Pad(test.in[:len(test.in):len(test.in)], aes.BlockSize)
+		if cap(out) != len(test.out) {
+			t.Errorf("[%d] grew to capacity %d, want %d", i, cap(out), len(test.out))
+		}
+
+		buf := make([]byte, len(test.in), len(test.out)+5)
+		copy(buf, test.in)
+		out = Pad(buf, aes.BlockSize)
+		if !bytes.Equal(out, test.out) {
+			t.Errorf("[%d] %x != %x", i, out, test.out)
+		}
+		if &out[0] != &buf[:1][0] {
+			t.Errorf("[%d] padding was not done in place", i)
+		}
+	}
+}
+
+func TestPadAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		Pad(testString[:7:7], aes.BlockSize)
+	})
+	if allocs != 1 {
+		t.Errorf("Pad allocated %v times", allocs)
+	}
+}
+
+func TestAppendPadAllocs(t *testing.T) {
+	dst := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		AppendPad(dst, testString[:7], aes.BlockSize)
+	})
+	if allocs != 0 {
+		t.Errorf("AppendPad allocated %v times", allocs)
+	}
+}
+
+func TestPaddedLen(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		if n := PadLen(len(test.in), aes.BlockSize); n != len(test.out)-len(test.in) {
+			t.Errorf("[%d] PadLen %d != %d", i, n, len(test.out)-len(test.in))
+		}
+		if n := PaddedLen(len(test.in), aes.BlockSize); n != len(test.out) {
+			t.Errorf("[%d] PaddedLen %d != %d", i, n, len(test.out))
+		}
+	}
+	for size := 1; size <= 255; size++ {
+		for n := 0; n < 3*size; n++ {
+			if got, want := PaddedLen(n, size), len(Pad(make([]byte, n), size)); got != want {
+				t.Errorf("[%d/%d] PaddedLen %d != %d", n, size, got, want)
+			}
+		}
+	}
+}
+
+func TestPadInPlace(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		buf := make([]byte, len(test.in), len(test.out))
+		copy(buf, test.in)
+		pad, err := PadInPlace(buf, aes.BlockSize)
+		if err != nil {
+			t.Errorf("[%d] error padding: %v", i, err)
+		}
+		if !bytes.Equal(pad, test.out) {
+			t.Errorf("[%d] %x != %x", i, pad, test.out)
+		}
+		if &pad[0] != &buf[:1][0] {
+			t.Errorf("[%d] padding was not done in place", i)
+		}
+
+		if _, err := PadInPlace(buf[:len(test.in):len(test.out)-1], aes.BlockSize); err != errCapacity {
+			t.Errorf("[%d] expected %v, got %v", i, errCapacity, err)
+		}
+	}
+}
+
+func TestPadExact(t *testing.T) {
+	t.Parallel()
+
+	for n := 0; n < 40; n++ {
+		for target := n + 1; target <= n+255; target += 7 {
+			msg := bytes.Repeat([]byte{0xa5}, n)
+			padded, err := PadExact(bytes.Clone(msg), target)
+			if err != nil {
+				t.Fatalf("[%d/%d] error padding: %v", n, target, err)
+			}
+			if len(padded) != target {
+				t.Fatalf("[%d/%d] padded to %d bytes", n, target, len(padded))
+			}
+			if out, err := Unpad(padded); err != nil || !bytes.Equal(out, msg) {
+				t.Fatalf("[%d/%d] Unpad = %x, %v", n, target, out, err)
+			}
+		}
+	}
+	for i, target := range []int{-1, 15, 16, 16 + 256} {
+		if _, err := PadExact(testString, target); err == nil {
+			t.Errorf("[%d] expected an error padding to %d bytes", i, target)
+		}
+	}
+}
+
 func TestUnpad(t *testing.T) {
 	t.Parallel()
 
@@ -115,6 +270,121 @@ func TestUnpad(t *testing.T) {
 	}
 }
 
+func TestUnpadBlock(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		unpad, err := UnpadBlock(test.out, aes.BlockSize)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+
+	bad := [][]byte{
+		{},
+		PadTests[1].out[1:],
+		bytes.Repeat([]byte{0x11}, 32),
+		append(testString[:16:16], PadTests[0].out[1:]...),
+	}
+	for i, test := range bad {
+		if _, err := UnpadBlock(test, aes.BlockSize); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+}
+
+func TestUnpadBlockBlackBox(t *testing.T) {
+	t.Parallel()
+
+	f := func(buf []byte, size uint8) bool {
+		if size == 0 {
+			size = 1
+		}
+		buf = buf[:len(buf)-len(buf)%int(size)]
+		got, err := UnpadBlock(buf, int(size))
+		want, wantErr := completelyUnsafeNotConstantTimeUnpad(buf)
+		if wantErr == nil && len(buf)-len(want) > int(size) {
+			wantErr = ErrBadPadding
+		}
+		if err != nil || wantErr != nil {
+			return errors.Is(err, wantErr)
+		}
+		return bytes.Equal(got, want)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnpadLen(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		n, err := UnpadLen(test.out)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if n != len(test.in) {
+			t.Errorf("[%d] %d != %d", i, n, len(test.in))
+		}
+	}
+	for i, test := range BadPadTests {
+		if _, err := UnpadLen(test); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+}
+
+func TestUnpadWithLen(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		unpad, padLen, err := UnpadWithLen(test.out)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+		if padLen != len(test.out)-len(test.in) {
+			t.Errorf("[%d] padLen %d != %d", i, padLen, len(test.out)-len(test.in))
+		}
+	}
+	for i, test := range BadPadTests {
+		if _, padLen, err := UnpadWithLen(test); err != ErrBadPadding || padLen != 0 {
+			t.Errorf("[%d] expected ErrBadPadding, got %d, %v", i, padLen, err)
+		}
+	}
+}
+
+func TestUnpadZeroize(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		buf := make([]byte, len(test.out))
+		copy(buf, test.out)
+
+		unpad, err := UnpadZeroize(buf)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+		if tail := buf[len(unpad):]; !bytes.Equal(tail, make([]byte, len(tail))) {
+			t.Errorf("[%d] padding not wiped: %x", i, tail)
+		}
+	}
+	for i, test := range BadPadTests {
+		if _, err := UnpadZeroize(test); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+}
+
 var BadPadTests = [][]byte{
 	{0x04, 0x04, 0x04},
 	{0xde, 0xad, 0xbe, 0xef, 0x03, 0x02, 0x03},
@@ -126,29 +396,118 @@ func TestUnpadErrors(t *testing.T) {
 
 	for i, test := range BadPadTests {
 		_, err := Unpad(test)
-		if err != errPKCS7Padding {
+		if !errors.Is(err, ErrBadPadding) {
 			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
 		}
 	}
 }
 
+func TestErrorsIs(t *testing.T) {
+	t.Parallel()
+
+	errs := []error{}
+	for _, s := range []Scheme{PKCS7, X923, ISO7816, ISO10126, Zero, None, ISO9797M1, ISO9797M2, TBC} {
+		_, err := s.Unpad(testString[:3], aes.BlockSize)
+		errs = append(errs, err)
+	}
+	_, err := Unpad(nil)
+	errs = append(errs, err)
+	_, err = UnpadBlock(testString, aes.BlockSize)
+	errs = append(errs, err)
+
+	for i, err := range errs {
+		if !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] %v is not ErrBadPadding", i, err)
+		}
+	}
+}
+
 func completelyUnsafeNotConstantTimeUnpad(buf []byte) ([]byte, error) {
 	if len(buf) == 0 {
-		return nil, errPKCS7Padding
+		return nil, ErrBadPadding
 	}
 	padLen := buf[len(buf)-1]
 	if int(padLen) > len(buf) || padLen == 0 {
-		return nil, errPKCS7Padding
+		return nil, ErrBadPadding
 	}
 
 	out, padding := buf[:len(buf)-int(padLen)], buf[len(buf)-int(padLen):]
 	rep := bytes.Repeat([]byte{padLen}, int(padLen))
 	if !bytes.Equal(padding, rep) {
-		return nil, errPKCS7Padding
+		return nil, ErrBadPadding
 	}
 	return out, nil
 }
 
+// checkPaddingBytewise is the original byte-at-a-time implementation of
+// checkPaddingGeneric, kept as a reference.
+func checkPaddingBytewise(buf []byte, padLen byte) int {
+	toCheck := min(255, len(buf))
+	good := 1
+	for i := 0; i < toCheck; i++ {
+		b := buf[len(buf)-1-i]
+
+		outOfRange := subtle.ConstantTimeLessOrEq(int(padLen), i)
+		equal := subtle.ConstantTimeByteEq(padLen, b)
+		good &= subtle.ConstantTimeSelect(outOfRange, 1, equal)
+	}
+	return good
+}
+
+func TestCheckPaddingBytes(t *testing.T) {
+	t.Parallel()
+
+	agree := func(buf []byte, padLen byte) bool {
+		want := checkPaddingBytewise(buf, padLen)
+		if checkPaddingGeneric(buf, padLen) != want {
+			return false
+		}
+		// The vector implementations may differ when checkPadding would
+		// reject padLen anyway.
+		if padLen == 0 || int(padLen) > len(buf) {
+			return true
+		}
+		return checkPaddingBytes(buf, padLen) == want
+	}
+	f := func(buf []byte, padLen byte) bool {
+		if len(buf) > 0 {
+			padLen = byte(1 + int(padLen)%min(len(buf), 255))
+		}
+		return agree(buf, padLen)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+	if err := quick.Check(agree, nil); err != nil {
+		t.Error(err)
+	}
+	for n := 1; n <= 300; n++ {
+		for _, padLen := range []byte{1, 16, byte(n), 254, 255} {
+			buf := bytes.Repeat([]byte{padLen}, n)
+			if !agree(buf, padLen) {
+				t.Errorf("[%d/%d] disagreement", n, padLen)
+			}
+			for _, i := range []int{0, int(padLen) - 1, int(padLen)} {
+				if i < 0 || i >= n {
+					continue
+				}
+				buf[len(buf)-1-i] ^= 0x01
+				if !agree(buf, padLen) {
+					t.Errorf("[%d/%d] disagreement with byte %d corrupted", n, padLen, i)
+				}
+				buf[len(buf)-1-i] ^= 0x01
+			}
+		}
+	}
+}
+
+func BenchmarkUnpad(b *testing.B) {
+	buf := Pad(bytes.Repeat(testString, 4), aes.BlockSize)
+	for i := 0; i < b.N; i++ {
+		Unpad(buf)
+	}
+}
+
 func TestUnpadBlackBox(t *testing.T) {
 	t.Parallel()
 	err := quick.CheckEqual(Unpad, completelyUnsafeNotConstantTimeUnpad, nil)
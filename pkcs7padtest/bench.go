@@ -0,0 +1,53 @@
+package pkcs7padtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// benchBlockSizes and benchLengths are the block sizes and message lengths
+// measured by BenchmarkScheme.
+var (
+	benchBlockSizes = []int{8, 16, 255}
+	benchLengths    = []int{15, 1024, 64 << 10}
+)
+
+// BenchmarkScheme benchmarks a padding scheme implementation. It runs Pad and
+// Unpad as sub-benchmarks named like "Pad/size=16/len=1024", for a range of
+// block sizes and message lengths, reporting throughput in terms of the
+// message length and allocations per operation. The names are the same for
+// every scheme, so results for different schemes can be compared with a tool
+// like benchstat.
+//
+// Pad is given a buffer with enough spare capacity for the padding, so an
+// implementation that pads in place does not allocate.
+func BenchmarkScheme(b *testing.B, s pkcs7pad.Scheme) {
+	for _, size := range benchBlockSizes {
+		for _, n := range benchLengths {
+			msg := make([]byte, n, n+size)
+			for i := range msg {
+				msg[i] = byte(i%255 + 1)
+			}
+			padded := s.Pad(msg[:n:n], size)
+
+			b.Run(fmt.Sprintf("Pad/size=%d/len=%d", size, n), func(b *testing.B) {
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					s.Pad(msg[:n], size)
+				}
+			})
+			b.Run(fmt.Sprintf("Unpad/size=%d/len=%d", size, n), func(b *testing.B) {
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := s.Unpad(padded, size); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package pkcs7padtest
+
+import (
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func BenchmarkBuiltinSchemes(b *testing.B) {
+	for _, name := range pkcs7pad.Schemes() {
+		if name == "none" {
+			// None can't pad unaligned messages.
+			continue
+		}
+		s, err := pkcs7pad.Lookup(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name, func(b *testing.B) {
+			BenchmarkScheme(b, s)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package pkcs7padtest
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// A Fake is a pkcs7pad.Scheme for testing code that uses one. It records every
+// call made to it, and can be told to fail. By default it behaves exactly like
+// pkcs7pad.PKCS7.
+//
+// A Fake is safe for concurrent use, but its fields must not be changed while
+// it is in use.
+type Fake struct {
+	// SchemeName is returned by Name. If empty, "fake" is used.
+	SchemeName string
+
+	// Scheme implements the calls that are not overridden below. If nil,
+	// pkcs7pad.PKCS7 is used.
+	Scheme pkcs7pad.Scheme
+
+	// PadFunc and UnpadFunc, if non-nil, replace Pad and Unpad entirely.
+	PadFunc   func(buf []byte, size int) []byte
+	UnpadFunc func(buf []byte, size int) ([]byte, error)
+
+	// UnpadErr, if non-nil, is returned by every call to Unpad, without
+	// consulting UnpadFunc or Scheme. A realistic choice is
+	// pkcs7pad.ErrBadPadding.
+	UnpadErr error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// A Call is a call made to a Fake.
+type Call struct {
+	// Method is "Pad" or "Unpad".
+	Method string
+	// Buf is a copy of the buffer that was passed in.
+	Buf  []byte
+	Size int
+	// Out and Err are what the call returned.
+	Out []byte
+	Err error
+}
+
+var _ pkcs7pad.Scheme = (*Fake)(nil)
+
+func (f *Fake) scheme() pkcs7pad.Scheme {
+	if f.Scheme == nil {
+		return pkcs7pad.PKCS7
+	}
+	return f.Scheme
+}
+
+// Name implements pkcs7pad.Scheme.
+func (f *Fake) Name() string {
+	if f.SchemeName == "" {
+		return "fake"
+	}
+	return f.SchemeName
+}
+
+// Pad implements pkcs7pad.Scheme.
+func (f *Fake) Pad(buf []byte, size int) []byte {
+	in := bytes.Clone(buf)
+	var out []byte
+	if f.PadFunc != nil {
+		out = f.PadFunc(buf, size)
+	} else {
+		out = f.scheme().Pad(buf, size)
+	}
+	f.record(Call{Method: "Pad", Buf: in, Size: size, Out: out})
+	return out
+}
+
+// Unpad implements pkcs7pad.Scheme.
+func (f *Fake) Unpad(buf []byte, size int) ([]byte, error) {
+	in := bytes.Clone(buf)
+	var out []byte
+	var err error
+	switch {
+	case f.UnpadErr != nil:
+		err = f.UnpadErr
+	case f.UnpadFunc != nil:
+		out, err = f.UnpadFunc(buf, size)
+	default:
+		out, err = f.scheme().Unpad(buf, size)
+	}
+	f.record(Call{Method: "Unpad", Buf: in, Size: size, Out: out, Err: err})
+	return out, err
+}
+
+func (f *Fake) record(c Call) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, c)
+}
+
+// Calls returns the calls made to f so far, in order.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Reset forgets the calls made to f so far.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
@@ -0,0 +1,65 @@
+package pkcs7padtest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+
+	f := &Fake{}
+	TestScheme(t, f)
+	if f.Name() != "fake" {
+		t.Errorf("Name() = %q", f.Name())
+	}
+
+	f.Reset()
+	padded := f.Pad([]byte("YELLOW"), 8)
+	if out, err := f.Unpad(padded, 8); err != nil || string(out) != "YELLOW" {
+		t.Errorf("Unpad(%x) = %q, %v", padded, out, err)
+	}
+	calls := f.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("recorded %d calls", len(calls))
+	}
+	if c := calls[0]; c.Method != "Pad" || string(c.Buf) != "YELLOW" || c.Size != 8 || !bytes.Equal(c.Out, padded) {
+		t.Errorf("recorded %+v for Pad", c)
+	}
+	if c := calls[1]; c.Method != "Unpad" || !bytes.Equal(c.Buf, padded) || string(c.Out) != "YELLOW" || c.Err != nil {
+		t.Errorf("recorded %+v for Unpad", c)
+	}
+}
+
+func TestFakeErrors(t *testing.T) {
+	t.Parallel()
+
+	f := &Fake{SchemeName: "failing", UnpadErr: pkcs7pad.ErrBadPadding}
+	if f.Name() != "failing" {
+		t.Errorf("Name() = %q", f.Name())
+	}
+	padded := f.Pad([]byte("YELLOW"), 8)
+	if _, err := f.Unpad(padded, 8); err != pkcs7pad.ErrBadPadding {
+		t.Errorf("Unpad returned %v", err)
+	}
+	if calls := f.Calls(); len(calls) != 2 || calls[1].Err != pkcs7pad.ErrBadPadding {
+		t.Errorf("recorded %+v", calls)
+	}
+
+	errBoom := errors.New("boom")
+	f = &Fake{
+		Scheme: pkcs7pad.X923,
+		UnpadFunc: func(buf []byte, size int) ([]byte, error) {
+			return nil, errBoom
+		},
+	}
+	if got, want := f.Pad([]byte("YELLOW"), 8), pkcs7pad.X923.Pad([]byte("YELLOW"), 8); !bytes.Equal(got, want) {
+		t.Errorf("Pad = %x, want %x", got, want)
+	}
+	if _, err := f.Unpad(make([]byte, 8), 8); err != errBoom {
+		t.Errorf("Unpad returned %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package pkcs7padtest
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CorruptPadding returns a copy of buf, which must be correctly PKCS#7 padded,
+// with the padding byte at offset i from the end (so 0 is the final byte)
+// replaced by one that makes the padding invalid. It panics if i is not less
+// than the padding length claimed by the final byte.
+func CorruptPadding(buf []byte, i int) []byte {
+	if len(buf) == 0 {
+		panic("pkcs7padtest: CorruptPadding of an empty buffer")
+	}
+	padLen := int(buf[len(buf)-1])
+	if i < 0 || i >= padLen || padLen > len(buf) {
+		panic(fmt.Sprintf("pkcs7padtest: offset %d is outside of %d bytes of padding", i, padLen))
+	}
+	out := bytes.Clone(buf)
+	if i == 0 {
+		// Any other nonzero value could leave a valid, shorter padding.
+		out[len(out)-1] = 0
+	} else {
+		out[len(out)-1-i] = byte(padLen) ^ 0xff
+	}
+	return out
+}
+
+// TruncateToMisaligned returns the longest prefix of buf whose length is not a
+// multiple of size, which is buf itself if it is already misaligned. It panics
+// if there is no such prefix, which is the case when size is 1 or buf is empty.
+func TruncateToMisaligned(buf []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7padtest: inappropriate block size %d", size))
+	}
+	if len(buf)%size != 0 {
+		return buf
+	}
+	if size == 1 || len(buf) == 0 {
+		panic(fmt.Sprintf("pkcs7padtest: no prefix of %d bytes is misaligned for block size %d", len(buf), size))
+	}
+	return buf[:len(buf)-1]
+}
+
+// Corruptions returns every invalid buffer that CorruptPadding and
+// TruncateToMisaligned can derive from buf, a buffer correctly padded for the
+// given block size, along with the empty buffer. Every one of them must be
+// rejected by a strict decryptor.
+func Corruptions(buf []byte, size int) [][]byte {
+	out := [][]byte{{}}
+	for i := 0; i < int(buf[len(buf)-1]); i++ {
+		out = append(out, CorruptPadding(buf, i))
+	}
+	if size > 1 {
+		out = append(out, TruncateToMisaligned(buf, size))
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package pkcs7padtest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func TestCorruptions(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range blockSizes {
+		for _, n := range lengths(size) {
+			buf := pkcs7pad.Pad(bytes.Repeat([]byte{byte(n)}, n), size)
+			orig := bytes.Clone(buf)
+			for i, bad := range Corruptions(buf, size) {
+				if _, err := pkcs7pad.UnpadBlock(bad, size); !errors.Is(err, pkcs7pad.ErrBadPadding) {
+					t.Errorf("[%d/%d/%d] UnpadBlock(%x) returned %v", size, n, i, bad, err)
+				}
+			}
+			if !bytes.Equal(buf, orig) {
+				t.Errorf("[%d/%d] Corruptions modified its input", size, n)
+			}
+		}
+	}
+}
+
+func TestCorruptPaddingPanics(t *testing.T) {
+	t.Parallel()
+
+	buf := pkcs7pad.Pad([]byte("YELLOW"), 8)
+	for i, f := range []func(){
+		func() { CorruptPadding(nil, 0) },
+		func() { CorruptPadding(buf, -1) },
+		func() { CorruptPadding(buf, 2) },
+		func() { TruncateToMisaligned(buf, 1) },
+		func() { TruncateToMisaligned(nil, 8) },
+		func() { TruncateToMisaligned(buf, 0) },
+	} {
+		if !panics(f) {
+			t.Errorf("[%d] did not panic", i)
+		}
+	}
+	if got := TruncateToMisaligned(buf[:7], 8); len(got) != 7 {
+		t.Errorf("misaligned buffer truncated to %d bytes", len(got))
+	}
+}
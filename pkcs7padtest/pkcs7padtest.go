@@ -0,0 +1,167 @@
+// Package pkcs7padtest implements support for testing implementations of
+// pkcs7pad.Scheme, and code that uses padding: generators and fault-injection
+// helpers for padded buffers, and a fake Scheme.
+package pkcs7padtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// blockSizes are the block sizes exercised by TestScheme.
+var blockSizes = []int{1, 2, 8, 16, 32, 255}
+
+// TestScheme tests a padding scheme implementation. It checks that:
+//
+//   - Pad produces a buffer whose length is a multiple of the block size, that
+//     starts with the input, and that adds at most one block of padding.
+//   - Unpad reverses Pad, for inputs that do not end in a zero byte.
+//   - Unpad rejects buffers that are not a multiple of the block size with an
+//     error wrapping pkcs7pad.ErrBadPadding, and any other error it returns
+//     also wraps pkcs7pad.ErrBadPadding.
+//   - Unpad on arbitrary input never panics, and when it succeeds it returns a
+//     prefix of its input.
+//   - Pad and Unpad panic if the block size is not between 1 and 255.
+//
+// Schemes that cannot pad some lengths at all, such as pkcs7pad.None for
+// unaligned input, may panic in Pad for those lengths. Inputs that end in a
+// zero byte are not round-tripped, since zero padding can't support them.
+func TestScheme(t *testing.T, s pkcs7pad.Scheme) {
+	t.Helper()
+	check(s, func(format string, args ...any) {
+		t.Helper()
+		t.Errorf(format, args...)
+	})
+}
+
+// check runs the checks described by TestScheme, reporting each failure.
+func check(s pkcs7pad.Scheme, errorf func(format string, args ...any)) {
+	name := s.Name()
+	if name == "" {
+		errorf("Name() is empty")
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	for _, size := range blockSizes {
+		for _, n := range lengths(size) {
+			msg := make([]byte, n)
+			rng.Read(msg)
+			if n > 0 && msg[n-1] == 0 {
+				msg[n-1] = 1
+			}
+			checkRoundTrip(s, size, msg, errorf)
+		}
+		checkMalformed(s, size, rng, errorf)
+	}
+
+	for _, size := range []int{-1, 0, 256} {
+		if !panics(func() { s.Pad(nil, size) }) {
+			errorf("%s: Pad with block size %d did not panic", name, size)
+		}
+		if !panics(func() { s.Unpad(make([]byte, 16), size) }) {
+			errorf("%s: Unpad with block size %d did not panic", name, size)
+		}
+	}
+}
+
+// lengths returns the message lengths to test with the given block size.
+func lengths(size int) []int {
+	if size <= 32 {
+		lens := make([]int, 2*size+2)
+		for i := range lens {
+			lens[i] = i
+		}
+		return lens
+	}
+	return []int{0, 1, size - 1, size, size + 1, 2*size - 1, 2 * size}
+}
+
+func checkRoundTrip(s pkcs7pad.Scheme, size int, msg []byte, errorf func(string, ...any)) {
+	name := s.Name()
+	var padded []byte
+	if panics(func() { padded = s.Pad(bytes.Clone(msg), size) }) {
+		if len(msg)%size == 0 {
+			errorf("%s: Pad of %d bytes with block size %d panicked", name, len(msg), size)
+		}
+		return
+	}
+	if len(padded)%size != 0 {
+		errorf("%s: Pad of %d bytes with block size %d produced %d bytes", name, len(msg), size, len(padded))
+		return
+	}
+	if len(padded) < len(msg) || !bytes.Equal(padded[:len(msg)], msg) {
+		errorf("%s: Pad(%x, %d) = %x, which does not start with the input", name, msg, size, padded)
+		return
+	}
+	if len(padded)-len(msg) > size {
+		errorf("%s: Pad of %d bytes with block size %d added %d bytes of padding", name, len(msg), size, len(padded)-len(msg))
+	}
+
+	unpad, err := s.Unpad(bytes.Clone(padded), size)
+	if err != nil {
+		errorf("%s: Unpad(%x, %d) returned error %v", name, padded, size, err)
+	} else if !bytes.Equal(unpad, msg) {
+		errorf("%s: Unpad(%x, %d) = %x, want %x", name, padded, size, unpad, msg)
+	}
+}
+
+func checkMalformed(s pkcs7pad.Scheme, size int, rng *rand.Rand, errorf func(string, ...any)) {
+	name := s.Name()
+	if size > 1 {
+		buf := make([]byte, size+1)
+		rng.Read(buf)
+		if _, err := safeUnpad(s, buf, size); !errors.Is(err, pkcs7pad.ErrBadPadding) {
+			errorf("%s: Unpad of %d bytes with block size %d returned %v, want an error wrapping ErrBadPadding", name, len(buf), size, err)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		buf := make([]byte, size*(1+rng.Intn(3)))
+		rng.Read(buf)
+		// Make some of the buffers look more like padding.
+		if i%2 == 0 {
+			p := 1 + rng.Intn(size)
+			for j := len(buf) - p; j < len(buf); j++ {
+				buf[j] = byte(p)
+			}
+			buf[len(buf)-1-rng.Intn(p)] ^= byte(1 + rng.Intn(255))
+		}
+		orig := bytes.Clone(buf)
+		unpad, err := safeUnpad(s, buf, size)
+		if err != nil {
+			if !errors.Is(err, pkcs7pad.ErrBadPadding) {
+				errorf("%s: Unpad(%x, %d) returned %v, want an error wrapping ErrBadPadding", name, orig, size, err)
+			}
+			continue
+		}
+		if len(unpad) > len(orig) || !bytes.Equal(unpad, orig[:len(unpad)]) {
+			errorf("%s: Unpad(%x, %d) = %x, which is not a prefix of the input", name, orig, size, unpad)
+		}
+	}
+}
+
+// safeUnpad calls s.Unpad, turning a panic into an error that does not wrap
+// ErrBadPadding.
+func safeUnpad(s pkcs7pad.Scheme, buf []byte, size int) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.Unpad(buf, size)
+}
+
+func panics(f func()) (panicked bool) {
+	defer func() {
+		if recover() != nil {
+			panicked = true
+		}
+	}()
+	f()
+	return false
+}
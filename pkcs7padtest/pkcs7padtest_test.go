@@ -0,0 +1,73 @@
+package pkcs7padtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func TestBuiltinSchemes(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range pkcs7pad.Schemes() {
+		s, err := pkcs7pad.Lookup(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			TestScheme(t, s)
+		})
+	}
+}
+
+// broken is a version of PKCS#7 with mistakes that TestScheme should catch.
+type broken struct {
+	pkcs7pad.Scheme
+	name   string
+	mutate func(buf []byte, size int) ([]byte, error)
+}
+
+func (b broken) Name() string { return b.name }
+
+func (b broken) Unpad(buf []byte, size int) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic("bad size")
+	}
+	return b.mutate(buf, size)
+}
+
+func TestBrokenSchemes(t *testing.T) {
+	t.Parallel()
+
+	schemes := []broken{
+		{pkcs7pad.PKCS7, "off-by-one", func(buf []byte, size int) ([]byte, error) {
+			out, err := pkcs7pad.UnpadBlock(buf, size)
+			if err != nil {
+				return nil, err
+			}
+			return buf[:len(out)+1], nil
+		}},
+		{pkcs7pad.PKCS7, "unwrapped-error", func(buf []byte, size int) ([]byte, error) {
+			out, err := pkcs7pad.UnpadBlock(buf, size)
+			if err != nil {
+				return nil, fmt.Errorf("bad padding")
+			}
+			return out, nil
+		}},
+		{pkcs7pad.PKCS7, "panics", func(buf []byte, size int) ([]byte, error) {
+			return buf[:len(buf)-int(buf[len(buf)-1])], nil
+		}},
+		{pkcs7pad.PKCS7, "", pkcs7pad.UnpadBlock},
+	}
+	for _, s := range schemes {
+		var failures []string
+		check(s, func(format string, args ...any) {
+			failures = append(failures, fmt.Sprintf(format, args...))
+		})
+		if len(failures) == 0 {
+			t.Errorf("[%s] no failures reported", s.name)
+		}
+	}
+}
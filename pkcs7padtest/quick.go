@@ -0,0 +1,139 @@
+package pkcs7padtest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing/quick"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// genBlockSize returns a random block size, favoring the common ones.
+func genBlockSize(rng *rand.Rand) int {
+	switch rng.Intn(4) {
+	case 0:
+		return 8
+	case 1:
+		return 16
+	}
+	return 1 + rng.Intn(255)
+}
+
+// Padded is a correctly PKCS#7-padded buffer, for use with testing/quick.
+// Its Generate method picks a random block size and a random message of up to
+// size bytes, where size is the hint provided by testing/quick.
+type Padded struct {
+	Msg       []byte
+	Padded    []byte
+	BlockSize int
+}
+
+var _ quick.Generator = Padded{}
+
+// Generate implements quick.Generator.
+func (Padded) Generate(rng *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(genPadded(rng, genBlockSize(rng), rng.Intn(size+1)))
+}
+
+func genPadded(rng *rand.Rand, blockSize, n int) Padded {
+	msg := make([]byte, n)
+	rng.Read(msg)
+	return Padded{
+		Msg:       msg,
+		Padded:    pkcs7pad.Pad(append(make([]byte, 0, n+blockSize), msg...), blockSize),
+		BlockSize: blockSize,
+	}
+}
+
+// A Fault is a way in which a Malformed buffer's padding is wrong.
+type Fault int
+
+const (
+	// WrongLastByte is a buffer whose final byte is zero, which is never
+	// a valid padding length.
+	WrongLastByte Fault = iota
+
+	// TruncatedPad is a buffer whose final byte claims more padding than
+	// is present: at least one of the other bytes it covers differs.
+	TruncatedPad
+
+	// OversizePad is a buffer ending in n bytes of the value n, where n is
+	// greater than the block size. PKCS#7 never produces more than one
+	// block of padding, so it is rejected by pkcs7pad.UnpadBlock and
+	// pkcs7pad.PKCS7, but pkcs7pad.Unpad, which does not know the block
+	// size, accepts it.
+	OversizePad
+
+	numFaults
+)
+
+var faultNames = []string{"WrongLastByte", "TruncatedPad", "OversizePad"}
+
+func (f Fault) String() string {
+	if f < 0 || f >= numFaults {
+		return fmt.Sprintf("Fault(%d)", int(f))
+	}
+	return faultNames[f]
+}
+
+// Malformed is a buffer with a non-zero multiple of BlockSize bytes whose
+// PKCS#7 padding is wrong in the way described by Fault, for use with
+// testing/quick. Apart from the fault, it is a padded message of up to size
+// bytes, where size is the hint provided by testing/quick, so it looks like
+// the output of decrypting a tampered ciphertext.
+type Malformed struct {
+	Padded    []byte
+	BlockSize int
+	Fault     Fault
+}
+
+var _ quick.Generator = Malformed{}
+
+// Generate implements quick.Generator.
+func (Malformed) Generate(rng *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(genMalformed(rng, Fault(rng.Intn(int(numFaults))), size))
+}
+
+func genMalformed(rng *rand.Rand, fault Fault, size int) Malformed {
+	blockSize := genBlockSize(rng)
+	switch fault {
+	case TruncatedPad:
+		// There must be room for at least two bytes of padding.
+		for blockSize < 2 {
+			blockSize = genBlockSize(rng)
+		}
+	case OversizePad:
+		for blockSize > 254 {
+			blockSize = genBlockSize(rng)
+		}
+	}
+	p := genPadded(rng, blockSize, rng.Intn(size+1))
+	buf := p.Padded
+	switch fault {
+	case WrongLastByte:
+		buf[len(buf)-1] = 0
+	case TruncatedPad:
+		padLen := len(buf) - len(p.Msg)
+		if padLen < 2 {
+			// Claim a full block of padding instead of just one byte.
+			padLen = blockSize
+			buf[len(buf)-1] = byte(padLen)
+		}
+		i := len(buf) - padLen + rng.Intn(padLen-1)
+		buf[i] = byte(padLen) ^ byte(1+rng.Intn(255))
+	case OversizePad:
+		padLen := blockSize + 1 + rng.Intn(255-blockSize)
+		// Extend the buffer so that it is long enough to hold the padding
+		// and remains a multiple of the block size.
+		for len(buf) < padLen {
+			tail := make([]byte, blockSize)
+			rng.Read(tail)
+			buf = append(buf, tail...)
+		}
+		for i := len(buf) - padLen; i < len(buf); i++ {
+			buf[i] = byte(padLen)
+		}
+	}
+	return Malformed{Padded: buf, BlockSize: blockSize, Fault: fault}
+}
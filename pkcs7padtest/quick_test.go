@@ -0,0 +1,49 @@
+package pkcs7padtest
+
+import (
+	"bytes"
+	"testing"
+	"testing/quick"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func TestPaddedGenerator(t *testing.T) {
+	t.Parallel()
+
+	f := func(p Padded) bool {
+		if len(p.Padded)%p.BlockSize != 0 {
+			return false
+		}
+		out, err := pkcs7pad.UnpadBlock(p.Padded, p.BlockSize)
+		return err == nil && bytes.Equal(out, p.Msg)
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMalformedGenerator(t *testing.T) {
+	t.Parallel()
+
+	var seen [numFaults]int
+	f := func(m Malformed) bool {
+		seen[m.Fault]++
+		if len(m.Padded) == 0 || len(m.Padded)%m.BlockSize != 0 {
+			return false
+		}
+		if _, err := pkcs7pad.UnpadBlock(m.Padded, m.BlockSize); err == nil {
+			return false
+		}
+		_, err := pkcs7pad.Unpad(m.Padded)
+		return (err == nil) == (m.Fault == OversizePad)
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+	for fault, n := range seen {
+		if n == 0 {
+			t.Errorf("%v was never generated", Fault(fault))
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package pkcs7pad
+
+import (
+	"fmt"
+	"io"
+)
+
+const defaultBufSize = 4096
+
+// An UnpadReader is an io.Reader that strips PKCS#7 padding from the data read
+// from an underlying reader. Since the padding can only be identified once the
+// underlying reader is exhausted, an UnpadReader always holds back the most
+// recent block of data until it sees io.EOF.
+type UnpadReader struct {
+	r          io.Reader
+	size       int
+	buf        []byte
+	start, end int
+	total      int64
+	err        error
+}
+
+// NewUnpadReader returns an UnpadReader that reads from r using the given block
+// size. By default its buffer holds 4096 bytes plus one block; see
+// WithBufferSize and WithBuffer.
+func NewUnpadReader(r io.Reader, size int, opts ...StreamOption) *UnpadReader {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return &UnpadReader{r: r, size: size, buf: newStreamConfig(opts).buffer(defaultBufSize+size, 2*size, 1)}
+}
+
+// Read reads unpadded data into p. Once the underlying reader is exhausted, it
+// returns io.EOF if the final block was correctly padded and an error otherwise.
+// The padding of the final block is checked in constant time.
+func (ur *UnpadReader) Read(p []byte) (int, error) {
+	for {
+		// The final block is only released once its padding has been
+		// verified, which finish signals with io.EOF.
+		avail := ur.end - ur.start
+		if ur.err != io.EOF {
+			avail -= ur.size
+		}
+		if avail > 0 {
+			n := copy(p, ur.buf[ur.start:ur.start+avail])
+			ur.start += n
+			return n, nil
+		}
+		if ur.err != nil {
+			return 0, ur.err
+		}
+		ur.fill()
+	}
+}
+
+// WriteTo writes the unpadded data to w until the underlying reader is
+// exhausted, implementing io.WriterTo so that io.Copy writes straight from the
+// UnpadReader's buffer. It returns an error if the final block was not
+// correctly padded, after writing everything before that block. The padding is
+// checked as it is by Read.
+func (ur *UnpadReader) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for {
+		// As in Read, the final block is held back unless it was verified.
+		avail := ur.end - ur.start
+		if ur.err != io.EOF {
+			avail -= ur.size
+		}
+		if avail > 0 {
+			n, err := w.Write(ur.buf[ur.start : ur.start+avail])
+			ur.start += n
+			total += int64(n)
+			if err != nil {
+				return total, err
+			}
+			continue
+		}
+		if ur.err == io.EOF {
+			return total, nil
+		} else if ur.err != nil {
+			return total, ur.err
+		}
+		ur.fill()
+	}
+}
+
+// fill moves any buffered data to the front of the buffer, and reads more from
+// the underlying reader after it.
+func (ur *UnpadReader) fill() {
+	copy(ur.buf, ur.buf[ur.start:ur.end])
+	ur.end -= ur.start
+	ur.start = 0
+	n, err := ur.r.Read(ur.buf[ur.end:])
+	ur.end += n
+	ur.total += int64(n)
+	if err == io.EOF {
+		ur.err = ur.finish()
+	} else if err != nil {
+		ur.err = err
+	}
+}
+
+// finish strips the padding from the final block once the underlying reader
+// has been exhausted.
+func (ur *UnpadReader) finish() error {
+	if ur.total%int64(ur.size) != 0 {
+		return errMisaligned(ur.total, ur.size)
+	}
+	if ur.end-ur.start < ur.size {
+		return ErrBadPadding
+	}
+	unpad, err := Unpad(ur.buf[ur.end-ur.size : ur.end])
+	if err != nil {
+		return err
+	}
+	ur.end -= ur.size - len(unpad)
+	return io.EOF
+}
+
+// A PadReader is an io.Reader that yields the data read from an underlying
+// reader, followed by PKCS#7 padding once the underlying reader is exhausted.
+type PadReader struct {
+	r     io.Reader
+	size  int
+	total int64
+	pad   []byte
+	eof   bool
+	block [255]byte
+}
+
+// NewPadReader returns a PadReader that reads from r using the given block
+// size.
+func NewPadReader(r io.Reader, size int) *PadReader {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return &PadReader{r: r, size: size}
+}
+
+// Read reads data into p. Errors from the underlying reader other than io.EOF
+// are returned as-is.
+func (pr *PadReader) Read(p []byte) (int, error) {
+	if !pr.eof {
+		n, err := pr.r.Read(p)
+		pr.total += int64(n)
+		if err != io.EOF {
+			return n, err
+		}
+		pr.eof = true
+		i := pr.size - int(pr.total%int64(pr.size))
+		pr.pad = pr.block[:i]
+		for j := range pr.pad {
+			pr.pad[j] = byte(i)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+	if len(pr.pad) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, pr.pad)
+	pr.pad = pr.pad[n:]
+	return n, nil
+}
+
+// ReadAllUnpadded reads from r until io.EOF and returns the data with its PKCS#7
+// padding removed. Like UnpadBlock, it returns an error wrapping ErrBadPadding
+// if the amount of data read is not a non-zero multiple of size or if the
+// padding is malformed. Errors from r are returned as-is.
+func ReadAllUnpadded(r io.Reader, size int) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return unpadBlock(buf, size)
+}
@@ -0,0 +1,162 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestUnpadReader(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 300)
+	for _, n := range []int{0, 1, 15, 16, 17, 4095, 4096, 4097, len(in)} {
+		pad := Pad(append([]byte(nil), in[:n]...), aes.BlockSize)
+		readers := map[string]io.Reader{
+			"plain":   bytes.NewReader(pad),
+			"onebyte": iotest.OneByteReader(bytes.NewReader(pad)),
+			"half":    iotest.HalfReader(bytes.NewReader(pad)),
+			"dataerr": iotest.DataErrReader(bytes.NewReader(pad)),
+		}
+		for name, r := range readers {
+			out, err := io.ReadAll(NewUnpadReader(r, aes.BlockSize))
+			if err != nil {
+				t.Errorf("[%s/%d] error reading: %v", name, n, err)
+			}
+			if !bytes.Equal(out, in[:n]) {
+				t.Errorf("[%s/%d] got %d bytes, want %d", name, n, len(out), n)
+			}
+		}
+	}
+}
+
+func TestUnpadReaderErrors(t *testing.T) {
+	t.Parallel()
+
+	bad := [][]byte{
+		{},
+		testString[:15],
+		append(testString[:16:16], 0x04, 0x04, 0x04),
+		testString,
+		bytes.Repeat([]byte{0x11}, 32),
+	}
+	for i, test := range bad {
+		_, err := io.ReadAll(NewUnpadReader(bytes.NewReader(test), aes.BlockSize))
+		if !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+
+	// Nothing from a final block that fails to verify may be returned.
+	unverified := []struct {
+		in   []byte
+		want int
+	}{
+		{append(bytes.Repeat([]byte("a"), 16), "0123456789abcde\x05"...), 16},
+		{bytes.Repeat([]byte("a"), 19), 3},
+		{bytes.Repeat([]byte{0x01}, 15), 0},
+	}
+	for i, test := range unverified {
+		for _, r := range []io.Reader{bytes.NewReader(test.in), iotest.OneByteReader(bytes.NewReader(test.in))} {
+			out, err := io.ReadAll(NewUnpadReader(r, aes.BlockSize))
+			if !errors.Is(err, ErrBadPadding) || len(out) != test.want {
+				t.Errorf("[%d] ReadAll = %q, %v; want %d bytes", i, out, err, test.want)
+			}
+		}
+	}
+
+	boom := errors.New("boom")
+	_, err := io.ReadAll(NewUnpadReader(iotest.ErrReader(boom), aes.BlockSize))
+	if err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+}
+
+func TestPadReader(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 10)
+	for n := 0; n <= len(in); n++ {
+		want := Pad(append([]byte(nil), in[:n]...), aes.BlockSize)
+		readers := map[string]io.Reader{
+			"plain":   bytes.NewReader(in[:n]),
+			"onebyte": iotest.OneByteReader(bytes.NewReader(in[:n])),
+			"dataerr": iotest.DataErrReader(bytes.NewReader(in[:n])),
+		}
+		for name, r := range readers {
+			out, err := io.ReadAll(NewPadReader(r, aes.BlockSize))
+			if err != nil {
+				t.Errorf("[%s/%d] error reading: %v", name, n, err)
+			}
+			if !bytes.Equal(out, want) {
+				t.Errorf("[%s/%d] %x != %x", name, n, out, want)
+			}
+		}
+	}
+
+	if err := iotest.TestReader(NewPadReader(bytes.NewReader(in[:21]), aes.BlockSize), Pad(in[:21:21], aes.BlockSize)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadAllUnpadded(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 2)
+	for n := 0; n <= len(in); n++ {
+		padded := Pad(bytes.Clone(in[:n]), aes.BlockSize)
+		out, err := ReadAllUnpadded(iotest.HalfReader(bytes.NewReader(padded)), aes.BlockSize)
+		if err != nil || !bytes.Equal(out, in[:n]) {
+			t.Errorf("[%d] ReadAllUnpadded = %x, %v", n, out, err)
+		}
+	}
+
+	for i, in := range [][]byte{nil, testString[:15], Pad(nil, 8)} {
+		if _, err := ReadAllUnpadded(bytes.NewReader(in), aes.BlockSize); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+	failure := errors.New("failure")
+	if _, err := ReadAllUnpadded(iotest.ErrReader(failure), aes.BlockSize); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
+
+func TestUnpadReaderWriteTo(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 600)
+	for _, n := range []int{0, 1, 15, 16, 17, 5000, len(in)} {
+		padded := Pad(bytes.Clone(in[:n]), aes.BlockSize)
+		ur := NewUnpadReader(iotest.HalfReader(bytes.NewReader(padded)), aes.BlockSize)
+		// Read a little first, to check that WriteTo picks up from there.
+		head := make([]byte, min(n, 3))
+		if _, err := io.ReadFull(ur, head); err != nil {
+			t.Fatalf("[%d] %v", n, err)
+		}
+		var _ io.WriterTo = ur
+		var buf bytes.Buffer
+		m, err := io.Copy(&buf, ur)
+		if err != nil || m != int64(n-len(head)) || !bytes.Equal(append(head, buf.Bytes()...), in[:n]) {
+			t.Errorf("[%d] Copy = %d, %v", n, m, err)
+		}
+	}
+
+	bad := Pad(bytes.Clone(in[:100]), aes.BlockSize)
+	bad[len(bad)-1] = 0
+	var buf bytes.Buffer
+	if _, err := NewUnpadReader(bytes.NewReader(bad), aes.BlockSize).WriteTo(&buf); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding, got %v", err)
+	}
+	if buf.Len() != 96 {
+		t.Errorf("wrote %d bytes before the final block, want 96", buf.Len())
+	}
+	failure := errors.New("failure")
+	ur := NewUnpadReader(bytes.NewReader(Pad(bytes.Clone(in), aes.BlockSize)), aes.BlockSize)
+	if _, err := ur.WriteTo(errWriter{failure}); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
@@ -0,0 +1,32 @@
+package pkcs7pad
+
+import (
+	"errors"
+	"io"
+)
+
+// UnpadAt returns the length of the data held in the first size bytes of r,
+// with trailing PKCS#7 padding removed, without reading the rest of it. Only
+// the final 255 bytes (or fewer, if size is smaller) are read, so it can check
+// multi-gigabyte files cheaply. It performs the same constant-time validation
+// as Unpad, and returns an error wrapping ErrBadPadding if the padding is
+// malformed, or any error returned by r.
+func UnpadAt(r io.ReaderAt, size int64) (int64, error) {
+	if size < 0 {
+		return 0, errors.New("pkcs7pad: negative size")
+	}
+	var buf [255]byte
+	tail := buf[:min(size, int64(len(buf)))]
+	n, err := r.ReadAt(tail, size-int64(len(tail)))
+	if n < len(tail) {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	padLen, good := checkPadding(tail)
+	if good != 1 {
+		return 0, ErrBadPadding
+	}
+	return size - int64(padLen), nil
+}
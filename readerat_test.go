@@ -0,0 +1,65 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// countingReaderAt records how many bytes were read from it.
+type countingReaderAt struct {
+	r    io.ReaderAt
+	read int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += n
+	return n, err
+}
+
+func TestUnpadAt(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{0, 1, 15, 16, 17, 200, 4096} {
+		padded := Pad(bytes.Repeat([]byte{0xaa}, n), 16)
+		r := &countingReaderAt{r: bytes.NewReader(padded)}
+		got, err := UnpadAt(r, int64(len(padded)))
+		if err != nil || got != int64(n) {
+			t.Errorf("[%d] UnpadAt = %d, %v", n, got, err)
+		}
+		if r.read > 255 {
+			t.Errorf("[%d] read %d bytes", n, r.read)
+		}
+	}
+
+	// UnpadAt only looks at the first size bytes.
+	data := append(Pad([]byte("hello"), 8), "trailing"...)
+	if got, err := UnpadAt(bytes.NewReader(data), 8); err != nil || got != 5 {
+		t.Errorf("UnpadAt with trailing data = %d, %v", got, err)
+	}
+}
+
+func TestUnpadAtErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		data []byte
+		size int64
+		want error
+	}{
+		{nil, 0, ErrBadPadding},
+		{[]byte("hello\x03\x02\x03"), 8, ErrBadPadding},
+		{[]byte("hello\x00"), 6, ErrBadPadding},
+		{[]byte("hello\x01"), 7, io.ErrUnexpectedEOF},
+	}
+	for i, test := range tests {
+		if _, err := UnpadAt(bytes.NewReader(test.data), test.size); !errors.Is(err, test.want) {
+			t.Errorf("[%d] expected %v, got %v", i, test.want, err)
+		}
+	}
+	if _, err := UnpadAt(bytes.NewReader(nil), -1); err == nil {
+		t.Error("expected error for negative size")
+	}
+}
@@ -0,0 +1,89 @@
+package pkcs7pad
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// recordHeaderSize is the size of the header SplitRecords adds to each record:
+// the record's index and the number of records, as 16-bit big-endian integers.
+const recordHeaderSize = 4
+
+// ErrRecords is returned by JoinRecords when the records are well padded but
+// don't form a complete message, for example because one was lost or
+// duplicated in transit.
+var ErrRecords = errors.New("pkcs7pad: incomplete or inconsistent records")
+
+// SplitRecords splits msg into records of at most maxRecord bytes each, for
+// transports such as UDP that limit the size of a datagram. Each record is a
+// 4-byte header holding its index and the number of records, followed by a
+// fragment of msg, padded independently with PKCS#7 so that it can be
+// encrypted on its own with a block cipher of the given size. An empty msg is
+// split into a single record.
+//
+// It returns an error if maxRecord is too small to hold a header, a byte of
+// msg, and the padding, or if msg would need more than 65535 records. Like Pad,
+// SplitRecords panics if size is not between 1 and 255.
+func SplitRecords(msg []byte, maxRecord, size int) ([][]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	// The largest fragment whose record, with at least one byte of padding,
+	// fits in maxRecord.
+	frag := max(maxRecord, 0)/size*size - recordHeaderSize - 1
+	if frag < 1 {
+		return nil, fmt.Errorf("pkcs7pad: record size %d is too small for block size %d", maxRecord, size)
+	}
+	n := max(1, (len(msg)+frag-1)/frag)
+	if n > math.MaxUint16 {
+		return nil, fmt.Errorf("pkcs7pad: %d bytes need %d records, more than %d", len(msg), n, math.MaxUint16)
+	}
+
+	records := make([][]byte, n)
+	for i := range records {
+		chunk := msg[min(i*frag, len(msg)):min((i+1)*frag, len(msg))]
+		rec := make([]byte, recordHeaderSize, PaddedLen(recordHeaderSize+len(chunk), size))
+		binary.BigEndian.PutUint16(rec, uint16(i))
+		binary.BigEndian.PutUint16(rec[2:], uint16(n))
+		records[i] = pad(append(rec, chunk...), size)
+	}
+	return records, nil
+}
+
+// JoinRecords reassembles a message split by SplitRecords with the same block
+// size. The records may be given in any order. It returns an error wrapping
+// ErrBadPadding if any record is badly padded, and ErrRecords if the records
+// are missing, duplicated, or from different messages.
+func JoinRecords(records [][]byte, size int) ([]byte, error) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	frags := make([][]byte, len(records))
+	total := 0
+	for _, rec := range records {
+		rec, err := unpadBlock(rec, size)
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) < recordHeaderSize {
+			return nil, ErrRecords
+		}
+		i, n := int(binary.BigEndian.Uint16(rec)), int(binary.BigEndian.Uint16(rec[2:]))
+		if n != len(records) || i >= n || frags[i] != nil {
+			return nil, ErrRecords
+		}
+		frags[i] = rec[recordHeaderSize:]
+		total += len(frags[i])
+	}
+	if len(records) == 0 {
+		return nil, ErrRecords
+	}
+
+	msg := make([]byte, 0, total)
+	for _, f := range frags {
+		msg = append(msg, f...)
+	}
+	return msg, nil
+}
@@ -0,0 +1,79 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSplitRecords(t *testing.T) {
+	t.Parallel()
+
+	msg := make([]byte, 200)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	for _, maxRecord := range []int{16, 17, 31, 32, 100, 1500} {
+		for _, n := range []int{0, 1, 10, 11, 12, 100, len(msg)} {
+			records, err := SplitRecords(msg[:n], maxRecord, 16)
+			if err != nil {
+				t.Fatalf("[%d, %d] %v", maxRecord, n, err)
+			}
+			for i, rec := range records {
+				if len(rec) > maxRecord || len(rec)%16 != 0 {
+					t.Errorf("[%d, %d] record %d has length %d", maxRecord, n, i, len(rec))
+				}
+			}
+			// Reverse the records to check that order doesn't matter.
+			for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+				records[i], records[j] = records[j], records[i]
+			}
+			out, err := JoinRecords(records, 16)
+			if err != nil || !bytes.Equal(out, msg[:n]) {
+				t.Errorf("[%d, %d] JoinRecords = %x, %v", maxRecord, n, out, err)
+			}
+		}
+	}
+
+	if records, _ := SplitRecords(msg[:22], 16, 8); len(records) != 2 || len(records[0]) != 16 || len(records[1]) != 16 {
+		t.Errorf("unexpected records %x", records)
+	}
+	for _, maxRecord := range []int{-1, 0, 15, 5} {
+		if _, err := SplitRecords(msg, maxRecord, 16); err == nil {
+			t.Errorf("[%d] expected error", maxRecord)
+		}
+	}
+	if _, err := SplitRecords(make([]byte, 1<<16), 6, 1); err == nil {
+		t.Error("expected error for too many records")
+	}
+}
+
+func TestJoinRecordsErrors(t *testing.T) {
+	t.Parallel()
+
+	records, err := SplitRecords(bytes.Repeat([]byte{0xaa}, 50), 32, 16)
+	if err != nil || len(records) != 2 {
+		t.Fatalf("SplitRecords = %d records, %v", len(records), err)
+	}
+	other, _ := SplitRecords(bytes.Repeat([]byte{0xbb}, 100), 32, 16)
+
+	badPad := [][]byte{records[0], bytes.Clone(records[1])}
+	badPad[1][len(badPad[1])-1] ^= 0x40
+	if _, err := JoinRecords(badPad, 16); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("bad padding: %v", err)
+	}
+
+	tests := [][][]byte{
+		nil,
+		records[:1],
+		{records[0], records[0]},
+		{records[0], other[1]},
+		{records[0], records[1], records[1]},
+		{records[0], Pad([]byte{0, 1, 0}, 16)},
+	}
+	for i, recs := range tests {
+		if _, err := JoinRecords(recs, 16); !errors.Is(err, ErrRecords) {
+			t.Errorf("[%d] expected ErrRecords, got %v", i, err)
+		}
+	}
+}
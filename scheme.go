@@ -0,0 +1,118 @@
+package pkcs7pad
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// A Scheme is a block cipher padding scheme.
+//
+// Pad appends padding to buf such that its length is a multiple of size, and
+// Unpad removes it again, returning an error if the padding is malformed. Both
+// methods panic if size is not between 1 and 255. Unpad additionally rejects
+// buffers whose length is not a multiple of size.
+type Scheme interface {
+	// Name returns the name the scheme is registered under, e.g. "pkcs7".
+	Name() string
+	Pad(buf []byte, size int) []byte
+	Unpad(buf []byte, size int) ([]byte, error)
+}
+
+// The padding schemes implemented by this package. Each of them is available
+// by name via Lookup.
+var (
+	PKCS7    Scheme = pkcs7{}
+	X923     Scheme = x923{}
+	ISO7816  Scheme = iso7816{}
+	ISO10126 Scheme = iso10126{}
+	Zero     Scheme = zero{}
+	None     Scheme = none{}
+
+	// ISO/IEC 9797-1 padding methods 1 and 2. Method 3 prepends a length
+	// block, which doesn't fit the Scheme interface, and is only available
+	// as PadISO9797M3 and UnpadISO9797M3.
+	ISO9797M1 Scheme = iso9797m1{}
+	ISO9797M2 Scheme = iso9797m2{}
+
+	// Trailing bit complement padding, as in Bouncy Castle's TBCPadding.
+	TBC Scheme = tbc{}
+
+	// PKCS7SkipAligned is the legacy variant of PKCS#7 that adds no padding
+	// to aligned input. Since it can't round-trip every message, it is not
+	// available via Lookup; see PadSkipAligned.
+	PKCS7SkipAligned Scheme = skipAligned{}
+
+	// PKCS5 is PKCS#7 restricted to a block size of 8. Its Pad panics, and
+	// its Unpad returns an error wrapping ErrBlockSize, for any other block
+	// size, so configuration mistakes surface immediately. Since it doesn't
+	// support every block size, it is not available via Lookup.
+	PKCS5 Scheme = pkcs5{}
+)
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]Scheme{
+		PKCS7.Name():    PKCS7,
+		X923.Name():     X923,
+		ISO7816.Name():  ISO7816,
+		ISO10126.Name(): ISO10126,
+		Zero.Name():     Zero,
+		None.Name():     None,
+
+		ISO9797M1.Name(): ISO9797M1,
+		ISO9797M2.Name(): ISO9797M2,
+		TBC.Name():       TBC,
+	}
+)
+
+// Register makes a padding scheme available by the name returned by its Name
+// method. It panics if s is nil or if a scheme with the same name has already
+// been registered.
+func Register(s Scheme) {
+	if s == nil {
+		panic("pkcs7pad: Register scheme is nil")
+	}
+	name := s.Name()
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	if _, dup := schemes[name]; dup {
+		panic("pkcs7pad: Register called twice for scheme " + name)
+	}
+	schemes[name] = s
+}
+
+// Lookup returns the padding scheme registered under the given name.
+func Lookup(name string) (Scheme, error) {
+	schemesMu.RLock()
+	s, ok := schemes[name]
+	schemesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("pkcs7pad: unknown padding scheme %q", name)
+	}
+	return s, nil
+}
+
+// Schemes returns a sorted list of the names of the registered padding schemes.
+func Schemes() []string {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkAligned is shared by the Unpad methods of the built-in schemes. The
+// buffer length is public, so it's fine to check it in variable time.
+func checkAligned(buf []byte, size int) error {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(buf)%size != 0 {
+		return errMisaligned(int64(len(buf)), size)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSchemesRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range Schemes() {
+		s, err := Lookup(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Name() != name {
+			t.Errorf("scheme registered as %q has name %q", name, s.Name())
+		}
+		// Zero padding can't round-trip plaintexts ending in zero bytes,
+		// and no padding can't pad a partial block.
+		in := testString[:aes.BlockSize]
+		if s != None {
+			in = in[:7]
+		}
+		buf := make([]byte, len(in))
+		copy(buf, in)
+		pad := s.Pad(buf, aes.BlockSize)
+		if len(pad)%aes.BlockSize != 0 {
+			t.Errorf("[%s] padded length %d is not aligned", name, len(pad))
+		}
+		unpad, err := s.Unpad(pad, aes.BlockSize)
+		if err != nil {
+			t.Errorf("[%s] error unpadding: %v", name, err)
+		}
+		if !bytes.Equal(unpad, in) {
+			t.Errorf("[%s] %x != %x", name, unpad, in)
+		}
+	}
+}
+
+func TestSchemesMisaligned(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []Scheme{PKCS7, X923, ISO7816, ISO10126, Zero, None, ISO9797M1, ISO9797M2, TBC} {
+		if _, err := s.Unpad(PadTests[1].out[:15], aes.BlockSize); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%s] expected BadCiphertext, got %v", s.Name(), err)
+		}
+	}
+}
+
+func TestSchemesBuiltin(t *testing.T) {
+	t.Parallel()
+
+	want := []string{"iso10126", "iso7816", "iso9797-m1", "iso9797-m2", "none", "pkcs7", "tbc", "x923", "zero"}
+	for _, name := range want {
+		if _, err := Lookup(name); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := Lookup("rot13"); err == nil {
+		t.Error("expected error looking up unknown scheme")
+	}
+}
+
+type testScheme struct{ pkcs7 }
+
+func (testScheme) Name() string { return "test" }
+
+func TestRegister(t *testing.T) {
+	Register(testScheme{})
+	s, err := Lookup("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s, testScheme{}) {
+		t.Errorf("got scheme %#v", s)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic registering duplicate scheme")
+		}
+	}()
+	Register(testScheme{})
+}
@@ -0,0 +1,91 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// selfTestVectors are the known answers checked by SelfTest. The block sizes
+// cover the specialized 8- and 16-byte paths as well as the general one, and
+// the 255-byte block is long enough to reach any vectorized padding check.
+var selfTestVectors = []struct {
+	size    int
+	in, out []byte
+}{
+	{8, []byte{}, bytes.Repeat([]byte{8}, 8)},
+	{8, []byte("YELLOW"), []byte("YELLOW\x02\x02")},
+	{8, []byte("SUBMARIN"), []byte("SUBMARIN\x08\x08\x08\x08\x08\x08\x08\x08")},
+	{16, []byte("YELLOW SUBMARINE"), append([]byte("YELLOW SUBMARINE"), bytes.Repeat([]byte{16}, 16)...)},
+	{16, []byte("YELLOW SUBMARIN"), []byte("YELLOW SUBMARIN\x01")},
+	{16, []byte("YELLOW"), append([]byte("YELLOW"), bytes.Repeat([]byte{10}, 10)...)},
+	{20, []byte("YELLOW SUBMARINE"), []byte("YELLOW SUBMARINE\x04\x04\x04\x04")},
+	{255, []byte("YELLOW SUBMARINE"), append([]byte("YELLOW SUBMARINE"), bytes.Repeat([]byte{239}, 239)...)},
+	{255, []byte{0xff}, append([]byte{0xff}, bytes.Repeat([]byte{254}, 254)...)},
+	{255, []byte{}, bytes.Repeat([]byte{255}, 255)},
+}
+
+// SelfTest checks the implementation against a small set of known answers,
+// returning an error describing the first discrepancy. It exercises padding,
+// unpadding and validation, including any assembly implementations selected
+// for the current CPU, and is cheap enough to run every time a program starts.
+// It is intended for deployments that must test their cryptographic modules
+// before use; a non-nil error indicates a miscompiled or otherwise broken build.
+func SelfTest() error {
+	for i, v := range selfTestVectors {
+		if err := selfTestVector(v.size, v.in, v.out); err != nil {
+			return fmt.Errorf("pkcs7pad: self-test %d failed: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// selfTestVector checks that in pads to out for the given block size, that out
+// unpads to in, and that out is rejected when any of its padding bytes is
+// wrong.
+func selfTestVector(size int, in, out []byte) error {
+	if got := pad(bytes.Clone(in), size); !bytes.Equal(got, out) {
+		return fmt.Errorf("Pad(%x, %d) = %x", in, size, got)
+	}
+	if got, err := Unpad(out); err != nil || !bytes.Equal(got, in) {
+		return fmt.Errorf("Unpad(%x) = %x, %v", out, got, err)
+	}
+	if got, err := unpadBlock(out, size); err != nil || !bytes.Equal(got, in) {
+		return fmt.Errorf("UnpadBlock(%x, %d) = %x, %v", out, size, got, err)
+	}
+	// An odd number of records exercises both the vector and portable paths
+	// of ValidateAll.
+	records := make([][]byte, 5)
+	ok := make([]bool, len(records))
+	for i := range records {
+		records[i] = out
+	}
+	ValidateAll(ok, records, size)
+	for i := range ok {
+		if !ok[i] {
+			return fmt.Errorf("ValidateAll rejected %x", out)
+		}
+	}
+
+	bad := bytes.Clone(out)
+	padLen := len(out) - len(in)
+	for j := len(bad) - padLen; j < len(bad); j++ {
+		bad[j] ^= 0x80
+		if _, err := Unpad(bad); err == nil {
+			return fmt.Errorf("Unpad accepted %x", bad)
+		}
+		if _, err := unpadBlock(bad, size); err == nil {
+			return fmt.Errorf("UnpadBlock accepted %x", bad)
+		}
+		for i := range records {
+			records[i] = bad
+		}
+		ValidateAll(ok, records, size)
+		for i := range ok {
+			if ok[i] {
+				return fmt.Errorf("ValidateAll accepted %x", bad)
+			}
+		}
+		bad[j] ^= 0x80
+	}
+	return nil
+}
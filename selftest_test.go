@@ -0,0 +1,32 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	t.Parallel()
+
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelfTestVectorFailure(t *testing.T) {
+	t.Parallel()
+
+	bad := []struct {
+		size    int
+		in, out []byte
+	}{
+		{16, []byte("YELLOW"), append([]byte("YELLOW"), bytes.Repeat([]byte{9}, 10)...)},
+		{8, []byte("YELLOW"), []byte("YELLOW\x02")},
+		{8, []byte("YELLOW"), []byte("YELLOWSUB\x01")},
+	}
+	for i, test := range bad {
+		if err := selfTestVector(test.size, test.in, test.out); err == nil {
+			t.Errorf("[%d] expected an error", i)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// The functions in this file implement a broken variant of PKCS#7 padding used
+// by several legacy systems, which only pad plaintexts that are not already a
+// multiple of the block size. It is ambiguous: an aligned plaintext whose final
+// bytes happen to look like PKCS#7 padding (such as one ending in 0x01) loses
+// them when it is unpadded. It should only be used to read data produced by
+// systems that cannot be changed. New designs should use Pad and Unpad instead.
+
+// PadSkipAligned appends PKCS#7 padding to the given buffer such that the
+// resulting slice of bytes has a length divisible by the given size. Unlike
+// Pad, no padding is added if the buffer is already a multiple of the block
+// size.
+func PadSkipAligned(buf []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(buf)%size == 0 {
+		return buf
+	}
+	return pad(buf, size)
+}
+
+// UnpadSkipAligned reverses PadSkipAligned as well as it can: if the final
+// block of buf ends in valid PKCS#7 padding, it is removed, and otherwise buf
+// is returned unchanged. An error wrapping ErrBadPadding is returned only if
+// the length of buf is not a multiple of size. Whether padding was removed is
+// decided in constant time, though it is revealed by the length of the result.
+func UnpadSkipAligned(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return buf, nil
+	}
+	padLen, good := checkPadding(buf[len(buf)-size:])
+	return buf[:len(buf)-subtle.ConstantTimeSelect(good, padLen, 0)], nil
+}
+
+type skipAligned struct{}
+
+func (skipAligned) Name() string                    { return "pkcs7-skip-aligned" }
+func (skipAligned) Pad(buf []byte, size int) []byte { return PadSkipAligned(buf, size) }
+func (skipAligned) Unpad(buf []byte, size int) ([]byte, error) {
+	return UnpadSkipAligned(buf, size)
+}
@@ -0,0 +1,54 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+)
+
+func TestPadSkipAligned(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		want := test.out
+		if len(test.in)%aes.BlockSize == 0 {
+			want = test.in
+		}
+		got := PadSkipAligned(bytes.Clone(test.in), aes.BlockSize)
+		if !bytes.Equal(got, want) {
+			t.Errorf("[%d] %x != %x", i, got, want)
+		}
+		out, err := PKCS7SkipAligned.Unpad(got, aes.BlockSize)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(out, test.in) {
+			t.Errorf("[%d] %x != %x", i, out, test.in)
+		}
+	}
+}
+
+func TestUnpadSkipAligned(t *testing.T) {
+	t.Parallel()
+
+	// An aligned plaintext that looks padded is indistinguishable from a
+	// padded one.
+	ambiguous := []byte("YELLOW SUBMARIN\x01")
+	if out, err := UnpadSkipAligned(ambiguous, 16); err != nil || string(out) != "YELLOW SUBMARIN" {
+		t.Errorf("UnpadSkipAligned(%x) = %x, %v", ambiguous, out, err)
+	}
+	plain := []byte("YELLOW SUBMARINE")
+	if out, err := UnpadSkipAligned(plain, 16); err != nil || !bytes.Equal(out, plain) {
+		t.Errorf("UnpadSkipAligned(%x) = %x, %v", plain, out, err)
+	}
+	if out, err := UnpadSkipAligned(nil, 16); err != nil || len(out) != 0 {
+		t.Errorf("UnpadSkipAligned(nil) = %x, %v", out, err)
+	}
+	if _, err := UnpadSkipAligned(plain[:15], 16); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding for a misaligned buffer, got %v", err)
+	}
+	if _, err := Lookup(PKCS7SkipAligned.Name()); err == nil {
+		t.Error("PKCS7SkipAligned should not be registered")
+	}
+}
@@ -0,0 +1,86 @@
+package pkcs7pad
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// sshMinPadding is the least amount of random padding RFC 4253 allows.
+const sshMinPadding = 4
+
+// sshAlign returns the multiple an SSH packet must be aligned to: the cipher's
+// block size, or 8, whichever is larger.
+func sshAlign(blockSize int) int {
+	if blockSize < 1 || blockSize > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", blockSize))
+	}
+	return max(blockSize, 8)
+}
+
+// PadSSH returns an SSH binary packet holding payload, as defined in RFC 4253,
+// section 6: a 4-byte packet length, a 1-byte padding length, the payload, and
+// between 4 and 255 bytes of random padding, so that the whole packet is a
+// multiple of the cipher's block size or of 8, whichever is larger. The least
+// padding satisfying these rules is used. The MAC, if any, is not included.
+//
+// https://tools.ietf.org/html/rfc4253#section-6
+//
+// The random bytes are read from r. If r is nil, crypto/rand.Reader is used. An
+// error is returned if reading from r fails, or an error wrapping ErrBlockSize
+// if blockSize is more than 252: since padding_length is a single byte, some
+// payloads can't be aligned to larger block sizes with at least 4 bytes of
+// padding. PadSSH still panics if blockSize is not between 1 and 255.
+func PadSSH(payload []byte, blockSize int, r io.Reader) ([]byte, error) {
+	align := sshAlign(blockSize)
+	if align+sshMinPadding-1 > 255 {
+		return nil, fmt.Errorf("%w %d: SSH padding can't align to more than %d bytes", ErrBlockSize, blockSize, 256-sshMinPadding)
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+	unpadded := 4 + 1 + len(payload)
+	i := align - unpadded%align
+	if i < sshMinPadding {
+		i += align
+	}
+	packet := make([]byte, unpadded+i)
+	binary.BigEndian.PutUint32(packet, uint32(len(packet)-4))
+	packet[4] = byte(i)
+	copy(packet[5:], payload)
+	if _, err := io.ReadFull(r, packet[unpadded:]); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+// UnpadSSH returns the payload of an SSH binary packet produced by PadSSH, as a
+// subslice of packet. It returns an error wrapping ErrBadPadding if the packet
+// length field does not match the length of packet, if packet is not aligned
+// as described by PadSSH, or if the padding length is less than 4 or extends
+// past the start of the payload. The padding length is checked in constant
+// time; the random padding itself can't be checked.
+func UnpadSSH(packet []byte, blockSize int) ([]byte, error) {
+	align := sshAlign(blockSize)
+	if len(packet)%align != 0 {
+		return nil, errMisaligned(int64(len(packet)), align)
+	}
+	if len(packet) < 4+1+sshMinPadding {
+		return nil, ErrBadPadding
+	}
+	// The packet length is checked against the length of packet, which
+	// is public, so it can be compared with a branch.
+	if binary.BigEndian.Uint32(packet) != uint32(len(packet)-4) {
+		return nil, fmt.Errorf("%w: packet length field does not match the packet", ErrBadPadding)
+	}
+
+	padLen := int(packet[4])
+	good := subtle.ConstantTimeLessOrEq(sshMinPadding, padLen)
+	good &= subtle.ConstantTimeLessOrEq(padLen, len(packet)-5)
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+	return packet[5 : len(packet)-padLen], nil
+}
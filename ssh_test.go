@@ -0,0 +1,95 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestPadSSH(t *testing.T) {
+	t.Parallel()
+
+	for _, bs := range []int{1, 8, 16} {
+		align := max(bs, 8)
+		for n := 0; n < 64; n++ {
+			payload := bytes.Repeat([]byte{0xa5}, n)
+			packet, err := PadSSH(payload, bs, nil)
+			if err != nil {
+				t.Fatalf("[%d/%d] error padding: %v", bs, n, err)
+			}
+			if len(packet)%align != 0 {
+				t.Errorf("[%d/%d] packet of %d bytes is misaligned", bs, n, len(packet))
+			}
+			if got := int(binary.BigEndian.Uint32(packet)); got != len(packet)-4 {
+				t.Errorf("[%d/%d] packet length %d, want %d", bs, n, got, len(packet)-4)
+			}
+			if p := int(packet[4]); p < 4 || p >= 4+align {
+				t.Errorf("[%d/%d] %d bytes of padding", bs, n, p)
+			}
+			out, err := UnpadSSH(packet, bs)
+			if err != nil {
+				t.Fatalf("[%d/%d] error unpadding: %v", bs, n, err)
+			}
+			if !bytes.Equal(out, payload) {
+				t.Errorf("[%d/%d] %x != %x", bs, n, out, payload)
+			}
+		}
+	}
+}
+
+func TestPadSSHLargeBlocks(t *testing.T) {
+	t.Parallel()
+
+	for n := 0; n <= 2*252; n++ {
+		payload := bytes.Repeat([]byte{0x5a}, n)
+		packet, err := PadSSH(payload, 252, nil)
+		if err != nil {
+			t.Fatalf("[%d] error padding: %v", n, err)
+		}
+		if len(packet)%252 != 0 {
+			t.Errorf("[%d] packet of %d bytes is misaligned", n, len(packet))
+		}
+		if out, err := UnpadSSH(packet, 252); err != nil || !bytes.Equal(out, payload) {
+			t.Errorf("[%d] UnpadSSH = %x, %v", n, out, err)
+		}
+	}
+	for _, bs := range []int{253, 254, 255} {
+		if _, err := PadSSH([]byte("x"), bs, nil); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[%d] expected ErrBlockSize, got %v", bs, err)
+		}
+	}
+}
+
+func TestUnpadSSHErrors(t *testing.T) {
+	t.Parallel()
+
+	good, err := PadSSH([]byte("YELLOW SUBMARINE"), 16, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	with := func(f func([]byte)) []byte {
+		b := bytes.Clone(good)
+		f(b)
+		return b
+	}
+	bad := [][]byte{
+		{},
+		make([]byte, 8),
+		good[:len(good)-1],
+		good[:16],
+		with(func(b []byte) { b[3]++ }),
+		with(func(b []byte) { b[4] = 3 }),
+		with(func(b []byte) { b[4] = byte(len(b) - 4) }),
+	}
+	for i, packet := range bad {
+		if _, err := UnpadSSH(packet, 16); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+	// The whole body may be padding, for an empty payload.
+	empty := with(func(b []byte) { b[4] = byte(len(b) - 5) })
+	if out, err := UnpadSSH(empty, 16); err != nil || len(out) != 0 {
+		t.Errorf("UnpadSSH of an empty payload = %x, %v", out, err)
+	}
+}
@@ -0,0 +1,64 @@
+package pkcs7pad
+
+import "fmt"
+
+// A StreamOption configures the internal buffer of an UnpadReader or a
+// PadWriter.
+type StreamOption func(*streamConfig)
+
+type streamConfig struct {
+	bufSize int
+	buf     []byte
+}
+
+// WithBufferSize sets the size of the internal buffer, in bytes. Small buffers
+// suit servers that handle many connections carrying small records, and large
+// ones reduce the number of calls to the underlying reader or writer during
+// bulk transfers. The size is rounded as each wrapper requires: an
+// UnpadReader's buffer holds at least two blocks, and a PadWriter's is a
+// non-zero multiple of the block size. It panics if n is not positive.
+func WithBufferSize(n int) StreamOption {
+	if n < 1 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate buffer size %d", n))
+	}
+	return func(c *streamConfig) {
+		c.bufSize = n
+	}
+}
+
+// WithBuffer makes the wrapper use buf, up to its capacity, as its internal
+// buffer instead of allocating one, for example so that buffers can be pooled.
+// The caller must not use buf while the wrapper is in use. The wrapper panics
+// if the capacity of buf is too small for its block size, as described by
+// WithBufferSize.
+func WithBuffer(buf []byte) StreamOption {
+	return func(c *streamConfig) {
+		c.buf = buf
+	}
+}
+
+func newStreamConfig(opts []StreamOption) streamConfig {
+	var c streamConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// buffer returns the configured buffer, which is defaultLen bytes long unless
+// a size or buffer was given. Sizes are rounded down to a multiple of align,
+// and at least minLen bytes are used.
+func (c streamConfig) buffer(defaultLen, minLen, align int) []byte {
+	if c.buf != nil {
+		n := cap(c.buf) - cap(c.buf)%align
+		if n < minLen {
+			panic(fmt.Sprintf("pkcs7pad: buffer of %d bytes is too small, need at least %d", cap(c.buf), minLen))
+		}
+		return c.buf[:n]
+	}
+	n := defaultLen
+	if c.bufSize != 0 {
+		n = c.bufSize
+	}
+	return make([]byte, max(minLen, n-n%align))
+}
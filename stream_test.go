@@ -0,0 +1,114 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// countingReader records the size of each read.
+type countingReader struct {
+	r     io.Reader
+	sizes []int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	cr.sizes = append(cr.sizes, len(p))
+	return cr.r.Read(p)
+}
+
+func TestUnpadReaderBuffer(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 100)
+	padded := Pad(bytes.Clone(in), aes.BlockSize)
+	tests := []struct {
+		opts    []StreamOption
+		maxRead int
+	}{
+		{nil, defaultBufSize + aes.BlockSize},
+		{[]StreamOption{WithBufferSize(1)}, 2 * aes.BlockSize},
+		{[]StreamOption{WithBufferSize(100)}, 100},
+		{[]StreamOption{WithBufferSize(1 << 16)}, 1 << 16},
+		{[]StreamOption{WithBuffer(make([]byte, 0, 40))}, 40},
+	}
+	for i, test := range tests {
+		cr := &countingReader{r: bytes.NewReader(padded)}
+		out, err := io.ReadAll(NewUnpadReader(cr, aes.BlockSize, test.opts...))
+		if err != nil || !bytes.Equal(out, in) {
+			t.Errorf("[%d] ReadAll = %d bytes, %v", i, len(out), err)
+		}
+		if cr.sizes[0] != test.maxRead {
+			t.Errorf("[%d] first read of %d bytes, want %d", i, cr.sizes[0], test.maxRead)
+		}
+	}
+
+	if err := iotest.TestReader(NewUnpadReader(bytes.NewReader(padded), aes.BlockSize, WithBufferSize(33)), in); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPadWriterBuffer(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 1000)
+	tests := []struct {
+		opts     []StreamOption
+		maxWrite int
+	}{
+		{nil, defaultBufSize},
+		{[]StreamOption{WithBufferSize(1)}, aes.BlockSize},
+		{[]StreamOption{WithBufferSize(100)}, 96},
+		{[]StreamOption{WithBuffer(make([]byte, 50))}, 48},
+	}
+	for i, test := range tests {
+		bw := &blockWriter{t: t}
+		cw := &countingWriter{w: bw}
+		pw := NewPadWriter(cw, aes.BlockSize, test.opts...)
+		if _, err := pw.ReadFrom(bytes.NewReader(in)); err != nil {
+			t.Fatal(err)
+		}
+		if err := pw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if want := Pad(bytes.Clone(in), aes.BlockSize); !bytes.Equal(bw.Bytes(), want) {
+			t.Errorf("[%d] wrote %d bytes, want %d", i, bw.Len(), len(want))
+		}
+		if cw.max != test.maxWrite {
+			t.Errorf("[%d] largest write was %d bytes, want %d", i, cw.max, test.maxWrite)
+		}
+	}
+}
+
+// countingWriter records the size of the largest write.
+type countingWriter struct {
+	w   io.Writer
+	max int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.max = max(cw.max, len(p))
+	return cw.w.Write(p)
+}
+
+func TestStreamOptionPanics(t *testing.T) {
+	t.Parallel()
+
+	tests := []func(){
+		func() { WithBufferSize(0) },
+		func() { NewUnpadReader(nil, 16, WithBuffer(make([]byte, 31))) },
+		func() { NewPadWriter(nil, 16, WithBuffer(make([]byte, 15))) },
+	}
+	for i, f := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("[%d] did not panic", i)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,80 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// PadTBC appends trailing bit complement padding, as implemented by Bouncy
+// Castle's TBCPadding, to the given buffer such that the resulting slice of
+// bytes has a length divisible by the given size. The padding bytes are all
+// 0xff if the last bit of the data is 0 (or there is no data), and all 0x00 if
+// it is 1, so that the padding can be told apart from the data. Like PKCS#7,
+// TBC always adds at least one byte of padding.
+func PadTBC(buf []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	var code byte = 0xff
+	if len(buf) > 0 && buf[len(buf)-1]&1 == 1 {
+		code = 0x00
+	}
+	i := size - (len(buf) % size)
+	for j := 0; j < i; j++ {
+		buf = append(buf, code)
+	}
+	return buf
+}
+
+// UnpadTBC returns a subslice of the input buffer with trailing TBC padding
+// removed. The padding is the run of bytes at the end of buf that are equal to
+// its final byte, so UnpadTBC needs the block size to know how long the run
+// may be. It returns an error wrapping ErrBadPadding if the length of buf is
+// not a non-zero multiple of size, if the final byte is neither 0x00 nor 0xff,
+// or if the last bit of the data is not the complement of the padding. The
+// final block is checked in constant time.
+func UnpadTBC(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+
+	code := buf[len(buf)-1]
+	padLen, ended, lastBit := 0, 0, 0
+	for i := 0; i < size; i++ {
+		b := buf[len(buf)-1-i]
+		same := subtle.ConstantTimeByteEq(b, code) & (ended ^ 1)
+		first := (same ^ 1) & (ended ^ 1)
+		lastBit = subtle.ConstantTimeSelect(first, int(b&1), lastBit)
+		padLen += same
+		ended |= first
+	}
+	// If the padding is a whole block, the last bit of the data is in the
+	// block before, if there is one.
+	hasData := ended
+	if len(buf) > size {
+		lastBit = subtle.ConstantTimeSelect(ended, lastBit, int(buf[len(buf)-1-size]&1))
+		hasData = 1
+	}
+
+	isFF := subtle.ConstantTimeByteEq(code, 0xff)
+	good := isFF | subtle.ConstantTimeByteEq(code, 0x00)
+	// 0xff padding follows a 0 bit, and 0x00 padding follows a 1 bit.
+	good &= (hasData ^ 1) | subtle.ConstantTimeEq(int32(lastBit), int32(isFF^1))
+	// Empty data is always padded with 0xff.
+	good &= hasData | isFF
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+	return buf[:len(buf)-padLen], nil
+}
+
+type tbc struct{}
+
+func (tbc) Name() string                    { return "tbc" }
+func (tbc) Pad(buf []byte, size int) []byte { return PadTBC(buf, size) }
+func (tbc) Unpad(buf []byte, size int) ([]byte, error) {
+	return UnpadTBC(buf, size)
+}
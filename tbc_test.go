@@ -0,0 +1,51 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPadTBC(t *testing.T) {
+	t.Parallel()
+
+	// These match the output of Bouncy Castle's TBCPadding.
+	tests := []struct {
+		in, out []byte
+	}{
+		{[]byte{}, bytes.Repeat([]byte{0xff}, 8)},
+		{[]byte{0x02}, []byte{0x02, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{[]byte{0x03}, []byte{0x03, 0, 0, 0, 0, 0, 0, 0}},
+		{[]byte("YELLOW"), []byte("YELLOW\x00\x00")},
+		{[]byte("YELLOWSX"), []byte("YELLOWSX\xff\xff\xff\xff\xff\xff\xff\xff")},
+		{[]byte("YELLOWSU"), []byte("YELLOWSU\x00\x00\x00\x00\x00\x00\x00\x00")},
+	}
+	for i, test := range tests {
+		if got := PadTBC(bytes.Clone(test.in), 8); !bytes.Equal(got, test.out) {
+			t.Errorf("[%d] %x != %x", i, got, test.out)
+		}
+		if got, err := UnpadTBC(test.out, 8); err != nil || !bytes.Equal(got, test.in) {
+			t.Errorf("[%d] UnpadTBC = %x, %v", i, got, err)
+		}
+	}
+}
+
+func TestUnpadTBCErrors(t *testing.T) {
+	t.Parallel()
+
+	bad := [][]byte{
+		{},
+		[]byte("YELLOW\x00"),
+		[]byte("YELLOWSU"),
+		[]byte("YELLOW\x02\x00"),
+		[]byte("YELLOW\x01\xff"),
+		bytes.Repeat([]byte{0}, 8),
+		append(bytes.Repeat([]byte{0xff}, 9), bytes.Repeat([]byte{0xff}, 7)...),
+		append([]byte("YELLOWSU"), bytes.Repeat([]byte{0xff}, 8)...),
+	}
+	for i, buf := range bad {
+		if _, err := UnpadTBC(buf, 8); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Generates node.csv using Node.js's crypto module (which uses OpenSSL's EVP
+// padding) as the reference implementation: node node.js > node.csv
+//
+// Padding is observed through a block cipher in ECB mode, since crypto does
+// not expose it directly.
+const crypto = require("crypto");
+
+const key = Buffer.from("interop vectors key 0123");
+const ciphers = { 8: ["des-ede3-ecb", key], 16: ["aes-128-ecb", key.subarray(0, 16)] };
+
+function crypt(size, encrypt, autoPadding, buf) {
+  const [name, k] = ciphers[size];
+  const c = encrypt ? crypto.createCipheriv(name, k, null) : crypto.createDecipheriv(name, k, null);
+  c.setAutoPadding(autoPadding);
+  return Buffer.concat([c.update(buf), c.final()]);
+}
+
+function pad(size, msg) {
+  return crypt(size, false, false, crypt(size, true, true, msg));
+}
+
+function unpad(size, buf) {
+  try {
+    return crypt(size, false, true, crypt(size, true, false, buf));
+  } catch (e) {
+    return null;
+  }
+}
+
+let state = 1;
+function bytes(n) {
+  const b = Buffer.alloc(n);
+  for (let i = 0; i < n; i++) {
+    state = (state * 1103515245 + 12345) % 2147483648;
+    b[i] = state >> 16;
+  }
+  return b;
+}
+
+console.log("block_size,input,padded,valid,comment");
+for (const size of [8, 16]) {
+  for (let n = 0; n <= 2 * size; n++) {
+    const msg = bytes(n);
+    console.log(`${size},${msg.toString("hex")},${pad(size, msg).toString("hex")},true,`);
+  }
+  const bad = [
+    ["final byte is zero", Buffer.concat([bytes(size - 1), Buffer.from([0])])],
+    ["padding length greater than the block size", Buffer.concat([bytes(2 * size - 1), Buffer.from([size + 1])])],
+    ["full block of padding with first byte corrupted", Buffer.concat([Buffer.from([size - 1]), Buffer.alloc(size - 1, size)])],
+    ["padding of length 3 with first byte wrong", Buffer.concat([bytes(size - 3), Buffer.from([2, 3, 3])])],
+  ];
+  for (const [comment, buf] of bad) {
+    if (unpad(size, buf) !== null) {
+      throw new Error(`${comment} is valid`);
+    }
+    console.log(`${size},,${buf.toString("hex")},false,${comment}`);
+  }
+}
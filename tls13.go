@@ -0,0 +1,52 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// maxTLS13InnerPlaintext is the largest TLSInnerPlaintext RFC 8446 allows: a
+// record of 2^14 bytes plus the content type.
+const maxTLS13InnerPlaintext = 1<<14 + 1
+
+// PadTLS13 returns the TLSInnerPlaintext for a TLS 1.3 record, as defined in
+// RFC 8446, section 5.4: the content followed by the nonzero content type and
+// then zeros, so that the result is exactly target bytes long. The result is
+// appended to content, so content may have spare capacity for it.
+//
+// https://tools.ietf.org/html/rfc8446#section-5.4
+//
+// An error is returned if contentType is zero, or if target is less than
+// len(content)+1 or more than the 2^14+1 bytes that RFC 8446 allows.
+func PadTLS13(content []byte, contentType byte, target int) ([]byte, error) {
+	if contentType == 0 {
+		return nil, errors.New("pkcs7pad: TLS 1.3 content type must be nonzero")
+	}
+	if target < len(content)+1 || target > maxTLS13InnerPlaintext {
+		return nil, fmt.Errorf("pkcs7pad: invalid TLS 1.3 record size %d for %d bytes of content", target, len(content))
+	}
+	n := len(content)
+	content = append(content, make([]byte, target-n)...)
+	content[n] = contentType
+	return content, nil
+}
+
+// UnpadTLS13 splits a decrypted TLSInnerPlaintext into its content, as a
+// subslice of buf, and its content type. It finds the content type by
+// scanning for the last nonzero byte, and returns an error wrapping
+// ErrBadPadding if there is none. The whole of buf is scanned, so the time
+// taken depends only on its length and not on the amount of padding.
+func UnpadTLS13(buf []byte) ([]byte, byte, error) {
+	var n, found, contentType int
+	for i, b := range buf {
+		nonzero := subtle.ConstantTimeByteEq(b, 0) ^ 1
+		n = subtle.ConstantTimeSelect(nonzero, i, n)
+		contentType = subtle.ConstantTimeSelect(nonzero, int(b), contentType)
+		found |= nonzero
+	}
+	if found != 1 {
+		return nil, 0, ErrBadPadding
+	}
+	return buf[:n], byte(contentType), nil
+}
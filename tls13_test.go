@@ -0,0 +1,60 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPadTLS13(t *testing.T) {
+	t.Parallel()
+
+	want := []byte{'h', 'i', 23, 0, 0, 0}
+	if got, err := PadTLS13([]byte("hi"), 23, 6); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("PadTLS13 = %x, %v; want %x", got, err, want)
+	}
+
+	for n := 0; n < 40; n++ {
+		content := bytes.Repeat([]byte{0}, n)
+		for target := n + 1; target < n+40; target++ {
+			padded, err := PadTLS13(bytes.Clone(content), 22, target)
+			if err != nil {
+				t.Fatalf("[%d/%d] error padding: %v", n, target, err)
+			}
+			if len(padded) != target {
+				t.Fatalf("[%d/%d] padded to %d bytes", n, target, len(padded))
+			}
+			out, typ, err := UnpadTLS13(padded)
+			if err != nil {
+				t.Fatalf("[%d/%d] error unpadding: %v", n, target, err)
+			}
+			if !bytes.Equal(out, content) || typ != 22 {
+				t.Fatalf("[%d/%d] got %x with type %d", n, target, out, typ)
+			}
+		}
+	}
+}
+
+func TestPadTLS13Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := PadTLS13(nil, 0, 16); err == nil {
+		t.Error("expected an error for a zero content type")
+	}
+	if _, err := PadTLS13(make([]byte, 16), 23, 16); err == nil {
+		t.Error("expected an error for a target with no room for the content type")
+	}
+	if _, err := PadTLS13(nil, 23, 1<<14+2); err == nil {
+		t.Error("expected an error for a target that is too large")
+	}
+}
+
+func TestUnpadTLS13Errors(t *testing.T) {
+	t.Parallel()
+
+	for i, buf := range [][]byte{nil, {0}, make([]byte, 100)} {
+		if _, _, err := UnpadTLS13(buf); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package tlscbc
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"testing"
+)
+
+func TestOpenBatch(t *testing.T) {
+	t.Parallel()
+
+	c := testCipher(t, sha1.New)
+	var records []Record
+	var want [][]byte
+	for i := 0; i < 50; i++ {
+		content := bytes.Repeat([]byte(fmt.Sprintf("record %d ", i)), i)
+		seq := uint64(i)
+		body := c.Seal(nil, testIV, seq, testType, testVersion, content)
+		switch i % 5 {
+		case 3:
+			body[len(body)-1] ^= 0x01
+			content = nil
+		case 4:
+			body = body[:len(body)-1]
+			content = nil
+		}
+		records = append(records, Record{seq, testType, testVersion, body})
+		want = append(want, content)
+	}
+
+	results := c.OpenBatch(records)
+	if len(results) != len(records) {
+		t.Fatalf("got %d results for %d records", len(results), len(records))
+	}
+	for i, r := range results {
+		if want[i] == nil {
+			if r.Err != ErrBadRecordMAC {
+				t.Errorf("[%d] expected %v, got %v", i, ErrBadRecordMAC, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("[%d] error opening: %v", i, r.Err)
+		}
+		if !bytes.Equal(r.Content, want[i]) {
+			t.Errorf("[%d] %q != %q", i, r.Content, want[i])
+		}
+	}
+
+	// Open must still work after the decrypter has been reused.
+	out, err := c.Open(nil, 7, testType, testVersion, records[7].Body)
+	if err != nil || !bytes.Equal(out, want[7]) {
+		t.Errorf("Open after OpenBatch = %q, %v", out, err)
+	}
+}
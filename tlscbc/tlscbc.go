@@ -0,0 +1,234 @@
+// Package tlscbc implements constant-time processing of TLS 1.1 and 1.2 (and
+// DTLS) records protected with a block cipher in CBC mode, using the original
+// MAC-then-encrypt construction.
+//
+// https://tools.ietf.org/html/rfc5246#section-6.2.3.2
+//
+// MAC-then-encrypt is the construction broken by padding oracle attacks such as
+// Lucky Thirteen, and defending against them requires that the padding check,
+// the MAC computation, and the MAC comparison all take the same amount of time
+// regardless of the contents of the record. This package is modeled on the
+// countermeasures in OpenSSL's tls1_cbc_remove_padding and ssl3_cbc_copy_mac
+// and in Go's crypto/tls. It exists for people implementing legacy protocols;
+// new protocols should use an AEAD.
+//
+// Note that TLS padding is not PKCS#7 padding: a record with n padding bytes
+// carries n+1 bytes, all equal to n.
+package tlscbc
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+// ErrBadRecordMAC is returned by Open for every kind of malformed record. TLS
+// deliberately does not distinguish between bad padding and a bad MAC.
+var ErrBadRecordMAC = errors.New("tlscbc: bad record MAC")
+
+// A Cipher seals and opens CBC records for a single direction of a connection.
+// It is not safe for concurrent use.
+type Cipher struct {
+	block cipher.Block
+	mac   hash.Hash
+	dec   cipher.BlockMode
+	buf   []byte
+}
+
+// New returns a Cipher that encrypts with the given block cipher and
+// authenticates with HMAC using the given hash function and key.
+func New(b cipher.Block, h func() hash.Hash, macKey []byte) *Cipher {
+	return &Cipher{block: b, mac: hmac.New(h, macKey)}
+}
+
+// Overhead returns the maximum number of bytes Seal adds to a record's
+// content: an explicit IV, a MAC, and a block of padding.
+func (c *Cipher) Overhead() int {
+	return 2*c.block.BlockSize() + c.mac.Size()
+}
+
+// Seal appends the encrypted record body for the given content to dst, and
+// returns the updated slice. The record body contains the explicit
+// initialization vector iv, which must be unpredictable and the length of the
+// block size. seq is the record's sequence number (for DTLS, the epoch in the
+// top 16 bits followed by the 48-bit sequence number), and typ and version are
+// copied from the record header.
+func (c *Cipher) Seal(dst, iv []byte, seq uint64, typ uint8, version uint16, content []byte) []byte {
+	bs := c.block.BlockSize()
+	if len(iv) != bs {
+		panic("tlscbc: incorrect IV length given to Seal")
+	}
+	macSize := c.mac.Size()
+	padLen := bs - (len(content)+macSize)%bs
+	n := len(content) + macSize + padLen
+
+	ret, out := sliceForAppend(dst, bs+n)
+	copy(out, iv)
+	body := out[bs:]
+	copy(body, content)
+	c.computeMAC(body[len(content):len(content)], seq, typ, version, content, nil)
+	for i := len(content) + macSize; i < n; i++ {
+		body[i] = byte(padLen - 1)
+	}
+	cipher.NewCBCEncrypter(c.block, iv).CryptBlocks(body, body)
+	return ret
+}
+
+// Open decrypts and authenticates a record body produced by Seal, appends the
+// record's content to dst, and returns the updated slice. seq, typ, and version
+// must match the values passed to Seal.
+//
+// Open checks the padding and the MAC in constant time, and always does the
+// same amount of work for records of a given length. dst and record must not
+// overlap.
+func (c *Cipher) Open(dst []byte, seq uint64, typ uint8, version uint16, record []byte) ([]byte, error) {
+	// The record length is public, so it's safe to reject bad ones early.
+	if !c.validLen(len(record)) {
+		return nil, ErrBadRecordMAC
+	}
+
+	ret, payload := sliceForAppend(dst, len(record)-c.block.BlockSize())
+	n, good := c.open(payload, seq, typ, version, record, 0)
+	if good != 1 {
+		return nil, ErrBadRecordMAC
+	}
+	return ret[:len(dst)+n], nil
+}
+
+// validLen reports whether a record body of the given length could have been
+// produced by Seal.
+func (c *Cipher) validLen(n int) bool {
+	bs := c.block.BlockSize()
+	minLen := bs + max(bs, (c.mac.Size()+1+bs-1)/bs*bs)
+	return n >= minLen && n%bs == 0
+}
+
+// open decrypts the record body into payload, which must be exactly as long as
+// the ciphertext, and returns the length of the content along with 1 if the
+// record is authentic and 0 otherwise. The payload is zeroed if it is not. The
+// MAC computation is followed by dummy hashing of pad bytes, as though the
+// payload were that much longer.
+func (c *Cipher) open(payload []byte, seq uint64, typ uint8, version uint16, record []byte, pad int) (int, int) {
+	bs := c.block.BlockSize()
+	macSize := c.mac.Size()
+	iv, ciphertext := record[:bs], record[bs:]
+	c.decrypter(iv).CryptBlocks(payload, ciphertext)
+
+	// Following tls1_cbc_remove_padding, bad padding is treated as if there
+	// were no padding at all, so that we go on to compute the MAC over the
+	// same amount of data either way.
+	toRemove, good := extractPadding(payload, macSize)
+	n := len(payload) - macSize - toRemove
+
+	if cap(c.buf) < 2*macSize {
+		c.buf = make([]byte, 2*macSize)
+	}
+	remoteMAC := c.buf[:macSize]
+	copyMAC(remoteMAC, payload, n)
+	localMAC := c.computeMAC(c.buf[macSize:macSize], seq, typ, version, payload[:n], payload[n+macSize:])
+	for pad > 0 {
+		k := min(pad, len(zeros))
+		c.mac.Write(zeros[:k])
+		pad -= k
+	}
+	good &= subtle.ConstantTimeCompare(localMAC, remoteMAC)
+
+	if good != 1 {
+		clear(payload)
+	}
+	return n, good
+}
+
+// decrypter returns a CBC decrypter with the given IV, reusing the previous
+// one if the block mode supports it.
+func (c *Cipher) decrypter(iv []byte) cipher.BlockMode {
+	if c.dec != nil {
+		c.dec.(ivSetter).SetIV(iv)
+		return c.dec
+	}
+	dec := cipher.NewCBCDecrypter(c.block, iv)
+	if _, ok := dec.(ivSetter); ok {
+		c.dec = dec
+	}
+	return dec
+}
+
+// ivSetter is implemented by the standard library's CBC block modes.
+type ivSetter interface {
+	SetIV([]byte)
+}
+
+var zeros [256]byte
+
+// computeMAC appends the TLS MAC of the given record to out.
+//
+// Once the MAC has been computed, extra is fed into the hash as well. The
+// result is discarded, but it means that the hash function processes the same
+// number of bytes, and so runs roughly the same number of compression
+// functions, no matter how the record was split between content and padding.
+func (c *Cipher) computeMAC(out []byte, seq uint64, typ uint8, version uint16, content, extra []byte) []byte {
+	var header [13]byte
+	binary.BigEndian.PutUint64(header[0:], seq)
+	header[8] = typ
+	binary.BigEndian.PutUint16(header[9:], version)
+	binary.BigEndian.PutUint16(header[11:], uint16(len(content)))
+
+	c.mac.Reset()
+	c.mac.Write(header[:])
+	c.mac.Write(content)
+	out = c.mac.Sum(out)
+	c.mac.Write(extra)
+	return out
+}
+
+// extractPadding returns the number of padding bytes (including the length
+// byte) at the end of payload, along with 1 if the padding is well-formed and
+// leaves room for a MAC of the given size, and 0 otherwise. If the padding is
+// malformed, the number of bytes to remove is 0. It runs in constant time.
+func extractPadding(payload []byte, macSize int) (toRemove int, good int) {
+	padLen := int(payload[len(payload)-1])
+	good = subtle.ConstantTimeLessOrEq(padLen+1+macSize, len(payload))
+
+	// The padding is at most 255 bytes plus the length byte itself.
+	toCheck := min(256, len(payload))
+	for i := 0; i < toCheck; i++ {
+		b := payload[len(payload)-1-i]
+		inPadding := subtle.ConstantTimeLessOrEq(i, padLen)
+		equal := subtle.ConstantTimeByteEq(byte(padLen), b)
+		good &= subtle.ConstantTimeSelect(inPadding, equal, 1)
+	}
+
+	toRemove = subtle.ConstantTimeSelect(good, padLen+1, 0)
+	return toRemove, good
+}
+
+// copyMAC copies the len(out) bytes of payload starting at the secret offset n
+// into out. Following ssl3_cbc_copy_mac, it touches every byte that could
+// possibly be part of the MAC, regardless of where the MAC actually is.
+func copyMAC(out, payload []byte, n int) {
+	clear(out)
+	scanStart := max(0, len(payload)-len(out)-256)
+	for j := scanStart; j < len(payload); j++ {
+		b := payload[j]
+		for k := range out {
+			mask := byte(subtle.ConstantTimeEq(int32(j), int32(n+k)))
+			out[k] |= b & -mask
+		}
+	}
+}
+
+// sliceForAppend extends in by n bytes, returning the extended slice along
+// with the n bytes that were added.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
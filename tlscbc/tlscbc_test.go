@@ -0,0 +1,127 @@
+package tlscbc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"hash"
+	"testing"
+)
+
+var (
+	testEncKey = []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	testMACKey = []byte("a MAC key the length of which doesn't matter")
+	testIV     = []byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff}
+)
+
+const (
+	testSeq     = 0x0001000000000007
+	testType    = 23
+	testVersion = 0x0303
+)
+
+func testCipher(t testing.TB, h func() hash.Hash) *Cipher {
+	b, err := aes.NewCipher(testEncKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(b, h, testMACKey)
+}
+
+func TestSealOpen(t *testing.T) {
+	t.Parallel()
+
+	for _, h := range []func() hash.Hash{sha1.New, sha256.New} {
+		c := testCipher(t, h)
+		content := bytes.Repeat([]byte("bonjour "), 20)
+		for n := 0; n <= len(content); n++ {
+			record := c.Seal(nil, testIV, testSeq, testType, testVersion, content[:n])
+			if len(record)%aes.BlockSize != 0 || len(record) > n+c.Overhead() {
+				t.Errorf("[%d] bad record length %d", n, len(record))
+			}
+			out, err := c.Open(nil, testSeq, testType, testVersion, record)
+			if err != nil {
+				t.Errorf("[%d] error opening: %v", n, err)
+			}
+			if !bytes.Equal(out, content[:n]) {
+				t.Errorf("[%d] %x != %x", n, out, content[:n])
+			}
+		}
+	}
+}
+
+// sealWithPadding builds a record by hand, so that tests can use long or
+// malformed padding.
+func sealWithPadding(c *Cipher, content, padding []byte) []byte {
+	var body []byte
+	body = append(body, content...)
+	body = c.computeMAC(body, testSeq, testType, testVersion, content, nil)
+	body = append(body, padding...)
+	cipher.NewCBCEncrypter(c.block, testIV).CryptBlocks(body, body)
+	return append(append([]byte(nil), testIV...), body...)
+}
+
+func TestOpenLongPadding(t *testing.T) {
+	t.Parallel()
+
+	c := testCipher(t, sha1.New)
+	content := []byte("hello, world")
+	// 12 + 20 + 64 = 96 bytes, with 63 bytes of padding plus the length.
+	record := sealWithPadding(c, content, bytes.Repeat([]byte{63}, 64))
+	out, err := c.Open(nil, testSeq, testType, testVersion, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, content) {
+		t.Errorf("%q != %q", out, content)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	t.Parallel()
+
+	c := testCipher(t, sha1.New)
+	content := []byte("hello, world")
+	good := c.Seal(nil, testIV, testSeq, testType, testVersion, content)
+
+	bad := [][]byte{
+		nil,
+		good[:len(good)-1],
+		good[:2*aes.BlockSize],
+		sealWithPadding(c, content, bytes.Repeat([]byte{16}, 16)),
+		sealWithPadding(c, content, []byte{14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 13, 14, 14}),
+		sealWithPadding(c, content, bytes.Repeat([]byte{200}, 16)),
+	}
+	for i := range good {
+		record := append([]byte(nil), good...)
+		record[i] ^= 0x80
+		bad = append(bad, record)
+	}
+	for i, record := range bad {
+		if _, err := c.Open(nil, testSeq, testType, testVersion, record); err != ErrBadRecordMAC {
+			t.Errorf("[%d] expected %v, got %v", i, ErrBadRecordMAC, err)
+		}
+	}
+
+	if _, err := c.Open(nil, testSeq+1, testType, testVersion, good); err != ErrBadRecordMAC {
+		t.Errorf("wrong sequence number: expected %v, got %v", ErrBadRecordMAC, err)
+	}
+}
+
+func TestCopyMAC(t *testing.T) {
+	t.Parallel()
+
+	payload := make([]byte, 300)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	out := make([]byte, 20)
+	for n := len(payload) - 20 - 256; n <= len(payload)-20; n++ {
+		copyMAC(out, payload, n)
+		if !bytes.Equal(out, payload[n:n+20]) {
+			t.Fatalf("[%d] %x != %x", n, out, payload[n:n+20])
+		}
+	}
+}
@@ -0,0 +1,24 @@
+//go:build gc && !purego
+
+package pkcs7pad
+
+func checkPaddingBytes(buf []byte, padLen byte) int {
+	if !useAVX2 {
+		return checkPaddingGeneric(buf, padLen)
+	}
+	// Copy the bytes that could be padding into the end of a fixed-size
+	// array, so the assembly always does the same amount of work and never
+	// needs to handle a partial vector. How much we copy depends only on
+	// len(buf). The zeros in front can only be mistaken for padding when
+	// padLen > len(buf), which checkPadding rejects.
+	var a [256]byte
+	toCheck := min(255, len(buf))
+	copy(a[256-toCheck:], buf[len(buf)-toCheck:])
+	return checkPadding256AVX2(&a, padLen)
+}
+
+// checkPadding256AVX2 returns 1 if each of the last padLen bytes of a is equal
+// to padLen, and 0 otherwise.
+//
+//go:noescape
+func checkPadding256AVX2(a *[256]byte, padLen byte) int
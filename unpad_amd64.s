@@ -0,0 +1,61 @@
+//go:build gc && !purego
+
+#include "textflag.h"
+
+DATA positions32<>+0x00(SB)/8, $0x0706050403020100
+DATA positions32<>+0x08(SB)/8, $0x0f0e0d0c0b0a0908
+DATA positions32<>+0x10(SB)/8, $0x1716151413121110
+DATA positions32<>+0x18(SB)/8, $0x1f1e1d1c1b1a1918
+GLOBL positions32<>(SB), RODATA|NOPTR, $32
+
+DATA thirtytwos<>+0x00(SB)/8, $0x2020202020202020
+DATA thirtytwos<>+0x08(SB)/8, $0x2020202020202020
+DATA thirtytwos<>+0x10(SB)/8, $0x2020202020202020
+DATA thirtytwos<>+0x18(SB)/8, $0x2020202020202020
+GLOBL thirtytwos<>(SB), RODATA|NOPTR, $32
+
+// func checkPadding256AVX2(a *[256]byte, padLen byte) int
+//
+// Byte j of a is part of the padding iff j >= 256-padLen, and every such byte
+// must equal padLen. All 256 bytes are examined, and there are no
+// data-dependent branches.
+TEXT ·checkPadding256AVX2(SB), NOSPLIT, $0-24
+	MOVQ a+0(FP), SI
+	MOVBLZX padLen+8(FP), AX
+	MOVL AX, BX
+	NEGL BX
+
+	// Y1 = 256-padLen (mod 256), Y2 = padLen, in every byte.
+	VMOVQ BX, X1
+	VPBROADCASTB X1, Y1
+	VMOVQ AX, X2
+	VPBROADCASTB X2, Y2
+
+	VMOVDQU positions32<>(SB), Y8
+	VMOVDQU thirtytwos<>(SB), Y9
+	VPXOR Y7, Y7, Y7
+	MOVQ $8, CX
+
+loop:
+	VMOVDQU (SI), Y0
+
+	// Y3 = bytes that are part of the padding (j >= 256-padLen).
+	VPMAXUB Y1, Y8, Y3
+	VPCMPEQB Y8, Y3, Y3
+
+	// Accumulate padding bytes that aren't equal to padLen into Y7.
+	VPCMPEQB Y2, Y0, Y4
+	VPANDN Y3, Y4, Y5
+	VPOR Y5, Y7, Y7
+
+	VPADDB Y9, Y8, Y8
+	ADDQ $32, SI
+	DECQ CX
+	JNZ loop
+
+	XORQ AX, AX
+	VPTEST Y7, Y7
+	SETEQ AL
+	MOVQ AX, ret+16(FP)
+	VZEROUPPER
+	RET
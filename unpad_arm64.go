@@ -0,0 +1,17 @@
+//go:build gc && !purego
+
+package pkcs7pad
+
+func checkPaddingBytes(buf []byte, padLen byte) int {
+	// See unpad_amd64.go for why this copy is safe.
+	var a [256]byte
+	toCheck := min(255, len(buf))
+	copy(a[256-toCheck:], buf[len(buf)-toCheck:])
+	return checkPadding256NEON(&a, padLen)
+}
+
+// checkPadding256NEON returns 1 if each of the last padLen bytes of a is equal
+// to padLen, and 0 otherwise.
+//
+//go:noescape
+func checkPadding256NEON(a *[256]byte, padLen byte) int
@@ -0,0 +1,49 @@
+//go:build gc && !purego
+
+#include "textflag.h"
+
+DATA positions16<>+0x00(SB)/8, $0x0706050403020100
+DATA positions16<>+0x08(SB)/8, $0x0f0e0d0c0b0a0908
+GLOBL positions16<>(SB), RODATA|NOPTR, $16
+
+// func checkPadding256NEON(a *[256]byte, padLen byte) int
+//
+// Byte j of a is part of the padding iff j >= 256-padLen, and every such byte
+// must equal padLen. All 256 bytes are examined, and there are no
+// data-dependent branches.
+TEXT ·checkPadding256NEON(SB), NOSPLIT, $0-24
+	MOVD a+0(FP), R0
+	MOVBU padLen+8(FP), R1
+	NEG R1, R2
+
+	// V1 = 256-padLen (mod 256), V2 = padLen, in every byte.
+	VDUP R2, V1.B16
+	VDUP R1, V2.B16
+
+	MOVD $positions16<>(SB), R3
+	VLD1 (R3), [V8.B16]
+	VMOVI $16, V9.B16
+	VEOR V7.B16, V7.B16, V7.B16
+	MOVD $16, R3
+
+loop:
+	VLD1.P 16(R0), [V0.B16]
+
+	// V3 = bytes that are part of the padding (j >= 256-padLen).
+	VCMHS V1.B16, V8.B16, V3.B16
+
+	// Accumulate padding bytes that aren't equal to padLen into V7.
+	VCMEQ V2.B16, V0.B16, V4.B16
+	VBIC V4.B16, V3.B16, V5.B16
+	VORR V5.B16, V7.B16, V7.B16
+
+	VADD V9.B16, V8.B16, V8.B16
+	SUBS $1, R3, R3
+	BNE loop
+
+	VUMAXV V7.B16, V6
+	VMOV V6.B[0], R4
+	CMP $0, R4
+	CSET EQ, R5
+	MOVD R5, ret+16(FP)
+	RET
@@ -0,0 +1,7 @@
+//go:build !(amd64 || arm64) || !gc || purego
+
+package pkcs7pad
+
+func checkPaddingBytes(buf []byte, padLen byte) int {
+	return checkPaddingGeneric(buf, padLen)
+}
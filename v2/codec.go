@@ -0,0 +1,44 @@
+package pkcs7pad
+
+import "errors"
+
+// A Codec pads and unpads buffers using a fixed Scheme and block size. Codecs
+// are immutable and safe for concurrent use. The zero Codec is not usable; use
+// NewCodec to create one.
+type Codec struct {
+	scheme Scheme
+	size   int
+}
+
+// NewCodec returns a Codec for the given padding scheme and block size. It
+// returns an error if the scheme is nil, or an error wrapping ErrBlockSize if
+// the block size is not between 1 and 255.
+func NewCodec(s Scheme, size int) (Codec, error) {
+	if s == nil {
+		return Codec{}, errors.New("pkcs7pad: nil padding scheme")
+	}
+	if err := checkSize(size); err != nil {
+		return Codec{}, err
+	}
+	return Codec{scheme: s, size: size}, nil
+}
+
+// Scheme returns the codec's padding scheme.
+func (c Codec) Scheme() Scheme {
+	return c.scheme
+}
+
+// BlockSize returns the codec's block size.
+func (c Codec) BlockSize() int {
+	return c.size
+}
+
+// AppendPad appends src followed by its padding to dst.
+func (c Codec) AppendPad(dst, src []byte) ([]byte, error) {
+	return c.scheme.AppendPad(dst, src, c.size)
+}
+
+// Unpad returns a subslice of buf with its padding removed.
+func (c Codec) Unpad(buf []byte) ([]byte, error) {
+	return c.scheme.Unpad(buf, c.size)
+}
@@ -0,0 +1,38 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewCodec(ISO7816, aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Scheme() != ISO7816 || c.BlockSize() != aes.BlockSize {
+		t.Errorf("got %s/%d", c.Scheme().Name(), c.BlockSize())
+	}
+	out, err := c.AppendPad(nil, testString[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpad, err := c.Unpad(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unpad, testString[:3]) {
+		t.Errorf("%x != %x", unpad, testString[:3])
+	}
+
+	if _, err := NewCodec(nil, aes.BlockSize); err == nil {
+		t.Error("expected an error for a nil scheme")
+	}
+	if _, err := NewCodec(PKCS7, 0); !errors.Is(err, ErrBlockSize) {
+		t.Errorf("expected ErrBlockSize, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Package pkcs7pad is version 2 of github.com/zenazn/pkcs7pad, which implements
+// PKCS#7 padding as defined in RFC 5652, along with several other block cipher
+// padding schemes.
+//
+// https://tools.ietf.org/html/rfc5652#section-6.3
+//
+// Version 2 differs from version 1 in three ways:
+//
+//   - Functions that produce output take a destination slice first and append
+//     to it, like the encoding and crypto/cipher packages, so the caller always
+//     decides where memory comes from.
+//   - Invalid block sizes and other caller mistakes are reported as errors
+//     rather than panics.
+//   - Scheme and Codec are the primary API. The PKCS#7 functions at the top
+//     level are shorthand for the PKCS7 scheme.
+//
+// The constant-time validation is shared with version 1, and the two versions
+// return the same error values, so they can be used side by side during a
+// migration.
+package pkcs7pad
+
+import (
+	"fmt"
+
+	v1 "github.com/zenazn/pkcs7pad"
+)
+
+// ErrBadPadding is returned when padding is malformed. Errors that carry more
+// detail wrap it, so callers should test for it with errors.Is. It is the same
+// value as in version 1.
+//
+// To avoid creating a padding oracle, callers decrypting attacker-controlled
+// ciphertext should take care not to reveal this error, or the time at which
+// it was returned, to the attacker.
+var ErrBadPadding = v1.ErrBadPadding
+
+// ErrBlockSize is wrapped by the errors returned when a block size is not
+// between 1 and 255. It is the same value as in version 1.
+var ErrBlockSize = v1.ErrBlockSize
+
+func checkSize(size int) error {
+	if size < 1 || size > 255 {
+		return fmt.Errorf("%w %d", ErrBlockSize, size)
+	}
+	return nil
+}
+
+// AppendPad appends src followed by its PKCS#7 padding to dst and returns the
+// extended buffer. It is shorthand for PKCS7.AppendPad.
+func AppendPad(dst, src []byte, size int) ([]byte, error) {
+	return PKCS7.AppendPad(dst, src, size)
+}
+
+// Unpad returns a subslice of buf with its PKCS#7 padding removed. The padding
+// is checked in constant time. It returns an error wrapping ErrBadPadding if
+// the padding is malformed or the length of buf is not a non-zero multiple of
+// size. It is shorthand for PKCS7.Unpad.
+func Unpad(buf []byte, size int) ([]byte, error) {
+	return PKCS7.Unpad(buf, size)
+}
+
+// PaddedLen returns the length of n bytes after PKCS#7 padding, so that callers
+// can size destination buffers.
+func PaddedLen(n, size int) (int, error) {
+	if err := checkSize(size); err != nil {
+		return 0, err
+	}
+	return v1.PaddedLen(n, size), nil
+}
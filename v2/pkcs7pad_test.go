@@ -0,0 +1,71 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+
+	v1 "github.com/zenazn/pkcs7pad"
+)
+
+var testString = []byte{0xde, 0xad, 0xbe, 0xef, 0xba, 0xad, 0xf0, 0x0d, 0xad, 0x15, 0xea, 0x5e, 0xfe, 0xe1, 0xde, 0xad}
+
+func TestAppendPad(t *testing.T) {
+	t.Parallel()
+
+	header := []byte{0xff, 0xfe}
+	for n := 0; n <= len(testString); n++ {
+		want := append(bytes.Clone(header), v1.Pad(bytes.Clone(testString[:n]), aes.BlockSize)...)
+		out, err := AppendPad(header[:len(header):len(header)], testString[:n], aes.BlockSize)
+		if err != nil {
+			t.Errorf("[%d] error padding: %v", n, err)
+		}
+		if !bytes.Equal(out, want) {
+			t.Errorf("[%d] %x != %x", n, out, want)
+		}
+
+		unpad, err := Unpad(out[len(header):], aes.BlockSize)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", n, err)
+		}
+		if !bytes.Equal(unpad, testString[:n]) {
+			t.Errorf("[%d] %x != %x", n, unpad, testString[:n])
+		}
+
+		if l, err := PaddedLen(n, aes.BlockSize); err != nil || l != len(want)-len(header) {
+			t.Errorf("[%d] PaddedLen %d, %v", n, l, err)
+		}
+	}
+}
+
+func TestBlockSizeErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{-1, 0, 256} {
+		if _, err := AppendPad(nil, testString, size); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[%d] AppendPad: expected ErrBlockSize, got %v", size, err)
+		}
+		if _, err := Unpad(testString, size); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[%d] Unpad: expected ErrBlockSize, got %v", size, err)
+		}
+		if _, err := PaddedLen(1, size); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[%d] PaddedLen: expected ErrBlockSize, got %v", size, err)
+		}
+	}
+}
+
+func TestUnpadBad(t *testing.T) {
+	t.Parallel()
+
+	bad := [][]byte{
+		{},
+		testString[:15],
+		bytes.Repeat([]byte{0x11}, 16),
+	}
+	for i, test := range bad {
+		if _, err := Unpad(test, aes.BlockSize); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package pkcs7pad
+
+import (
+	"fmt"
+	"slices"
+
+	v1 "github.com/zenazn/pkcs7pad"
+)
+
+// A Scheme is a block cipher padding scheme.
+//
+// AppendPad appends src followed by its padding to dst, such that the padded
+// part has a length that is a multiple of size. Unpad returns a subslice of buf
+// with its padding removed. Both return an error wrapping ErrBlockSize if size
+// is not between 1 and 255, and Unpad returns an error wrapping ErrBadPadding
+// if the padding is malformed or buf is not a multiple of size.
+type Scheme interface {
+	// Name returns the name of the scheme, e.g. "pkcs7".
+	Name() string
+	AppendPad(dst, src []byte, size int) ([]byte, error)
+	Unpad(buf []byte, size int) ([]byte, error)
+}
+
+// The padding schemes implemented by this package. They have the same names
+// as in version 1.
+var (
+	PKCS7    Scheme = FromV1(v1.PKCS7)
+	X923     Scheme = FromV1(v1.X923)
+	ISO7816  Scheme = FromV1(v1.ISO7816)
+	ISO10126 Scheme = iso10126{}
+	Zero     Scheme = FromV1(v1.Zero)
+	None     Scheme = none{}
+
+	ISO9797M1 Scheme = FromV1(v1.ISO9797M1)
+	ISO9797M2 Scheme = FromV1(v1.ISO9797M2)
+	TBC       Scheme = FromV1(v1.TBC)
+)
+
+// Lookup returns the padding scheme with the given name. Schemes registered
+// with version 1's Register function are available too.
+func Lookup(name string) (Scheme, error) {
+	for _, s := range []Scheme{ISO10126, None} {
+		if s.Name() == name {
+			return s, nil
+		}
+	}
+	s, err := v1.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return FromV1(s), nil
+}
+
+// FromV1 adapts a version 1 Scheme to the version 2 interface. The adapter
+// validates the block size before calling s, so s never panics because of it.
+func FromV1(s v1.Scheme) Scheme {
+	return v1Scheme{s}
+}
+
+type v1Scheme struct {
+	s v1.Scheme
+}
+
+func (a v1Scheme) Name() string { return a.s.Name() }
+
+func (a v1Scheme) AppendPad(dst, src []byte, size int) ([]byte, error) {
+	if err := checkSize(size); err != nil {
+		return nil, err
+	}
+	// Leave room for the longest possible padding, so that padding src in
+	// place at the end of dst doesn't need to allocate again.
+	n := len(dst)
+	dst = slices.Grow(dst, len(src)+size)
+	dst = append(dst, src...)
+	return append(dst[:n], a.s.Pad(dst[n:], size)...), nil
+}
+
+func (a v1Scheme) Unpad(buf []byte, size int) ([]byte, error) {
+	if err := checkSize(size); err != nil {
+		return nil, err
+	}
+	return a.s.Unpad(buf, size)
+}
+
+// iso10126 reports failures to read random bytes, which version 1's scheme
+// can only panic on.
+type iso10126 struct{}
+
+func (iso10126) Name() string { return v1.ISO10126.Name() }
+
+func (iso10126) AppendPad(dst, src []byte, size int) ([]byte, error) {
+	if err := checkSize(size); err != nil {
+		return nil, err
+	}
+	n := len(dst)
+	dst = slices.Grow(dst, len(src)+size)
+	dst = append(dst, src...)
+	padded, err := v1.PadISO10126(dst[n:], size, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst[:n], padded...), nil
+}
+
+func (iso10126) Unpad(buf []byte, size int) ([]byte, error) {
+	return FromV1(v1.ISO10126).Unpad(buf, size)
+}
+
+// none returns an error for misaligned input, which version 1's scheme panics
+// on.
+type none struct{}
+
+func (none) Name() string { return v1.None.Name() }
+
+func (none) AppendPad(dst, src []byte, size int) ([]byte, error) {
+	if err := checkSize(size); err != nil {
+		return nil, err
+	}
+	if len(src)%size != 0 {
+		return nil, fmt.Errorf("pkcs7pad: length %d is not a multiple of block size %d", len(src), size)
+	}
+	return append(dst, src...), nil
+}
+
+func (none) Unpad(buf []byte, size int) ([]byte, error) {
+	return FromV1(v1.None).Unpad(buf, size)
+}
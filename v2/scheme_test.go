@@ -0,0 +1,74 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+
+	v1 "github.com/zenazn/pkcs7pad"
+)
+
+func TestSchemes(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"pkcs7", "x923", "iso7816", "iso10126", "zero", "none", "iso9797-m1", "iso9797-m2", "tbc"} {
+		s, err := Lookup(name)
+		if err != nil {
+			t.Fatalf("[%s] %v", name, err)
+		}
+		if s.Name() != name {
+			t.Errorf("[%s] got scheme %s", name, s.Name())
+		}
+		for n := 0; n <= 2*len(testString); n++ {
+			src := bytes.Repeat(testString, 2)[:n]
+			out, err := s.AppendPad([]byte{0xff}, src, aes.BlockSize)
+			if name == "none" && n%aes.BlockSize != 0 {
+				if err == nil {
+					t.Errorf("[%s/%d] expected an error", name, n)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("[%s/%d] error padding: %v", name, n, err)
+				continue
+			}
+			if out[0] != 0xff || (len(out)-1)%aes.BlockSize != 0 {
+				t.Errorf("[%s/%d] bad output %x", name, n, out)
+			}
+			if name == "zero" && n > 0 && src[n-1] == 0 {
+				continue
+			}
+			unpad, err := s.Unpad(out[1:], aes.BlockSize)
+			if err != nil {
+				t.Errorf("[%s/%d] error unpadding: %v", name, n, err)
+			}
+			if !bytes.Equal(unpad, src) {
+				t.Errorf("[%s/%d] %x != %x", name, n, unpad, src)
+			}
+		}
+		if _, err := s.AppendPad(nil, nil, 0); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[%s] expected ErrBlockSize, got %v", name, err)
+		}
+	}
+
+	if _, err := Lookup("rot13"); err == nil {
+		t.Error("expected an error for an unknown scheme")
+	}
+}
+
+func TestFromV1(t *testing.T) {
+	t.Parallel()
+
+	s := FromV1(v1.X923)
+	out, err := s.AppendPad(nil, testString[:5], 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := v1.PadX923(bytes.Clone(testString[:5]), 8); !bytes.Equal(out, want) {
+		t.Errorf("%x != %x", out, want)
+	}
+	if _, err := s.Unpad(out, 256); !errors.Is(err, ErrBlockSize) {
+		t.Errorf("expected ErrBlockSize, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build gc && !purego
+
+package pkcs7pad
+
+// validate16Stride is the number of blocks validate16Blocks handles at once.
+const validate16Stride = 2
+
+func haveValidate16() bool {
+	return useAVX2
+}
+
+func validate16Blocks(ok *byte, blocks *byte, n int) {
+	validate16AVX2(ok, blocks, n/2)
+}
+
+// validate16AVX2 checks the PKCS#7 padding of 2*pairs consecutive 16-byte
+// blocks, setting ok[i] to 1 if block i is well-formed and 0 otherwise.
+//
+//go:noescape
+func validate16AVX2(ok *byte, blocks *byte, pairs int)
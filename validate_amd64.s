@@ -0,0 +1,78 @@
+//go:build gc && !purego
+
+#include "textflag.h"
+
+// Byte positions within each 128-bit lane.
+DATA positions<>+0x00(SB)/8, $0x0706050403020100
+DATA positions<>+0x08(SB)/8, $0x0f0e0d0c0b0a0908
+DATA positions<>+0x10(SB)/8, $0x0706050403020100
+DATA positions<>+0x18(SB)/8, $0x0f0e0d0c0b0a0908
+GLOBL positions<>(SB), RODATA|NOPTR, $32
+
+DATA fifteens<>+0x00(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA fifteens<>+0x08(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA fifteens<>+0x10(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA fifteens<>+0x18(SB)/8, $0x0f0f0f0f0f0f0f0f
+GLOBL fifteens<>(SB), RODATA|NOPTR, $32
+
+DATA sixteens<>+0x00(SB)/8, $0x1010101010101010
+DATA sixteens<>+0x08(SB)/8, $0x1010101010101010
+DATA sixteens<>+0x10(SB)/8, $0x1010101010101010
+DATA sixteens<>+0x18(SB)/8, $0x1010101010101010
+GLOBL sixteens<>(SB), RODATA|NOPTR, $32
+
+// func validate16AVX2(ok *byte, blocks *byte, pairs int)
+//
+// Each iteration checks two blocks, one per 128-bit lane. For a block whose
+// final byte is p, byte i is part of the padding iff i+p >= 16, and every such
+// byte must equal p. Separately, p must be between 1 and 16. There are no
+// data-dependent branches.
+TEXT ·validate16AVX2(SB), NOSPLIT, $0-24
+	MOVQ ok+0(FP), DI
+	MOVQ blocks+8(FP), SI
+	MOVQ pairs+16(FP), CX
+	VMOVDQU positions<>(SB), Y8
+	VMOVDQU fifteens<>(SB), Y9
+	VMOVDQU sixteens<>(SB), Y10
+	VPCMPEQB Y11, Y11, Y11
+	TESTQ CX, CX
+	JZ done
+
+loop:
+	VMOVDQU (SI), Y0
+
+	// Y1 = the final byte of each block, broadcast across its lane.
+	VPSHUFB Y9, Y0, Y1
+
+	// Y3 = bytes that are part of the padding (i+p >= 16).
+	VPADDUSB Y8, Y1, Y2
+	VPMAXUB Y10, Y2, Y3
+	VPCMPEQB Y2, Y3, Y3
+
+	// Y5 = padding bytes that aren't equal to p.
+	VPCMPEQB Y1, Y0, Y4
+	VPANDN Y3, Y4, Y5
+
+	// Y7 = all ones unless 1 <= p <= 16, i.e. p-1 <= 15.
+	VPADDB Y11, Y1, Y6
+	VPMAXUB Y9, Y6, Y7
+	VPCMPEQB Y9, Y7, Y7
+	VPXOR Y11, Y7, Y7
+
+	VPOR Y7, Y5, Y5
+	VPMOVMSKB Y5, AX
+	MOVL AX, BX
+	SHRL $16, BX
+	TESTW AX, AX
+	SETEQ (DI)
+	TESTW BX, BX
+	SETEQ 1(DI)
+
+	ADDQ $32, SI
+	ADDQ $2, DI
+	DECQ CX
+	JNZ loop
+
+done:
+	VZEROUPPER
+	RET
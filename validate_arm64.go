@@ -0,0 +1,21 @@
+//go:build gc && !purego
+
+package pkcs7pad
+
+// validate16Stride is the number of blocks validate16Blocks handles at once.
+const validate16Stride = 1
+
+// NEON is part of the base arm64 architecture.
+func haveValidate16() bool {
+	return true
+}
+
+func validate16Blocks(ok *byte, blocks *byte, n int) {
+	validate16NEON(ok, blocks, n)
+}
+
+// validate16NEON checks the PKCS#7 padding of n consecutive 16-byte blocks,
+// setting ok[i] to 1 if block i is well-formed and 0 otherwise.
+//
+//go:noescape
+func validate16NEON(ok *byte, blocks *byte, n int)
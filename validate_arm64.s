@@ -0,0 +1,55 @@
+//go:build gc && !purego
+
+#include "textflag.h"
+
+DATA vpositions<>+0x00(SB)/8, $0x0706050403020100
+DATA vpositions<>+0x08(SB)/8, $0x0f0e0d0c0b0a0908
+GLOBL vpositions<>(SB), RODATA|NOPTR, $16
+
+// func validate16NEON(ok *byte, blocks *byte, n int)
+//
+// For a block whose final byte is p, byte i is part of the padding iff
+// i+p >= 16, and every such byte must equal p. Separately, p must be between 1
+// and 16. There are no data-dependent branches.
+TEXT ·validate16NEON(SB), NOSPLIT, $0-24
+	MOVD ok+0(FP), R0
+	MOVD blocks+8(FP), R1
+	MOVD n+16(FP), R2
+	MOVD $vpositions<>(SB), R3
+	VLD1 (R3), [V8.B16]
+	VMOVI $15, V9.B16
+	VMOVI $16, V10.B16
+	VMOVI $1, V11.B16
+	CBZ R2, done
+
+loop:
+	VLD1.P 16(R1), [V0.B16]
+
+	// V1 = the final byte of the block, broadcast.
+	VDUP V0.B[15], V1.B16
+
+	// V3 = bytes that are part of the padding (i+p >= 16).
+	VUQADD V8.B16, V1.B16, V2.B16
+	VCMHS V10.B16, V2.B16, V3.B16
+
+	// V5 = padding bytes that aren't equal to p.
+	VCMEQ V1.B16, V0.B16, V4.B16
+	VBIC V4.B16, V3.B16, V5.B16
+
+	// Add all ones to V5 unless 1 <= p <= 16, i.e. p-1 <= 15.
+	VSUB V11.B16, V1.B16, V6.B16
+	VCMHS V6.B16, V9.B16, V7.B16
+	VORN V7.B16, V5.B16, V5.B16
+
+	VUMAXV V5.B16, V6
+	VMOV V6.B[0], R4
+	CMP $0, R4
+	CSET EQ, R5
+	MOVB R5, (R0)
+	ADD $1, R0
+
+	SUBS $1, R2, R2
+	BNE loop
+
+done:
+	RET
@@ -0,0 +1,29 @@
+package pkcs7pad
+
+import "fmt"
+
+// ValidateAll reports, for each record, whether it is correctly padded for the
+// given block size, as Validate does, storing the result for records[i] in
+// ok[i]. It panics if ok is shorter than records.
+//
+// On platforms that support it, ValidateAll checks the final blocks of many
+// records at once using vector instructions. The vector and portable paths
+// both run in constant time with respect to the contents of the records.
+func ValidateAll(ok []bool, records [][]byte, size int) {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if len(ok) < len(records) {
+		panic("pkcs7pad: output slice too short")
+	}
+	if n := validateAllVector(ok, records, size); n > 0 {
+		ok, records = ok[n:], records[n:]
+	}
+	validateAllGeneric(ok, records, size)
+}
+
+func validateAllGeneric(ok []bool, records [][]byte, size int) {
+	for i, r := range records {
+		ok[i] = Validate(r, size)
+	}
+}
@@ -0,0 +1,82 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// randomRecords returns records of assorted lengths, about half of which are
+// correctly padded for the given block size.
+func randomRecords(rng *rand.Rand, n, size int) [][]byte {
+	records := make([][]byte, n)
+	for i := range records {
+		buf := make([]byte, rng.Intn(4*size))
+		rng.Read(buf)
+		switch rng.Intn(4) {
+		case 0:
+			buf = Pad(buf, size)
+		case 1:
+			buf = Pad(buf, size)
+			buf[len(buf)-1-rng.Intn(size)] ^= byte(1 << uint(rng.Intn(8)))
+		case 2:
+			buf = append(buf[:len(buf)-len(buf)%size], bytes.Repeat([]byte{byte(rng.Intn(size + 2))}, size)...)
+		}
+		records[i] = buf
+	}
+	return records
+}
+
+func TestValidateAll(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1))
+	for _, size := range []int{1, 8, 15, 16, 17, 32} {
+		for _, n := range []int{0, 1, 2, 3, 31, 32, 33, 100} {
+			records := randomRecords(rng, n, size)
+			ok := make([]bool, n)
+			ValidateAll(ok, records, size)
+			valid := 0
+			for i, r := range records {
+				if want := Validate(r, size); ok[i] != want {
+					t.Errorf("[%d/%d/%d] %x: got %v, want %v", size, n, i, r, ok[i], want)
+				}
+				if ok[i] {
+					valid++
+				}
+			}
+			if n >= 32 && (valid == 0 || valid == n) {
+				t.Errorf("[%d/%d] %d valid records is suspicious", size, n, valid)
+			}
+		}
+	}
+}
+
+func TestValidateAllShortOutput(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	ValidateAll(make([]bool, 1), make([][]byte, 2), 16)
+}
+
+func BenchmarkValidateAll(b *testing.B) {
+	records := randomRecords(rand.New(rand.NewSource(1)), 1024, 16)
+	ok := make([]bool, len(records))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ValidateAll(ok, records, 16)
+	}
+}
+
+func BenchmarkValidateAllGeneric(b *testing.B) {
+	records := randomRecords(rand.New(rand.NewSource(1)), 1024, 16)
+	ok := make([]bool, len(records))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		validateAllGeneric(ok, records, 16)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !(amd64 || arm64) || !gc || purego
+
+package pkcs7pad
+
+func validateAllVector(ok []bool, records [][]byte, size int) int {
+	return 0
+}
@@ -0,0 +1,39 @@
+//go:build (amd64 || arm64) && gc && !purego
+
+package pkcs7pad
+
+// validateBatch is the number of records handed to validate16Blocks at a time.
+const validateBatch = 32
+
+// validateAllVector validates as many records as it can using vector
+// instructions, and returns how many it validated.
+func validateAllVector(ok []bool, records [][]byte, size int) int {
+	if size != 16 || !haveValidate16() {
+		return 0
+	}
+
+	var blocks [validateBatch * 16]byte
+	var res [validateBatch]byte
+	done := 0
+	for len(records)-done >= validate16Stride {
+		n := min(validateBatch, len(records)-done)
+		n -= n % validate16Stride
+		batch := records[done : done+n]
+		for i, r := range batch {
+			// The length of a record is public. Records of the wrong
+			// length get a block that can never be valid.
+			block := blocks[16*i : 16*i+16]
+			if len(r) == 0 || len(r)%16 != 0 {
+				clear(block)
+				continue
+			}
+			copy(block, r[len(r)-16:])
+		}
+		validate16Blocks(&res[0], &blocks[0], n)
+		for i := range batch {
+			ok[done+i] = res[i] == 1
+		}
+		done += n
+	}
+	return done
+}
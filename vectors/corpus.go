@@ -0,0 +1,62 @@
+package vectors
+
+import "bytes"
+
+// Corpus returns a hand-curated set of test vectors derived from RFC 5652,
+// section 6.3, including the cases implementations most often get wrong: a
+// full block of padding on aligned input, a buffer that is nothing but
+// padding, a pad byte of zero, and padding longer than a block. It is intended
+// for use in the tests of other projects, for example:
+//
+//	for _, v := range vectors.Corpus() {
+//		got, err := myUnpad(v.Padded, v.BlockSize)
+//		...
+//	}
+//
+// As with Check, unpadding is expected to be strict about the block size.
+// Each call returns a new slice, which the caller may modify.
+func Corpus() []Vector {
+	abc := []byte("abcdefghijklmnopqrstuvwxyz0123456789")
+	valid := func(comment string, size int, input []byte, padLen int) Vector {
+		padded := append(bytes.Clone(input), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+		return Vector{Comment: comment, BlockSize: size, Input: bytes.Clone(input), Padded: padded, Valid: true}
+	}
+	invalid := func(comment string, size int, padded []byte) Vector {
+		return Vector{Comment: comment, BlockSize: size, Padded: padded}
+	}
+
+	return []Vector{
+		// RFC 5652: "01 -- if l mod k = k-1", through "k k ... k k -- if
+		// l mod k = 0".
+		valid("one byte of padding", 8, abc[:7], 1),
+		valid("two bytes of padding", 8, abc[:6], 2),
+		valid("seven bytes of padding", 8, abc[:1], 7),
+		valid("full block of padding on aligned input", 8, abc[:8], 8),
+		valid("one byte of padding", 16, abc[:15], 1),
+		valid("two bytes of padding", 16, abc[:14], 2),
+		valid("fifteen bytes of padding", 16, abc[:1], 15),
+		valid("full block of padding on aligned input", 16, abc[:16], 16),
+		valid("full block of padding on two aligned blocks", 16, abc[:32], 16),
+		valid("padding in the second block", 16, abc[:20], 12),
+
+		valid("pad length equal to buffer length", 8, nil, 8),
+		valid("pad length equal to buffer length", 16, nil, 16),
+		valid("block size 1", 1, abc[:3], 1),
+		valid("block size 1 with empty input", 1, nil, 1),
+		valid("block size 255 with empty input", 255, nil, 255),
+		valid("block size 255", 255, abc[:30], 225),
+		valid("pad byte that also appears in the data", 8, []byte{0x03, 0x03, 0x03, 0x03, 0x03}, 3),
+
+		invalid("empty buffer", 16, nil),
+		invalid("pad byte 0x00", 16, append(bytes.Clone(abc[:15]), 0x00)),
+		invalid("pad byte 0x00 in a block of zeros", 8, make([]byte, 8)),
+		invalid("pad length greater than the block size", 16, append(bytes.Clone(abc[:31]), 0x11)),
+		invalid("pad length greater than the buffer length", 16, append(bytes.Clone(abc[:15]), 0x20)),
+		invalid("pad length greater than the block size, but correct bytes", 8, bytes.Repeat([]byte{0x09}, 16)),
+		invalid("first padding byte wrong", 16, append(bytes.Clone(abc[:12]), 0x03, 0x04, 0x04, 0x04)),
+		invalid("middle padding byte wrong", 16, append(bytes.Clone(abc[:12]), 0x04, 0x04, 0x05, 0x04)),
+		invalid("full block of padding with first byte wrong", 8, []byte{0x07, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08}),
+		invalid("length not a multiple of the block size", 16, append(bytes.Clone(abc[:14]), 0x01)),
+		invalid("valid padding but one byte short of a block", 8, []byte{0x61, 0x62, 0x03, 0x03, 0x03, 0x03, 0x03}),
+	}
+}
@@ -0,0 +1,22 @@
+package vectors
+
+import "testing"
+
+func TestCorpus(t *testing.T) {
+	t.Parallel()
+
+	vs := Corpus()
+	if err := CheckAll(vs); err != nil {
+		t.Error(err)
+	}
+	for i, v := range vs {
+		if v.Comment == "" {
+			t.Errorf("[%d] %v has no comment", i, v)
+		}
+	}
+
+	vs[0].Padded[0] ^= 0xff
+	if err := Check(Corpus()[0]); err != nil {
+		t.Errorf("modifying the corpus affected later calls: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+// Package vectors loads PKCS#7 padding test vectors, such as those produced by
+// cmd/vectors or by other languages' crypto libraries, and checks this module
+// against them. It also provides a curated corpus of vectors, returned by
+// Corpus, for use in the tests of other projects.
+//
+// Two formats are supported. The JSON format is the one written by
+// cmd/vectors: an object whose "vectors" field is a list of objects with
+// "block_size", "input", "padded", "valid", and optional "comment" fields, or
+// just the list itself. The CSV format has a header row naming the columns
+// block_size, input, padded, valid, and optionally comment, in any order. In
+// both, input and padded are hex encoded, and input is ignored for invalid
+// vectors.
+package vectors
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// A Vector is a single test case. If Valid is true, padding Input with
+// BlockSize must produce Padded, and unpadding Padded must produce Input.
+// Otherwise, unpadding Padded must fail.
+type Vector struct {
+	Comment   string
+	BlockSize int
+	Input     []byte
+	Padded    []byte
+	Valid     bool
+}
+
+func (v Vector) String() string {
+	s := fmt.Sprintf("block size %d, padded %x", v.BlockSize, v.Padded)
+	if v.Comment != "" {
+		s += " (" + v.Comment + ")"
+	}
+	return s
+}
+
+// Check returns an error describing how Pad or UnpadBlock disagrees with v, or
+// nil if they agree.
+func Check(v Vector) error {
+	if v.BlockSize < 1 || v.BlockSize > 255 {
+		return fmt.Errorf("%v: invalid block size", v)
+	}
+	unpad, err := pkcs7pad.UnpadBlock(v.Padded, v.BlockSize)
+	if !v.Valid {
+		if err == nil {
+			return fmt.Errorf("%v: unpadded to %x, want an error", v, unpad)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%v: unpad error %v", v, err)
+	}
+	if !bytes.Equal(unpad, v.Input) {
+		return fmt.Errorf("%v: unpadded to %x, want %x", v, unpad, v.Input)
+	}
+	if pad := pkcs7pad.Pad(bytes.Clone(v.Input), v.BlockSize); !bytes.Equal(pad, v.Padded) {
+		return fmt.Errorf("%v: padded %x to %x", v, v.Input, pad)
+	}
+	return nil
+}
+
+// CheckAll checks each of the vectors, and returns all of the disagreements
+// joined together.
+func CheckAll(vs []Vector) error {
+	var errs []error
+	for _, v := range vs {
+		errs = append(errs, Check(v))
+	}
+	return errors.Join(errs...)
+}
+
+type jsonVector struct {
+	Comment   string  `json:"comment"`
+	BlockSize int     `json:"block_size"`
+	Input     *string `json:"input"`
+	Padded    string  `json:"padded"`
+	Valid     bool    `json:"valid"`
+}
+
+// LoadJSON reads vectors in the JSON format.
+func LoadJSON(r io.Reader) ([]Vector, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var jvs []jsonVector
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] == '[' {
+		err = json.Unmarshal(data, &jvs)
+	} else {
+		var doc struct {
+			Vectors []jsonVector `json:"vectors"`
+		}
+		err = json.Unmarshal(data, &doc)
+		jvs = doc.Vectors
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vectors: %v", err)
+	}
+
+	vs := make([]Vector, len(jvs))
+	for i, jv := range jvs {
+		input := ""
+		if jv.Input != nil {
+			input = *jv.Input
+		} else if jv.Valid {
+			return nil, fmt.Errorf("vectors: vector %d is valid but has no input", i)
+		}
+		v, err := parse(jv.Comment, jv.BlockSize, input, jv.Padded, jv.Valid)
+		if err != nil {
+			return nil, fmt.Errorf("vectors: vector %d: %v", i, err)
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+// LoadCSV reads vectors in the CSV format.
+func LoadCSV(r io.Reader) ([]Vector, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("vectors: reading header: %v", err)
+	}
+	cols := map[string]int{"comment": -1}
+	for i, name := range header {
+		cols[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"block_size", "input", "padded", "valid"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("vectors: missing %s column", name)
+		}
+	}
+
+	var vs []Vector
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return vs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("vectors: %v", err)
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i := cols[name]; i >= 0 && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		size, err := strconv.Atoi(field("block_size"))
+		if err != nil {
+			return nil, fmt.Errorf("vectors: line %d: invalid block size: %v", line, err)
+		}
+		valid, err := strconv.ParseBool(field("valid"))
+		if err != nil {
+			return nil, fmt.Errorf("vectors: line %d: invalid valid column: %v", line, err)
+		}
+		v, err := parse(field("comment"), size, field("input"), field("padded"), valid)
+		if err != nil {
+			return nil, fmt.Errorf("vectors: line %d: %v", line, err)
+		}
+		vs = append(vs, v)
+	}
+}
+
+func parse(comment string, size int, input, padded string, valid bool) (Vector, error) {
+	v := Vector{Comment: comment, BlockSize: size, Valid: valid}
+	var err error
+	if v.Padded, err = hex.DecodeString(padded); err != nil {
+		return Vector{}, fmt.Errorf("invalid padded: %v", err)
+	}
+	if valid {
+		if v.Input, err = hex.DecodeString(input); err != nil {
+			return Vector{}, fmt.Errorf("invalid input: %v", err)
+		}
+	}
+	return v, nil
+}
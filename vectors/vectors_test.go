@@ -0,0 +1,89 @@
+package vectors
+
+import (
+	"strings"
+	"testing"
+)
+
+const testJSON = `{
+  "vectors": [
+    {"block_size": 8, "input": "", "padded": "0808080808080808", "valid": true},
+    {"block_size": 4, "input": "616263", "padded": "61626301", "valid": true},
+    {"comment": "zero", "block_size": 4, "padded": "61626300", "valid": false}
+  ]
+}`
+
+const testCSV = `padded,block_size,valid,input,comment
+0808080808080808,8,true,,
+61626301,4,true,616263,
+61626300,4,false,,zero
+`
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	fromJSON, err := LoadJSON(strings.NewReader(testJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromArray, err := LoadJSON(strings.NewReader(testJSON[strings.Index(testJSON, "[") : strings.LastIndex(testJSON, "]")+1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromCSV, err := LoadCSV(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, vs := range [][]Vector{fromJSON, fromArray, fromCSV} {
+		if len(vs) != 3 {
+			t.Fatalf("loaded %d vectors", len(vs))
+		}
+		if err := CheckAll(vs); err != nil {
+			t.Error(err)
+		}
+		if vs[2].Valid || vs[2].Comment != "zero" || vs[1].BlockSize != 4 || string(vs[1].Input) != "abc" {
+			t.Errorf("unexpected vectors %v", vs)
+		}
+	}
+}
+
+func TestCheckDisagreement(t *testing.T) {
+	t.Parallel()
+
+	bad := []Vector{
+		{BlockSize: 4, Input: []byte("abc"), Padded: []byte("abc\x02"), Valid: true},
+		{BlockSize: 4, Input: []byte("abc"), Padded: []byte("ab\x02\x02"), Valid: true},
+		{BlockSize: 4, Padded: []byte("abc\x01")},
+		{BlockSize: 0, Padded: []byte("abc\x01")},
+	}
+	for i, v := range bad {
+		if err := Check(v); err == nil {
+			t.Errorf("[%d] expected a disagreement", i)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	for i, in := range []string{
+		`{"vectors": [{"block_size": 8, "padded": "08", "valid": true}]}`,
+		`{"vectors": [{"block_size": 8, "padded": "zz"}]}`,
+		`[{`,
+	} {
+		if _, err := LoadJSON(strings.NewReader(in)); err == nil {
+			t.Errorf("[%d] expected an error", i)
+		}
+	}
+	for i, in := range []string{
+		"",
+		"block_size,input,padded\n",
+		"block_size,input,padded,valid\nx,,00,false\n",
+		"block_size,input,padded,valid\n8,,00,maybe\n",
+		"block_size,input,padded,valid\n8,,0,false\n",
+	} {
+		if _, err := LoadCSV(strings.NewReader(in)); err == nil {
+			t.Errorf("[%d] expected an error", i)
+		}
+	}
+}
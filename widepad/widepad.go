@@ -0,0 +1,133 @@
+// Package widepad implements an extension of PKCS#7-style padding to block
+// sizes above 255 bytes, for framing fixed-size records in storage formats,
+// such as 4 KiB or 64 KiB pages. It is not part of RFC 5652 and is not
+// compatible with PKCS#7: use the pkcs7pad package for cipher block padding.
+//
+// Since a single byte can't hold the padding length, it is written as a
+// 2- or 4-byte big-endian integer, repeated. With a width of w bytes, the
+// padding of a message of n bytes to a block size of size is p bytes long,
+// where p is the smallest number of at least w and at most size+w-1 that makes
+// n+p a multiple of size. Reading the padding from its end, each group of w
+// bytes is the big-endian encoding of p, and the group nearest the start of
+// the padding may be cut short. For example, with a width of 2 and a block
+// size of 4096, a message of 4000 bytes gets 96 bytes of padding, each pair of
+// which is 0x00 0x60.
+//
+// As with PKCS#7, exactly one valid padding exists for each message, so
+// padding can always be removed unambiguously.
+package widepad
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+// A Format is a width for the padding length.
+type Format struct {
+	width int
+}
+
+// The supported formats.
+var (
+	// Uint16 supports block sizes up to 65534 bytes.
+	Uint16 = Format{2}
+	// Uint32 supports block sizes up to 2^31-4 bytes.
+	Uint32 = Format{4}
+)
+
+// MaxBlockSize returns the largest block size f supports. Padding may be up to
+// size+w-1 bytes long, where w is the width of f in bytes, and that length must
+// fit in 16 bits, or in 31 bits so that it is non-negative on 32-bit platforms.
+func (f Format) MaxBlockSize() int {
+	if f.width == 2 {
+		return 1<<16 - 2
+	}
+	return 1<<31 - 4
+}
+
+func (f Format) checkSize(size int) {
+	if f.width == 0 {
+		panic("widepad: use of zero Format")
+	}
+	if size < 1 || size > f.MaxBlockSize() {
+		panic(fmt.Sprintf("widepad: inappropriate block size %d", size))
+	}
+}
+
+func (f Format) padLen(n, size int) int {
+	p := size - n%size
+	if p < f.width {
+		// Add as many blocks as it takes, for block sizes below the width.
+		p += (f.width - p + size - 1) / size * size
+	}
+	return p
+}
+
+// PaddedLen returns the length of a buffer of length n after Pad has been
+// applied to it.
+func (f Format) PaddedLen(n, size int) int {
+	f.checkSize(size)
+	return n + f.padLen(n, size)
+}
+
+// Pad appends padding to buf such that its length is a multiple of size. It
+// panics if size is not between 1 and f.MaxBlockSize().
+func (f Format) Pad(buf []byte, size int) []byte {
+	f.checkSize(size)
+	p := f.padLen(len(buf), size)
+	var enc [4]byte
+	binary.BigEndian.PutUint32(enc[:], uint32(p))
+	pattern := enc[4-f.width:]
+
+	n := len(buf)
+	if cap(buf)-n < p {
+		grown := make([]byte, n, n+p)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:n+p]
+	for d := 1; d <= p; d++ {
+		buf[len(buf)-d] = pattern[f.width-1-(d-1)%f.width]
+	}
+	return buf
+}
+
+// Unpad returns a subslice of buf with its padding removed. It returns an error
+// wrapping pkcs7pad.ErrBadPadding if the length of buf is not a non-zero
+// multiple of size, or if the padding is malformed. The padding is checked in
+// constant time. It panics if size is out of range, as Pad does.
+func (f Format) Unpad(buf []byte, size int) ([]byte, error) {
+	f.checkSize(size)
+	if len(buf)%size != 0 {
+		return nil, fmt.Errorf("%w: length %d is not a multiple of block size %d", pkcs7pad.ErrBadPadding, len(buf), size)
+	}
+	if len(buf) < f.width {
+		return nil, pkcs7pad.ErrBadPadding
+	}
+
+	var enc [4]byte
+	copy(enc[4-f.width:], buf[len(buf)-f.width:])
+	p := int(binary.BigEndian.Uint32(enc[:]) & (1<<31 - 1))
+	// A valid length never has its top bit set. It is cleared above so
+	// that p is non-negative on 32-bit platforms, and rejected here.
+	good := subtle.ConstantTimeByteEq(enc[0]&0x80, 0)
+	good &= subtle.ConstantTimeLessOrEq(f.width, p)
+	good &= subtle.ConstantTimeLessOrEq(p, min(len(buf), size+f.width-1))
+
+	// The padding can't extend further back than this window.
+	window := buf[len(buf)-min(len(buf), size+f.width-1):]
+	var diff byte
+	for d := 1; d <= len(window); d++ {
+		inPad := byte(subtle.ConstantTimeLessOrEq(d, p))
+		want := enc[4-1-(d-1)%f.width]
+		diff |= (window[len(window)-d] ^ want) & -inPad
+	}
+	good &= subtle.ConstantTimeByteEq(diff, 0)
+	if good != 1 {
+		return nil, pkcs7pad.ErrBadPadding
+	}
+	return buf[:len(buf)-p], nil
+}
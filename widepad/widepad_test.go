@@ -0,0 +1,142 @@
+package widepad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/zenazn/pkcs7pad"
+)
+
+func TestPadExample(t *testing.T) {
+	t.Parallel()
+
+	padded := Uint16.Pad(make([]byte, 4000), 4096)
+	if want := append(make([]byte, 4000), bytes.Repeat([]byte{0x00, 0x60}, 48)...); !bytes.Equal(padded, want) {
+		t.Errorf("%x != %x", padded[4000:], want[4000:])
+	}
+
+	// Reading from the end, the group at the start of the padding is
+	// cut short.
+	padded = Uint32.Pad([]byte("YELLOW"), 8)
+	if want := []byte("YELLOW\x00\x0a\x00\x00\x00\x0a\x00\x00\x00\x0a"); !bytes.Equal(padded, want) {
+		t.Errorf("%x != %x", padded, want)
+	}
+}
+
+func TestPadUnpad(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []Format{Uint16, Uint32} {
+		for _, size := range []int{1, 2, 3, 5, 16, 256, 4096} {
+			for _, n := range []int{0, 1, size - 1, size, size + 1, 2*size + 3, 3 * size} {
+				msg := bytes.Repeat([]byte{byte(n)}, n)
+				padded := f.Pad(bytes.Clone(msg), size)
+				if len(padded)%size != 0 || len(padded) != f.PaddedLen(n, size) {
+					t.Fatalf("[%d/%d/%d] padded to %d bytes", f.width, size, n, len(padded))
+				}
+				if p := len(padded) - n; p < f.width || p > size+f.width-1 {
+					t.Fatalf("[%d/%d/%d] %d bytes of padding", f.width, size, n, p)
+				}
+				out, err := f.Unpad(padded, size)
+				if err != nil {
+					t.Fatalf("[%d/%d/%d] error unpadding: %v", f.width, size, n, err)
+				}
+				if !bytes.Equal(out, msg) {
+					t.Fatalf("[%d/%d/%d] %x != %x", f.width, size, n, out, msg)
+				}
+			}
+		}
+	}
+}
+
+func TestUnpadErrors(t *testing.T) {
+	t.Parallel()
+
+	good := Uint16.Pad([]byte("YELLOW SUBMARINE"), 16)
+	with := func(f func([]byte)) []byte {
+		b := bytes.Clone(good)
+		f(b)
+		return b
+	}
+	bad := [][]byte{
+		{},
+		good[:31],
+		make([]byte, 16),
+		with(func(b []byte) { b[16] ^= 1 }),
+		with(func(b []byte) { b[31] = 0x11 }),
+		with(func(b []byte) { b[30] = 0xff }),
+		with(func(b []byte) { b[30] = 0x00; b[31] = 0x01 }),
+	}
+	for i, buf := range bad {
+		if _, err := Uint16.Unpad(buf, 16); !errors.Is(err, pkcs7pad.ErrBadPadding) {
+			t.Errorf("[%d] expected ErrBadPadding, got %v", i, err)
+		}
+	}
+	if _, err := Uint32.Unpad(bytes.Repeat([]byte{0xff}, 16), 16); !errors.Is(err, pkcs7pad.ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding for a length with the top bit set, got %v", err)
+	}
+}
+
+func TestBadSize(t *testing.T) {
+	t.Parallel()
+
+	for i, f := range []func(){
+		func() { Uint16.Pad(nil, 0) },
+		func() { Uint16.Pad(nil, 1<<16) },
+		func() { Uint16.Unpad(nil, 1<<16-1) },
+		func() { Format{}.Pad(nil, 16) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("[%d] did not panic", i)
+				}
+			}()
+			f()
+		}()
+	}
+	Uint16.Pad(nil, 1<<16-2)
+}
+
+func TestMaxBlockSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		f          Format
+		size, maxP int
+	}{
+		{Uint16, 1<<16 - 2, 1<<16 - 1},
+		{Uint32, 1<<31 - 4, 1<<31 - 1},
+	}
+	for _, test := range tests {
+		f := test.f
+		if size := f.MaxBlockSize(); size != test.size {
+			t.Errorf("[%d] MaxBlockSize = %d, want %d", f.width, size, test.size)
+		}
+		// The longest padding, size+w-1 bytes, must fit in the width.
+		f.checkSize(test.size)
+		if p := f.padLen(test.size-f.width+1, test.size); p != test.maxP {
+			t.Errorf("[%d] longest padding %d, want %d", f.width, p, test.maxP)
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("[%d] block size %d did not panic", f.width, test.size+1)
+				}
+			}()
+			f.checkSize(test.size + 1)
+		}()
+	}
+
+	// Round-trip the longest padding Uint16 can write.
+	size := Uint16.MaxBlockSize()
+	msg := bytes.Repeat([]byte{0x5a}, size-1)
+	padded := Uint16.Pad(bytes.Clone(msg), size)
+	if len(padded) != 2*size || padded[len(padded)-1] != 0xff || padded[len(padded)-2] != 0xff {
+		t.Fatalf("padded to %d bytes ending %x", len(padded), padded[len(padded)-2:])
+	}
+	if out, err := Uint16.Unpad(padded, size); err != nil || !bytes.Equal(out, msg) {
+		t.Errorf("Unpad = %d bytes, %v", len(out), err)
+	}
+}
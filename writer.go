@@ -0,0 +1,223 @@
+package pkcs7pad
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+var errWriterClosed = errors.New("pkcs7pad: write to closed writer")
+
+// A PadWriter is an io.Writer that appends PKCS#7 padding to the data written
+// to it. Every write to the underlying writer is a whole number of blocks, so a
+// PadWriter can sit directly in front of a block cipher.
+type PadWriter struct {
+	w    io.Writer
+	size int
+	buf  []byte
+	err  error
+
+	// rbuf is the buffer used by ReadFrom, allocated on first use unless it
+	// was given to NewPadWriter.
+	rbuf []byte
+	cfg  streamConfig
+}
+
+// NewPadWriter returns a PadWriter that writes to w using the given block
+// size. The caller must call Close to emit the final padded block. The options
+// configure the buffer used by ReadFrom, which by default is about 4096 bytes.
+// Write never buffers more than a partial block.
+func NewPadWriter(w io.Writer, size int, opts ...StreamOption) *PadWriter {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	pw := &PadWriter{w: w, size: size, buf: make([]byte, 0, size), cfg: newStreamConfig(opts)}
+	if pw.cfg.buf != nil {
+		pw.rbuf = pw.cfg.buffer(0, size, size)
+	}
+	return pw
+}
+
+// Write writes all complete blocks of data to the underlying writer and buffers
+// the rest until more data arrives or the writer is closed.
+func (pw *PadWriter) Write(p []byte) (int, error) {
+	if pw.err != nil {
+		return 0, pw.err
+	}
+
+	n := 0
+	if len(pw.buf) > 0 {
+		k := copy(pw.buf[len(pw.buf):pw.size], p)
+		pw.buf = pw.buf[:len(pw.buf)+k]
+		n += k
+		p = p[k:]
+		if len(pw.buf) < pw.size {
+			return n, nil
+		}
+		if _, err := pw.w.Write(pw.buf); err != nil {
+			pw.err = err
+			return n, err
+		}
+		pw.buf = pw.buf[:0]
+	}
+
+	if full := len(p) - len(p)%pw.size; full > 0 {
+		m, err := pw.w.Write(p[:full])
+		n += m
+		if err != nil {
+			pw.err = err
+			return n, err
+		}
+		p = p[full:]
+	}
+
+	pw.buf = append(pw.buf, p...)
+	return n + len(p), nil
+}
+
+// ReadFrom reads data from r until io.EOF and writes all complete blocks of it
+// to the underlying writer, implementing io.ReaderFrom so that io.Copy reads
+// straight into the PadWriter's buffer. Like Write, it buffers any trailing
+// partial block, and the caller must still call Close to write the final
+// padded block. It returns the number of bytes read from r.
+func (pw *PadWriter) ReadFrom(r io.Reader) (int64, error) {
+	if pw.err != nil {
+		return 0, pw.err
+	}
+	if pw.rbuf == nil {
+		pw.rbuf = pw.cfg.buffer(defaultBufSize, pw.size, pw.size)
+	}
+	buf := pw.rbuf
+	filled := copy(buf, pw.buf)
+	pw.buf = pw.buf[:0]
+
+	var total int64
+	for {
+		n, err := r.Read(buf[filled:])
+		filled += n
+		total += int64(n)
+		if full := filled - filled%pw.size; full > 0 {
+			if _, werr := pw.w.Write(buf[:full]); werr != nil {
+				pw.err = werr
+				return total, werr
+			}
+			filled = copy(buf, buf[full:filled])
+		}
+		if err != nil {
+			pw.buf = append(pw.buf, buf[:filled]...)
+			if err == io.EOF {
+				err = nil
+			}
+			return total, err
+		}
+	}
+}
+
+// Close writes the final padded block to the underlying writer. It does not
+// close the underlying writer. Subsequent calls to Write or Close return an
+// error.
+func (pw *PadWriter) Close() error {
+	if pw.err != nil {
+		return pw.err
+	}
+	pw.err = errWriterClosed
+	_, err := pw.w.Write(Pad(pw.buf, pw.size))
+	return err
+}
+
+// An UnpadWriter is an io.Writer that strips PKCS#7 padding from the data
+// written to it. Since the padding can only be identified once all the data has
+// been written, an UnpadWriter always holds back the most recent block of data
+// until it is closed.
+type UnpadWriter struct {
+	w     io.Writer
+	size  int
+	buf   []byte
+	total int64
+	err   error
+}
+
+// NewUnpadWriter returns an UnpadWriter that writes to w using the given block
+// size. The caller must call Close to check the padding and write the final
+// block.
+func NewUnpadWriter(w io.Writer, size int) *UnpadWriter {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	return &UnpadWriter{w: w, size: size, buf: make([]byte, 0, size)}
+}
+
+// Write writes all but the last block of the data written so far to the
+// underlying writer.
+func (uw *UnpadWriter) Write(p []byte) (int, error) {
+	if uw.err != nil {
+		return 0, uw.err
+	}
+
+	written := len(p)
+	n := len(uw.buf) + len(p) - uw.size
+	if n > 0 {
+		if k := min(n, len(uw.buf)); k > 0 {
+			if _, err := uw.w.Write(uw.buf[:k]); err != nil {
+				uw.err = err
+				return 0, err
+			}
+			uw.buf = uw.buf[:copy(uw.buf, uw.buf[k:])]
+			n -= k
+		}
+		if n > 0 {
+			m, err := uw.w.Write(p[:n])
+			uw.total += int64(m)
+			if err != nil {
+				uw.err = err
+				return m, err
+			}
+			p = p[n:]
+		}
+	}
+
+	uw.buf = append(uw.buf, p...)
+	uw.total += int64(len(p))
+	return written, nil
+}
+
+// Close checks the padding of the final block in constant time and writes the
+// unpadded remainder to the underlying writer. It returns an error if the
+// padding is malformed or if the total amount of data written was not a
+// multiple of the block size. It does not close the underlying writer.
+// Subsequent calls to Write or Close return an error.
+func (uw *UnpadWriter) Close() error {
+	if uw.err != nil {
+		return uw.err
+	}
+	uw.err = errWriterClosed
+	if uw.total%int64(uw.size) != 0 {
+		return errMisaligned(uw.total, uw.size)
+	}
+	if len(uw.buf) < uw.size {
+		return ErrBadPadding
+	}
+	unpad, err := Unpad(uw.buf)
+	if err != nil {
+		return err
+	}
+	_, err = uw.w.Write(unpad)
+	return err
+}
+
+// WritePadded writes buf followed by its PKCS#7 padding to w, without copying
+// buf into a larger buffer. If w implements the writev-style fast path of
+// net.Buffers, as a *net.TCPConn does, both are written with a single system
+// call. It returns the number of bytes written, including the padding. Like
+// Pad, WritePadded panics if size is not between 1 and 255.
+func WritePadded(w io.Writer, buf []byte, size int) (int64, error) {
+	i := PadLen(len(buf), size)
+	var block [255]byte
+	padding := block[:i]
+	for j := range padding {
+		padding[j] = byte(i)
+	}
+	bufs := net.Buffers{buf, padding}
+	return bufs.WriteTo(w)
+}
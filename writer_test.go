@@ -0,0 +1,192 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// blockWriter records the size of each write, and fails the test if any of
+// them is not a whole number of blocks.
+type blockWriter struct {
+	t *testing.T
+	bytes.Buffer
+}
+
+func (bw *blockWriter) Write(p []byte) (int, error) {
+	if len(p)%aes.BlockSize != 0 {
+		bw.t.Errorf("misaligned write of %d bytes", len(p))
+	}
+	return bw.Buffer.Write(p)
+}
+
+func TestPadWriter(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 5)
+	for chunk := 1; chunk <= 2*aes.BlockSize; chunk++ {
+		for n := 0; n <= len(in); n += 7 {
+			bw := &blockWriter{t: t}
+			pw := NewPadWriter(bw, aes.BlockSize)
+			for p := in[:n]; len(p) > 0; {
+				k := chunk
+				if k > len(p) {
+					k = len(p)
+				}
+				if m, err := pw.Write(p[:k]); m != k || err != nil {
+					t.Fatalf("[%d/%d] Write = %d, %v", chunk, n, m, err)
+				}
+				p = p[k:]
+			}
+			if err := pw.Close(); err != nil {
+				t.Fatalf("[%d/%d] Close: %v", chunk, n, err)
+			}
+
+			want := Pad(append([]byte(nil), in[:n]...), aes.BlockSize)
+			if !bytes.Equal(bw.Bytes(), want) {
+				t.Errorf("[%d/%d] %x != %x", chunk, n, bw.Bytes(), want)
+			}
+		}
+	}
+}
+
+type errWriter struct{ err error }
+
+func (ew errWriter) Write(p []byte) (int, error) { return 0, ew.err }
+
+func TestPadWriterErrors(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	pw := NewPadWriter(errWriter{boom}, aes.BlockSize)
+	if _, err := pw.Write(testString[:3]); err != nil {
+		t.Errorf("buffered write failed: %v", err)
+	}
+	if _, err := pw.Write(testString); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if err := pw.Close(); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+
+	pw = NewPadWriter(&bytes.Buffer{}, aes.BlockSize)
+	if err := pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pw.Write(testString); err != errWriterClosed {
+		t.Errorf("expected %v, got %v", errWriterClosed, err)
+	}
+}
+
+func TestUnpadWriter(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 5)
+	for chunk := 1; chunk <= 2*aes.BlockSize; chunk++ {
+		for n := 0; n <= len(in); n += 7 {
+			pad := Pad(append([]byte(nil), in[:n]...), aes.BlockSize)
+			var out bytes.Buffer
+			uw := NewUnpadWriter(&out, aes.BlockSize)
+			for p := pad; len(p) > 0; {
+				k := min(chunk, len(p))
+				if m, err := uw.Write(p[:k]); m != k || err != nil {
+					t.Fatalf("[%d/%d] Write = %d, %v", chunk, n, m, err)
+				}
+				p = p[k:]
+			}
+			if err := uw.Close(); err != nil {
+				t.Fatalf("[%d/%d] Close: %v", chunk, n, err)
+			}
+			if !bytes.Equal(out.Bytes(), in[:n]) {
+				t.Errorf("[%d/%d] %x != %x", chunk, n, out.Bytes(), in[:n])
+			}
+		}
+	}
+}
+
+func TestUnpadWriterErrors(t *testing.T) {
+	t.Parallel()
+
+	bad := [][]byte{
+		{},
+		testString[:15],
+		append(testString[:16:16], 0x04, 0x04, 0x04),
+		testString,
+	}
+	for i, test := range bad {
+		uw := NewUnpadWriter(&bytes.Buffer{}, aes.BlockSize)
+		if _, err := uw.Write(test); err != nil {
+			t.Fatal(err)
+		}
+		if err := uw.Close(); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+		if _, err := uw.Write(test); err != errWriterClosed {
+			t.Errorf("[%d] expected %v, got %v", i, errWriterClosed, err)
+		}
+	}
+
+	boom := errors.New("boom")
+	uw := NewUnpadWriter(errWriter{boom}, aes.BlockSize)
+	if _, err := uw.Write(PadTests[3].out); err != nil {
+		t.Errorf("buffered write failed: %v", err)
+	}
+	if err := uw.Close(); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+}
+
+func TestWritePadded(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 2)
+	for n := 0; n <= len(in); n++ {
+		var buf bytes.Buffer
+		m, err := WritePadded(&buf, in[:n], aes.BlockSize)
+		want := Pad(bytes.Clone(in[:n]), aes.BlockSize)
+		if err != nil || m != int64(len(want)) || !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("[%d] WritePadded = %d, %v; wrote %x, want %x", n, m, err, buf.Bytes(), want)
+		}
+	}
+
+	failure := errors.New("failure")
+	if _, err := WritePadded(errWriter{failure}, testString, aes.BlockSize); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
+
+func TestPadWriterReadFrom(t *testing.T) {
+	t.Parallel()
+
+	in := bytes.Repeat(testString, 600)
+	for _, n := range []int{0, 1, 15, 16, 17, 5000, len(in)} {
+		bw := &blockWriter{t: t}
+		pw := NewPadWriter(bw, aes.BlockSize)
+		// Start with a partial block buffered by Write.
+		pw.Write(in[:3])
+		var _ io.ReaderFrom = pw
+		m, err := io.Copy(pw, iotest.HalfReader(bytes.NewReader(in[3:max(n, 3)])))
+		if err != nil || m != int64(max(n, 3)-3) {
+			t.Fatalf("[%d] Copy = %d, %v", n, m, err)
+		}
+		if err := pw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if want := Pad(bytes.Clone(in[:max(n, 3)]), aes.BlockSize); !bytes.Equal(bw.Bytes(), want) {
+			t.Errorf("[%d] wrote %d bytes, want %d", n, bw.Len(), len(want))
+		}
+	}
+
+	failure := errors.New("failure")
+	pw := NewPadWriter(errWriter{failure}, aes.BlockSize)
+	if _, err := pw.ReadFrom(bytes.NewReader(in)); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+	pw = NewPadWriter(io.Discard, aes.BlockSize)
+	if _, err := pw.ReadFrom(iotest.ErrReader(failure)); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
@@ -0,0 +1,71 @@
+package pkcs7pad
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// PadX923 appends ANSI X9.23 padding to the given buffer such that the
+// resulting slice of bytes has a length divisible by the given size. ANSI X9.23
+// padding consists of zero bytes followed by a final byte holding the total
+// number of padding bytes.
+func PadX923(buf []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	i := size - (len(buf) % size)
+	buf = append(buf, make([]byte, i)...)
+	buf[len(buf)-1] = byte(i)
+	return buf
+}
+
+// UnpadX923 returns a subslice of the input buffer with trailing ANSI X9.23
+// padding removed. Like Unpad, it checks the correctness of the padding bytes in
+// constant time, and returns an error if the padding bytes are malformed.
+func UnpadX923(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+
+	// This is the same constant-time scan as Unpad, except that every
+	// padding byte other than the length byte itself must be zero.
+	padLen := buf[len(buf)-1]
+	toCheck := 255
+	good := 1
+	if toCheck > len(buf) {
+		toCheck = len(buf)
+	}
+	for i := 1; i < toCheck; i++ {
+		b := buf[len(buf)-1-i]
+
+		outOfRange := subtle.ConstantTimeLessOrEq(int(padLen), i)
+		equal := subtle.ConstantTimeByteEq(0, b)
+		good &= subtle.ConstantTimeSelect(outOfRange, 1, equal)
+	}
+
+	good &= subtle.ConstantTimeLessOrEq(1, int(padLen))
+	good &= subtle.ConstantTimeLessOrEq(int(padLen), len(buf))
+
+	if good != 1 {
+		return nil, ErrBadPadding
+	}
+
+	return buf[:len(buf)-int(padLen)], nil
+}
+
+type x923 struct{}
+
+func (x923) Name() string                    { return "x923" }
+func (x923) Pad(buf []byte, size int) []byte { return PadX923(buf, size) }
+func (x923) Unpad(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	// As with PKCS7, the padding can be no longer than a block.
+	n := len(buf) - min(len(buf), size)
+	out, err := UnpadX923(buf[n:])
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n+len(out)], nil
+}
@@ -0,0 +1,92 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+	"testing/quick"
+)
+
+func TestPadX923(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range PadTests {
+		buf := make([]byte, len(test.in))
+		copy(buf, test.in)
+		pad := PadX923(buf, aes.BlockSize)
+
+		want := make([]byte, len(test.out))
+		copy(want, test.in)
+		want[len(want)-1] = test.out[len(test.out)-1]
+		if !bytes.Equal(pad, want) {
+			t.Errorf("[%d] %x != %x", i, pad, want)
+		}
+
+		unpad, err := UnpadX923(pad)
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+}
+
+var BadX923Tests = [][]byte{
+	{},
+	{0x00, 0x00, 0x04},
+	{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01, 0x03},
+	{0xde, 0xad, 0xbe, 0xef, 0x00},
+	{0xde, 0xad, 0xbe, 0xef, 0x03, 0x03, 0x03},
+}
+
+func TestUnpadX923Errors(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range BadX923Tests {
+		_, err := UnpadX923(test)
+		if !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", i, err)
+		}
+	}
+}
+
+func completelyUnsafeNotConstantTimeUnpadX923(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, ErrBadPadding
+	}
+	padLen := buf[len(buf)-1]
+	if int(padLen) > len(buf) || padLen == 0 {
+		return nil, ErrBadPadding
+	}
+
+	out, padding := buf[:len(buf)-int(padLen)], buf[len(buf)-int(padLen):len(buf)-1]
+	if !bytes.Equal(padding, make([]byte, len(padding))) {
+		return nil, ErrBadPadding
+	}
+	return out, nil
+}
+
+func TestUnpadX923BlackBox(t *testing.T) {
+	t.Parallel()
+	err := quick.CheckEqual(UnpadX923, completelyUnsafeNotConstantTimeUnpadX923, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestX923SchemeFinalBlock(t *testing.T) {
+	t.Parallel()
+
+	// The padding is longer than a block, which UnpadX923 accepts but the
+	// scheme must not.
+	buf := make([]byte, 2*aes.BlockSize)
+	buf[len(buf)-1] = aes.BlockSize + 1
+	if _, err := UnpadX923(buf); err != nil {
+		t.Fatalf("UnpadX923: %v", err)
+	}
+	if _, err := X923.Unpad(buf, aes.BlockSize); !errors.Is(err, ErrBadPadding) {
+		t.Errorf("expected ErrBadPadding, got %v", err)
+	}
+}
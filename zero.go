@@ -0,0 +1,61 @@
+package pkcs7pad
+
+import "fmt"
+
+// The functions in this file implement zero padding, which is found in a number
+// of legacy systems. Zero padding is ambiguous: there is no way to distinguish
+// padding from plaintext that happens to end in zero bytes. It should only be
+// used to read data produced by systems that cannot be changed. New designs
+// should use Pad and Unpad instead.
+
+// PadZeros appends zero bytes to the given buffer such that the resulting slice
+// of bytes has a length divisible by the given size. Unlike Pad, no padding is
+// added if the buffer is already a multiple of the block size.
+func PadZeros(buf []byte, size int) []byte {
+	if size < 1 || size > 255 {
+		panic(fmt.Sprintf("pkcs7pad: inappropriate block size %d", size))
+	}
+	if i := len(buf) % size; i != 0 {
+		buf = append(buf, make([]byte, size-i)...)
+	}
+	return buf
+}
+
+// UnpadZeros returns a subslice of the input buffer with all trailing zero
+// bytes removed. It does not run in constant time, and it cannot fail: it is
+// up to the caller to know that the original plaintext did not end with zero
+// bytes.
+func UnpadZeros(buf []byte) []byte {
+	i := len(buf)
+	for i > 0 && buf[i-1] == 0 {
+		i--
+	}
+	return buf[:i]
+}
+
+// UnpadZerosLen returns the first n bytes of the input buffer, for use when the
+// length of the original plaintext is known out of band. It returns an error if
+// n is out of range or if any of the bytes being removed are not zero. It does
+// not run in constant time.
+func UnpadZerosLen(buf []byte, n int) ([]byte, error) {
+	if n < 0 || n > len(buf) {
+		return nil, ErrBadPadding
+	}
+	for _, b := range buf[n:] {
+		if b != 0 {
+			return nil, ErrBadPadding
+		}
+	}
+	return buf[:n], nil
+}
+
+type zero struct{}
+
+func (zero) Name() string                    { return "zero" }
+func (zero) Pad(buf []byte, size int) []byte { return PadZeros(buf, size) }
+func (zero) Unpad(buf []byte, size int) ([]byte, error) {
+	if err := checkAligned(buf, size); err != nil {
+		return nil, err
+	}
+	return UnpadZeros(buf), nil
+}
@@ -0,0 +1,53 @@
+package pkcs7pad
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var ZeroPadTests = []struct {
+	in, out []byte
+}{
+	{[]byte{}, []byte{}},
+	{[]byte{0xde}, []byte{0xde, 0x00, 0x00, 0x00}},
+	{[]byte{0xde, 0xad, 0xbe}, []byte{0xde, 0xad, 0xbe, 0x00}},
+	{[]byte{0xde, 0xad, 0xbe, 0xef}, []byte{0xde, 0xad, 0xbe, 0xef}},
+	{[]byte{0xde, 0xad, 0xbe, 0xef, 0xba}, []byte{0xde, 0xad, 0xbe, 0xef, 0xba, 0x00, 0x00, 0x00}},
+}
+
+func TestPadZeros(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range ZeroPadTests {
+		buf := make([]byte, len(test.in))
+		copy(buf, test.in)
+		pad := PadZeros(buf, 4)
+		if !bytes.Equal(pad, test.out) {
+			t.Errorf("[%d] %x != %x", i, pad, test.out)
+		}
+
+		if unpad := UnpadZeros(pad); !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+
+		unpad, err := UnpadZerosLen(pad, len(test.in))
+		if err != nil {
+			t.Errorf("[%d] error unpadding: %v", i, err)
+		}
+		if !bytes.Equal(unpad, test.in) {
+			t.Errorf("[%d] %x != %x", i, unpad, test.in)
+		}
+	}
+}
+
+func TestUnpadZerosLenErrors(t *testing.T) {
+	t.Parallel()
+
+	buf := []byte{0xde, 0xad, 0x00, 0x00}
+	for _, n := range []int{-1, 1, 5} {
+		if _, err := UnpadZerosLen(buf, n); !errors.Is(err, ErrBadPadding) {
+			t.Errorf("[%d] expected BadCiphertext, got %v", n, err)
+		}
+	}
+}